@@ -174,7 +174,15 @@ export function activate(context: vscode.ExtensionContext) {
 		unknownSubOperand: config.get<boolean>('diagnostics.unknownSubOperand', true),
 		subOperandValidation: config.get<boolean>('diagnostics.subOperandValidation', true),
 		contentBeyondColumn72: config.get<boolean>('diagnostics.contentBeyondColumn72', true),
-		standaloneCommentBetweenMCS: config.get<boolean>('diagnostics.standaloneCommentBetweenMCS', true)
+		invalidFormat: config.get<boolean>('diagnostics.invalidFormat', true),
+		reservedSysmodPrefix: config.get<boolean>('diagnostics.reservedSysmodPrefix', true),
+		unsupportedRelease: config.get<boolean>('diagnostics.unsupportedRelease', true),
+		standaloneCommentBetweenMCS: config.get<boolean>('diagnostics.standaloneCommentBetweenMCS', true),
+		duplicateSysmodDefinition: config.get<boolean>('diagnostics.duplicateSysmodDefinition', true),
+		unresolvedSysmodReference: config.get<boolean>('diagnostics.unresolvedSysmodReference', true),
+		rules: config.get<Record<string, string>>('diagnostics.rules', {}),
+		knownSysmodsFile: config.get<string>('diagnostics.knownSysmodsFile', ''),
+		delay: config.get<number>('diagnostics.delay', 300)
 	};
 
 	// Build formatting configuration
@@ -186,6 +194,18 @@ export function activate(context: vscode.ExtensionContext) {
 		moveLeadingComments: config.get<boolean>('formatting.moveLeadingComments', false)
 	};
 
+	// Watch smpe.json and the overlays so the server reloads them when they are edited.
+	// The bundled data file does not change.
+	const dataOverlays = config.get<string[]>('dataOverlays', []);
+	const dataFiles: string[] = dataPath && dataPath !== bundledDataPath ? [dataPath] : [];
+	const dataWatchers = [vscode.workspace.createFileSystemWatcher('**/.smpe_ls/*.json')];
+	for (const file of dataFiles.concat(dataOverlays)) {
+		const pattern = path.isAbsolute(file)
+			? new vscode.RelativePattern(vscode.Uri.file(path.dirname(file)), path.basename(file))
+			: `**/${file}`;
+		dataWatchers.push(vscode.workspace.createFileSystemWatcher(pattern));
+	}
+
 	debugLog(`Diagnostics config: ${JSON.stringify(diagnosticsConfig)}`);
 	debugLog(`Formatting config: ${JSON.stringify(formattingConfig)}`);
 
@@ -199,12 +219,17 @@ export function activate(context: vscode.ExtensionContext) {
 			{ scheme: 'untitled', language: 'smpe' }
 		],
 		synchronize: {
-			fileEvents: vscode.workspace.createFileSystemWatcher('**/*.{smpe,mcs,smp}')
+			fileEvents: [vscode.workspace.createFileSystemWatcher('**/*.{smpe,mcs,smp}')].concat(dataWatchers)
 		},
 		outputChannel: outputChannel,
 		initializationOptions: {
 			diagnostics: diagnosticsConfig,
-			formatting: formattingConfig
+			formatting: formattingConfig,
+			codeActions: {
+				snippetCommand: true
+			},
+			dataOverlays: dataOverlays,
+			targetRelease: config.get<string>('targetRelease', '')
 		}
 	};
 
@@ -248,6 +273,19 @@ export function activate(context: vscode.ExtensionContext) {
 		vscode.window.showErrorMessage(`Failed to start SMP/E Language Server: ${error}`);
 	});
 
+	// Register snippet insertion used by quick fixes (cursor placement inside inserted operands)
+	context.subscriptions.push(
+		vscode.commands.registerCommand('smpe.insertSnippet', async (uri: string, range: { start: { line: number; character: number }; end: { line: number; character: number } }, snippet: string) => {
+			const document = await vscode.workspace.openTextDocument(vscode.Uri.parse(uri));
+			const editor = await vscode.window.showTextDocument(document);
+			const target = new vscode.Range(
+				new vscode.Position(range.start.line, range.start.character),
+				new vscode.Position(range.end.line, range.end.character)
+			);
+			await editor.insertSnippet(new vscode.SnippetString(snippet), target);
+		})
+	);
+
 	// Register format on save handler
 	context.subscriptions.push(
 		vscode.workspace.onWillSaveTextDocument(async (e) => {
@@ -297,7 +335,15 @@ export function activate(context: vscode.ExtensionContext) {
 					unknownSubOperand: updatedConfig.get<boolean>('diagnostics.unknownSubOperand', true),
 					subOperandValidation: updatedConfig.get<boolean>('diagnostics.subOperandValidation', true),
 					contentBeyondColumn72: updatedConfig.get<boolean>('diagnostics.contentBeyondColumn72', true),
-					standaloneCommentBetweenMCS: updatedConfig.get<boolean>('diagnostics.standaloneCommentBetweenMCS', true)
+					invalidFormat: updatedConfig.get<boolean>('diagnostics.invalidFormat', true),
+					reservedSysmodPrefix: updatedConfig.get<boolean>('diagnostics.reservedSysmodPrefix', true),
+					unsupportedRelease: updatedConfig.get<boolean>('diagnostics.unsupportedRelease', true),
+					standaloneCommentBetweenMCS: updatedConfig.get<boolean>('diagnostics.standaloneCommentBetweenMCS', true),
+					duplicateSysmodDefinition: updatedConfig.get<boolean>('diagnostics.duplicateSysmodDefinition', true),
+					unresolvedSysmodReference: updatedConfig.get<boolean>('diagnostics.unresolvedSysmodReference', true),
+					rules: updatedConfig.get<Record<string, string>>('diagnostics.rules', {}),
+					knownSysmodsFile: updatedConfig.get<string>('diagnostics.knownSysmodsFile', ''),
+					delay: updatedConfig.get<number>('diagnostics.delay', 300)
 				};
 
 				const updatedFormattingConfig = {
@@ -320,6 +366,29 @@ export function activate(context: vscode.ExtensionContext) {
 
 				log('Sent updated configuration to server');
 			}
+
+			if (e.affectsConfiguration('smpe.dataOverlays')) {
+				// The server reloads the data with the new overlays
+				client.sendNotification('workspace/didChangeConfiguration', {
+					settings: {
+						smpe: {
+							dataOverlays: vscode.workspace.getConfiguration('smpe').get<string[]>('dataOverlays', [])
+						}
+					}
+				});
+				log('Sent updated data overlays to server');
+			}
+
+			if (e.affectsConfiguration('smpe.targetRelease')) {
+				client.sendNotification('workspace/didChangeConfiguration', {
+					settings: {
+						smpe: {
+							targetRelease: vscode.workspace.getConfiguration('smpe').get<string>('targetRelease', '')
+						}
+					}
+				});
+				log('Sent updated target release to server');
+			}
 		})
 	);
 
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+)
+
+// baselineVersion is the version of the baseline file format
+const baselineVersion = 1
+
+// Baseline records the findings accepted in existing files, so that only new findings are
+// reported. Findings are matched by file, rule code, statement and a hash of the message
+// and the source line, not by line number.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is a finding recorded in a baseline
+type BaselineEntry struct {
+	File      string `json:"file"`
+	Code      string `json:"code"`
+	Statement string `json:"statement"` // e.g. "++PTF(UA12345) ++VER(Z038)"
+	Hash      string `json:"hash"`
+	Message   string `json:"message"` // Informational, not used for matching
+	Count     int    `json:"count"`   // Number of identical findings
+}
+
+// key returns the fields an entry is matched by
+func (e BaselineEntry) key() string {
+	return e.File + "\x00" + e.Code + "\x00" + e.Statement + "\x00" + e.Hash
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, err
+	}
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", baseline.Version)
+	}
+	return &baseline, nil
+}
+
+// WriteBaseline writes the findings of a report as a baseline file
+func WriteBaseline(path string, report *Report) (int, error) {
+	counts := make(map[string]*BaselineEntry)
+	for _, file := range report.Files {
+		for _, d := range file.Diagnostics {
+			entry := d.baseline
+			if existing, ok := counts[entry.key()]; ok {
+				existing.Count++
+				continue
+			}
+			entry.Count = 1
+			counts[entry.key()] = &entry
+		}
+	}
+
+	baseline := Baseline{Version: baselineVersion, Entries: []BaselineEntry{}}
+	for _, entry := range counts {
+		baseline.Entries = append(baseline.Entries, *entry)
+	}
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		return baseline.Entries[i].key() < baseline.Entries[j].key()
+	})
+
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(baseline.Entries), os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// baselineMatcher matches findings against the entries of a baseline
+type baselineMatcher struct {
+	remaining map[string]int
+	entries   map[string]BaselineEntry
+}
+
+// newBaselineMatcher creates a matcher for a baseline
+func newBaselineMatcher(baseline *Baseline) *baselineMatcher {
+	m := &baselineMatcher{remaining: make(map[string]int), entries: make(map[string]BaselineEntry)}
+	for _, entry := range baseline.Entries {
+		m.remaining[entry.key()] += entry.Count
+		m.entries[entry.key()] = entry
+	}
+	return m
+}
+
+// match checks if a finding is recorded in the baseline and consumes the entry
+func (m *baselineMatcher) match(entry BaselineEntry) bool {
+	if m.remaining[entry.key()] <= 0 {
+		return false
+	}
+	m.remaining[entry.key()]--
+	return true
+}
+
+// fixed returns the baseline entries of the checked files that no longer occur, with the
+// number of findings that disappeared as their count
+func (m *baselineMatcher) fixed(checked []string) []BaselineEntry {
+	files := make(map[string]bool)
+	for _, path := range checked {
+		files[relativePath(path)] = true
+	}
+
+	var fixed []BaselineEntry
+	for key, count := range m.remaining {
+		entry := m.entries[key]
+		if count > 0 && files[entry.File] {
+			entry.Count = count
+			fixed = append(fixed, entry)
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool { return fixed[i].key() < fixed[j].key() })
+	return fixed
+}
+
+// baselineEntry describes a finding for the baseline
+func baselineEntry(path string, doc *parser.Document, lines []string, item DiagnosticItem) BaselineEntry {
+	var source string
+	if item.Line-1 < len(lines) {
+		source = strings.TrimSpace(lines[item.Line-1])
+	}
+	sum := sha256.Sum256([]byte(item.Code + "\x00" + item.Message + "\x00" + source))
+	return BaselineEntry{
+		File:      relativePath(path),
+		Code:      item.Code,
+		Statement: statementIdentity(doc, item.Line-1),
+		Hash:      hex.EncodeToString(sum[:8]),
+		Message:   item.Message,
+	}
+}
+
+// statementIdentity identifies the statement a line belongs to by its name and parameter,
+// qualified by the SYSMOD it belongs to, e.g. "++PTF(UA12345) ++VER(Z038)"
+func statementIdentity(doc *parser.Document, line int) string {
+	var sysmod, identity string
+	for _, stmt := range doc.Statements {
+		if stmt.Position.Line > line {
+			break
+		}
+		identity = stmt.Name
+		for _, child := range stmt.Children {
+			if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
+				identity += "(" + strings.TrimSpace(child.Value) + ")"
+				break
+			}
+		}
+		switch stmt.Name {
+		case "++APAR", "++FUNCTION", "++PTF", "++USERMOD":
+			sysmod = identity
+		}
+	}
+	if sysmod != "" && sysmod != identity {
+		return sysmod + " " + identity
+	}
+	return identity
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+)
+
+// baselineReport builds a report with a finding per line of text containing FOO
+func baselineReport(t *testing.T, text string) *Report {
+	t.Helper()
+	store, err := data.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := parser.NewParser(store.Statements).Parse(text)
+	lines := strings.Split(text, "\n")
+
+	file := FileReport{Path: "pkg/a.smpe"}
+	for i, line := range lines {
+		if strings.Contains(line, "FOO") {
+			item := DiagnosticItem{Line: i + 1, Column: 1, Severity: "WARNING", Code: "unknown_operand", Message: "Unknown operand 'FOO'"}
+			item.baseline = baselineEntry(file.Path, doc, lines, item)
+			file.Diagnostics = append(file.Diagnostics, item)
+		}
+	}
+	return &Report{Files: []FileReport{file}, Checked: []string{file.Path}}
+}
+
+func TestStatementIdentity(t *testing.T) {
+	report := baselineReport(t, "++PTF(UA00001) .\n++VER(Z038) FMID(HBB77C0)\n  FOO(X) .\n")
+	if got := report.Files[0].Diagnostics[0].baseline.Statement; got != "++PTF(UA00001) ++VER(Z038)" {
+		t.Errorf("statement = %q", got)
+	}
+}
+
+func TestBaselineMatchesMovedFindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	old := "++PTF(UA00001) .\n++VER(Z038) FMID(HBB77C0) FOO(X) .\n++MOD(MOD1) DISTLIB(AMODLIB) FOO(X) .\n++MOD(MOD2) DISTLIB(AMODLIB) FOO(X) .\n"
+	if count, err := WriteBaseline(path, baselineReport(t, old)); err != nil || count != 3 {
+		t.Fatalf("WriteBaseline = %d, %v", count, err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines were inserted, MOD2 was fixed and MOD3 is new
+	current := "++PTF(UA00001) .\n\n\n++VER(Z038) FMID(HBB77C0) FOO(X) .\n++MOD(MOD1) DISTLIB(AMODLIB) FOO(X) .\n++MOD(MOD2) DISTLIB(AMODLIB) .\n++MOD(MOD3) DISTLIB(AMODLIB) FOO(X) .\n"
+	matcher := newBaselineMatcher(loaded)
+	var reported []string
+	for _, d := range baselineReport(t, current).Files[0].Diagnostics {
+		if !matcher.match(d.baseline) {
+			reported = append(reported, d.baseline.Statement)
+		}
+	}
+	if len(reported) != 1 || reported[0] != "++PTF(UA00001) ++MOD(MOD3)" {
+		t.Errorf("Expected only the finding in MOD3 to be new, got %v", reported)
+	}
+
+	fixed := matcher.fixed([]string{"pkg/a.smpe"})
+	if len(fixed) != 1 || fixed[0].Statement != "++PTF(UA00001) ++MOD(MOD2)" {
+		t.Errorf("Expected the finding in MOD2 to be fixed, got %+v", fixed)
+	}
+	if fixed := matcher.fixed([]string{"other.smpe"}); len(fixed) != 0 {
+		t.Errorf("Expected no fixed entries for files not linted, got %+v", fixed)
+	}
+}
@@ -9,51 +9,84 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// DiagnosticCode represents the code for each diagnostic type
-type DiagnosticCode string
-
-const (
-	// Syntax Errors
-	DiagUnknownStatement      DiagnosticCode = "unknown_statement"
-	DiagInvalidLanguageID     DiagnosticCode = "invalid_language_id"
-	DiagUnbalancedParentheses DiagnosticCode = "unbalanced_parentheses"
-	DiagMissingTerminator     DiagnosticCode = "missing_terminator"
-	DiagMissingParameter      DiagnosticCode = "missing_parameter"
-	DiagContentBeyondCol72    DiagnosticCode = "content_beyond_column_72"
-
-	// Operand Errors
-	DiagUnknownOperand         DiagnosticCode = "unknown_operand"
-	DiagDuplicateOperand       DiagnosticCode = "duplicate_operand"
-	DiagEmptyOperandParameter  DiagnosticCode = "empty_operand_parameter"
-	DiagMissingRequiredOperand DiagnosticCode = "missing_required_operand"
-	DiagDependencyViolation    DiagnosticCode = "dependency_violation"
-	DiagMutuallyExclusive      DiagnosticCode = "mutually_exclusive"
-	DiagRequiredGroup          DiagnosticCode = "required_group"
-
-	// Sub-Operand Errors
-	DiagUnknownSubOperand    DiagnosticCode = "unknown_sub_operand"
-	DiagSubOperandValidation DiagnosticCode = "sub_operand_validation"
-
-	// Structural Errors
-	DiagMissingInlineData           DiagnosticCode = "missing_inline_data"
-	DiagStandaloneCommentBetweenMCS DiagnosticCode = "standalone_comment_between_mcs"
-)
+// RuleSetting configures a diagnostic rule: a severity ("off", "hint", "info", "warning"
+// or "error"), or true/false to keep the default severity or turn the rule off
+type RuleSetting string
+
+// UnmarshalYAML accepts a severity or a boolean
+func (r *RuleSetting) UnmarshalYAML(value *yaml.Node) error {
+	var enabled bool
+	if value.Tag == "!!bool" && value.Decode(&enabled) == nil {
+		*r = ruleSettingFromBool(enabled)
+		return nil
+	}
+	var severity string
+	if err := value.Decode(&severity); err != nil {
+		return err
+	}
+	*r = RuleSetting(severity)
+	return nil
+}
+
+// UnmarshalJSON accepts a severity or a boolean
+func (r *RuleSetting) UnmarshalJSON(b []byte) error {
+	var enabled bool
+	if json.Unmarshal(b, &enabled) == nil {
+		*r = ruleSettingFromBool(enabled)
+		return nil
+	}
+	var severity string
+	if err := json.Unmarshal(b, &severity); err != nil {
+		return err
+	}
+	*r = RuleSetting(severity)
+	return nil
+}
+
+// ruleSettingFromBool maps the former on/off settings: true keeps the default severity
+func ruleSettingFromBool(enabled bool) RuleSetting {
+	if enabled {
+		return ""
+	}
+	return RuleSetting(diagnostics.SeverityOff)
+}
 
 // LintConfig holds the linter configuration
-// All diagnostics default to true (enabled)
+// All diagnostics default to enabled with the severity from the rule registry
 type LintConfig struct {
 	// WarningsAsErrors treats all warnings as errors (exit code 1)
 	WarningsAsErrors bool `yaml:"warnings_as_errors" json:"warnings_as_errors"`
 
-	// Diagnostics maps diagnostic codes to enabled/disabled (true/false)
-	Diagnostics map[DiagnosticCode]bool `yaml:"diagnostics" json:"diagnostics"`
+	// Diagnostics maps diagnostic codes to a severity override or true/false
+	Diagnostics map[string]RuleSetting `yaml:"diagnostics" json:"diagnostics"`
+
+	// KnownSysmodsFile lists SYSMOD IDs that exist outside the linted files (e.g. on the
+	// target system). Relative paths are resolved against the config file's directory.
+	KnownSysmodsFile string `yaml:"known_sysmods_file" json:"known_sysmods_file"`
+
+	// Overlays are data files merged into smpe.json in order, after .smpe_ls/overlay.json.
+	// Relative paths are resolved against the config file's directory.
+	Overlays []string `yaml:"overlays" json:"overlays"`
+
+	// TargetRelease is the SMP/E release the MCS are written for, e.g. V3R6. Statements,
+	// operands and values not available in it are reported. Empty allows all releases.
+	TargetRelease string `yaml:"target_release" json:"target_release"`
+
+	// Extensions of the files linted in directories; "" or "." selects files without an
+	// extension. Empty selects DefaultExtensions.
+	Extensions []string `yaml:"extensions" json:"extensions"`
+
+	// Include and Exclude select the files found in directories and by patterns, with
+	// the syntax of .smpe_lintignore. Paths are relative to the current directory.
+	Include []string `yaml:"include" json:"include"`
+	Exclude []string `yaml:"exclude" json:"exclude"`
 }
 
 // DefaultLintConfig returns a config with all diagnostics enabled
 func DefaultLintConfig() *LintConfig {
 	return &LintConfig{
 		WarningsAsErrors: false,
-		Diagnostics:      make(map[DiagnosticCode]bool),
+		Diagnostics:      make(map[string]RuleSetting),
 	}
 }
 
@@ -79,6 +112,15 @@ func LoadConfig(path string) (*LintConfig, error) {
 		return nil, err
 	}
 
+	if config.KnownSysmodsFile != "" && !filepath.IsAbs(config.KnownSysmodsFile) {
+		config.KnownSysmodsFile = filepath.Join(filepath.Dir(path), config.KnownSysmodsFile)
+	}
+	for i, overlay := range config.Overlays {
+		if !filepath.IsAbs(overlay) {
+			config.Overlays[i] = filepath.Join(filepath.Dir(path), overlay)
+		}
+	}
+
 	return config, nil
 }
 
@@ -111,37 +153,16 @@ func FindConfigFile() string {
 	return ""
 }
 
-// IsEnabled returns true if the diagnostic is enabled
-// Defaults to true if not explicitly configured
-func (c *LintConfig) IsEnabled(code DiagnosticCode) bool {
-	if enabled, ok := c.Diagnostics[code]; ok {
-		return enabled
-	}
-	return true // Default: enabled
-}
-
 // ToDiagnosticsConfig converts LintConfig to diagnostics.Config
 func (c *LintConfig) ToDiagnosticsConfig() *diagnostics.Config {
 	cfg := diagnostics.DefaultConfig()
-
-	// Map lint config to diagnostics config
-	cfg.UnknownStatement = c.IsEnabled(DiagUnknownStatement)
-	cfg.InvalidLanguageId = c.IsEnabled(DiagInvalidLanguageID)
-	cfg.UnbalancedParentheses = c.IsEnabled(DiagUnbalancedParentheses)
-	cfg.MissingTerminator = c.IsEnabled(DiagMissingTerminator)
-	cfg.MissingParameter = c.IsEnabled(DiagMissingParameter)
-	cfg.UnknownOperand = c.IsEnabled(DiagUnknownOperand)
-	cfg.DuplicateOperand = c.IsEnabled(DiagDuplicateOperand)
-	cfg.EmptyOperandParameter = c.IsEnabled(DiagEmptyOperandParameter)
-	cfg.MissingRequiredOperand = c.IsEnabled(DiagMissingRequiredOperand)
-	cfg.DependencyViolation = c.IsEnabled(DiagDependencyViolation)
-	cfg.MutuallyExclusive = c.IsEnabled(DiagMutuallyExclusive)
-	cfg.RequiredGroup = c.IsEnabled(DiagRequiredGroup)
-	cfg.MissingInlineData = c.IsEnabled(DiagMissingInlineData)
-	cfg.UnknownSubOperand = c.IsEnabled(DiagUnknownSubOperand)
-	cfg.SubOperandValidation = c.IsEnabled(DiagSubOperandValidation)
-	cfg.ContentBeyondColumn72 = c.IsEnabled(DiagContentBeyondCol72)
-	cfg.StandaloneCommentBetweenMCS = c.IsEnabled(DiagStandaloneCommentBetweenMCS)
+	cfg.Severities = make(map[string]diagnostics.Severity)
+	for code, setting := range c.Diagnostics {
+		if setting != "" {
+			cfg.Severities[code] = diagnostics.Severity(setting)
+		}
+	}
+	cfg.TargetRelease = c.TargetRelease
 
 	return cfg
 }
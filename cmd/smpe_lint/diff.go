@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// diffOp is a line of an edit script: ' ' keeps, '-' deletes and '+' inserts a line
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns the changes from oldText to newText in unified diff format, or "" if
+// the texts are equal
+func unifiedDiff(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// oldPos and newPos are the lines consumed before ops[i]
+	oldPos, newPos := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
+		}
+		if op.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough to share context
+		start := max(0, i-diffContext)
+		last := i
+		for j := i; j < len(ops) && j-last <= 2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				last = j
+			}
+		}
+		end := min(len(ops), last+diffContext+1)
+
+		oldCount, newCount := oldPos[end]-oldPos[start], newPos[end]-newPos[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldPos[start], oldCount), hunkRange(newPos[start], newCount))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines without their line breaks
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// hunkRange formats the start line and length of a hunk; an empty range starts at the
+// line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines computes the shortest edit script turning a into b. It uses the linear space
+// variant of Myers' algorithm, splitting at the middle snake and recursing on both halves,
+// so formatting a large file that changes most lines does not hold every step in memory.
+func diffLines(a, b []string) []diffOp {
+	return appendDiff(make([]diffOp, 0, max(len(a), len(b))), a, b)
+}
+
+// appendDiff appends the edit script turning a into b to ops
+func appendDiff(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	ops = appendLines(ops, ' ', a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		ops = appendLines(ops, '+', b)
+	case len(b) == 0:
+		ops = appendLines(ops, '-', a)
+	default:
+		// Both sides differ at their ends, so the edit script has at least two steps
+		// and the snake splits it into two strictly smaller problems
+		x0, y0, x1, y1 := middleSnake(a, b)
+		ops = appendDiff(ops, a[:x0], b[:y0])
+		ops = appendLines(ops, ' ', a[x0:x1])
+		ops = appendDiff(ops, a[x1:], b[y1:])
+	}
+	return appendLines(ops, ' ', common)
+}
+
+// appendLines appends an operation of the given kind for each line
+func appendLines(ops []diffOp, kind byte, lines []string) []diffOp {
+	for _, line := range lines {
+		ops = append(ops, diffOp{kind, line})
+	}
+	return ops
+}
+
+// middleSnake searches forward from the start and backward from the end of a and b at
+// the same time and returns the snake where the two searches meet, which lies on a
+// shortest edit script. The snake runs from (x0, y0) to (x1, y1) and may be empty.
+func middleSnake(a, b []string) (x0, y0, x1, y1 int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+
+	// forward[k] is the furthest x on diagonal k = x-y from the start, backward[k] the
+	// furthest distance from the end on diagonal k counted from the end
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			startX := x
+			for x < n && x-k < m && a[x] == b[x-k] {
+				x++
+			}
+			forward[offset+k] = x
+
+			// With an odd delta the searches meet after a forward step
+			if c := delta - k; delta%2 != 0 && c >= -(d-1) && c <= d-1 && x+backward[offset+c] >= n {
+				return startX, startX - k, x, x - k
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			startX := x
+			for x < n && x-k < m && a[n-1-x] == b[m-1-(x-k)] {
+				x++
+			}
+			backward[offset+k] = x
+
+			// With an even delta the searches meet after a backward step
+			if c := delta - k; delta%2 == 0 && c >= -d && c <= d && forward[offset+c]+x >= n {
+				return n - x, m - (x - k), n - startX, m - (startX - k)
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	var oldLines, newLines []string
+	for i := 1; i <= 12; i++ {
+		line := "line " + string(rune('a'+i-1))
+		oldLines = append(oldLines, line)
+		switch i {
+		case 2:
+			newLines = append(newLines, "LINE B")
+		case 11:
+			// deleted
+		default:
+			newLines = append(newLines, line)
+		}
+	}
+	oldText := strings.Join(oldLines, "\n") + "\n"
+	newText := strings.Join(newLines, "\n") + "\nline m\n"
+
+	want := `--- a/x.smpe
++++ b/x.smpe
+@@ -1,5 +1,5 @@
+ line a
+-line b
++LINE B
+ line c
+ line d
+ line e
+@@ -8,5 +8,5 @@
+ line h
+ line i
+ line j
+-line k
+ line l
++line m
+`
+	if got := unifiedDiff("x.smpe", oldText, newText); got != want {
+		t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := unifiedDiff("x.smpe", oldText, oldText); got != "" {
+		t.Errorf("Expected no diff for equal texts, got\n%s", got)
+	}
+}
+
+// checkScript verifies that ops turns a into b and returns the number of edits
+func checkScript(t *testing.T, a, b []string, ops []diffOp) int {
+	t.Helper()
+	var gotA, gotB []string
+	edits := 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			gotA = append(gotA, op.text)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.text)
+		}
+		if op.kind != ' ' {
+			edits++
+		}
+	}
+	if strings.Join(gotA, "\n") != strings.Join(a, "\n") || strings.Join(gotB, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("Edit script does not turn %q into %q: %+v", a, b, ops)
+	}
+	return edits
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+
+		// The shortest edit script deletes and inserts everything outside the LCS
+		lcs := make([][]int, len(a)+1)
+		for x := range lcs {
+			lcs[x] = make([]int, len(b)+1)
+		}
+		for x := len(a) - 1; x >= 0; x-- {
+			for y := len(b) - 1; y >= 0; y-- {
+				if a[x] == b[y] {
+					lcs[x][y] = lcs[x+1][y+1] + 1
+				} else {
+					lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+				}
+			}
+		}
+
+		if got, want := checkScript(t, a, b, diffLines(a, b)), len(a)+len(b)-2*lcs[0][0]; got != want {
+			t.Fatalf("diffLines(%q, %q) has %d edits, want %d", a, b, got, want)
+		}
+	}
+}
+
+func TestDiffLinesLargeRewrite(t *testing.T) {
+	// Every other line of a large file changes, as when formatting a vendor SMPMCS
+	var a, b []string
+	for i := 0; i < 20000; i++ {
+		line := fmt.Sprintf("++MOD(M%06d) DISTLIB(AMODLIB).", i)
+		a = append(a, line)
+		if i%2 == 0 {
+			line = fmt.Sprintf("++MOD(M%06d)  DISTLIB(AMODLIB) .", i)
+		}
+		b = append(b, line)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := diffLines(a, b)
+	runtime.ReadMemStats(&after)
+
+	if got := checkScript(t, a, b, ops); got != 20000 {
+		t.Errorf("Got %d edits, want 20000", got)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("diffLines allocated %d MB", allocated>>20)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile lists patterns of files and directories not to lint, one per line
+const IgnoreFile = ".smpe_lintignore"
+
+// DefaultExtensions are the extensions of the files linted in directories
+var DefaultExtensions = []string{".smpe", ".mcs", ".ptf"}
+
+// fileSelector expands the command-line arguments to the files to lint. Directories are
+// searched recursively for files with one of the extensions; files found in directories or
+// by patterns are skipped if they match an exclude or ignore pattern or, if include patterns
+// are given, none of them. Files named explicitly are always linted.
+type fileSelector struct {
+	extensions []string // "" or "." selects files without an extension
+	include    []pattern
+	exclude    []pattern
+	ignore     []pattern // From .smpe_lintignore, negations allowed
+}
+
+// pattern is a gitignore-style pattern: patterns containing a slash match the path
+// relative to the current directory, others the name of a file or directory at any depth.
+// "**" matches any number of directories, a trailing slash matches directories only.
+type pattern struct {
+	glob    string
+	negate  bool
+	dirOnly bool
+	rooted  bool
+}
+
+// parsePattern parses a pattern of an include, exclude or ignore list
+func parsePattern(line string) pattern {
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	p.rooted = strings.Contains(line, "/")
+	p.glob = strings.TrimPrefix(line, "/")
+	return p
+}
+
+// parsePatterns parses a list of patterns
+func parsePatterns(lines []string) []pattern {
+	var patterns []pattern
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, parsePattern(line))
+		}
+	}
+	return patterns
+}
+
+// LoadIgnoreFile reads the patterns of an ignore file; a missing file yields no patterns.
+// Blank lines and lines starting with # are skipped.
+func LoadIgnoreFile(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// matches reports whether the pattern matches a slash-separated relative path
+func (p pattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.rooted {
+		return matchGlob(p.glob, rel)
+	}
+	return matchGlob(p.glob, path.Base(rel))
+}
+
+// matchGlob matches a slash-separated path against a glob in which "**" matches any number
+// of path segments and the other segments follow path.Match
+func matchGlob(glob, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments against glob segments
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// anyMatch reports whether a path or one of its parent directories matches one of the
+// patterns
+func anyMatch(patterns []pattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.matches(rel, isDir) {
+			return true
+		}
+	}
+	if dir := path.Dir(rel); dir != "." && dir != "/" && dir != rel {
+		return anyMatch(patterns, dir, true)
+	}
+	return false
+}
+
+// ignored reports whether a path is ignored: the last ignore pattern matching the path or
+// one of its parent directories decides
+func (s *fileSelector) ignored(rel string, isDir bool) bool {
+	if dir := path.Dir(rel); dir != "." && dir != "/" && dir != rel && s.ignored(dir, true) {
+		return true
+	}
+	ignored := false
+	for _, p := range s.ignore {
+		if p.matches(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// selected reports whether a file found in a directory or by a pattern is linted
+func (s *fileSelector) selected(file string) bool {
+	rel := relativePath(file)
+	if len(s.include) > 0 && !anyMatch(s.include, rel, false) {
+		return false
+	}
+	return !anyMatch(s.exclude, rel, false) && !s.ignored(rel, false)
+}
+
+// hasExtension reports whether a file has one of the extensions
+func (s *fileSelector) hasExtension(file string) bool {
+	ext := filepath.Ext(file)
+	for _, e := range s.extensions {
+		if e == "." {
+			e = ""
+		}
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// collect expands the arguments to the files to lint, without duplicates
+func (s *fileSelector) collect(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		switch info, err := os.Stat(arg); {
+		case err == nil && info.IsDir():
+			found, err := s.walk(arg, s.hasExtension)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		case err == nil || !hasMeta(arg):
+			// Explicit file; a file that doesn't exist is reported when it is read
+			files = append(files, arg)
+		case strings.Contains(filepath.ToSlash(arg), "**"):
+			root, _ := splitGlob(arg)
+			glob := filepath.ToSlash(filepath.Clean(arg))
+			found, err := s.walk(root, func(file string) bool {
+				return matchGlob(glob, filepath.ToSlash(file))
+			})
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		default:
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && !info.IsDir() && s.selected(match) {
+					files = append(files, match)
+				}
+			}
+		}
+	}
+	return uniqueFiles(files), nil
+}
+
+// walk returns the files below root accepted by accept and selected. Hidden and ignored
+// directories are skipped.
+func (s *fileSelector) walk(root string, accept func(string) bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != root && (strings.HasPrefix(d.Name(), ".") || s.ignored(relativePath(file), true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && accept(file) && s.selected(file) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// hasMeta reports whether a path contains glob metacharacters
+func hasMeta(arg string) bool {
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// splitGlob splits a pattern into the directory before the first segment with
+// metacharacters and the rest
+func splitGlob(arg string) (string, string) {
+	segments := strings.Split(filepath.ToSlash(arg), "/")
+	for i, seg := range segments {
+		if hasMeta(seg) {
+			root := strings.Join(segments[:i], "/")
+			if root == "" && i > 0 {
+				root = "/"
+			} else if root == "" {
+				root = "."
+			}
+			return filepath.FromSlash(root), strings.Join(segments[i:], "/")
+		}
+	}
+	return arg, ""
+}
+
+// relativePath returns a path relative to the current directory if possible, with
+// forward slashes
+func relativePath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// uniqueFiles removes duplicate file paths from a slice
+func uniqueFiles(files []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if !seen[file] {
+			seen[file] = true
+			result = append(result, file)
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"**/*.smpe", "a.smpe", true},
+		{"**/*.smpe", "pkg/sub/a.smpe", true},
+		{"pkg/**/*.smpe", "pkg/a.smpe", true},
+		{"pkg/**/*.smpe", "other/a.smpe", false},
+		{"pkg/*.smpe", "pkg/sub/a.smpe", false},
+		{"pkg/**", "pkg/sub/a.mcs", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v", tt.glob, tt.name, got)
+		}
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, file := range []string{"pkg/a.smpe", "pkg/b.mcs", "pkg/c.txt", "pkg/NOEXT", "pkg/old/d.smpe", "pkg/keep.bak.smpe", ".git/e.smpe", "top.smpe"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	selector := &fileSelector{
+		extensions: DefaultExtensions,
+		ignore:     parsePatterns([]string{"old/", "*.bak.smpe", "!keep.bak.smpe"}),
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"."}, []string{"pkg/a.smpe", "pkg/b.mcs", "pkg/keep.bak.smpe", "top.smpe"}},
+		{[]string{"pkg/**/*.smpe"}, []string{"pkg/a.smpe", "pkg/keep.bak.smpe"}},
+		{[]string{"*.smpe", "top.smpe"}, []string{"top.smpe"}},
+		// Explicit files are linted even if ignored
+		{[]string{"pkg/old/d.smpe"}, []string{"pkg/old/d.smpe"}},
+	}
+	for _, tt := range tests {
+		got, err := selector.collect(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range got {
+			got[i] = filepath.ToSlash(got[i])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("collect(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	selector.extensions = []string{"."}
+	selector.exclude = parsePatterns([]string{"pkg/c*"})
+	if got, _ := selector.collect([]string{"pkg"}); len(got) != 1 || filepath.ToSlash(got[0]) != "pkg/NOEXT" {
+		t.Errorf("Expected only the file without extension, got %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/cybersorcerer/smpe_ls/internal/codeactions"
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+	"github.com/cybersorcerer/smpe_ls/internal/formatting"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+)
+
+// maxFixPasses limits the analyze-and-fix passes of --fix; fixes can expose further issues
+const maxFixPasses = 10
+
+// fixer applies the safe fixes of --fix and the formatting of --format-check/--format-write
+type fixer struct {
+	parser      *parser.Parser
+	diagnostics *diagnostics.Provider
+	actions     *codeactions.Provider
+	formatter   *formatting.Provider
+	config      *diagnostics.Config
+}
+
+// fix normalizes abbreviated operand names and applies the preferred fixes of the
+// diagnostics of text until none are left. It returns the fixed text and the number of
+// changes made. Workspace diagnostics have no fixes and are not analyzed.
+func (f *fixer) fix(uri, text string) (string, int) {
+	text, total := f.actions.NormalizeAliases(f.parser.Parse(text), text)
+	for pass := 0; pass < maxFixPasses; pass++ {
+		doc := f.parser.Parse(text)
+		diags := f.diagnostics.Analyze(uri, doc, nil, f.config, text)
+		var applied int
+		text, applied = f.actions.FixAll(doc, text, diags)
+		if applied == 0 {
+			break
+		}
+		total += applied
+	}
+	return text, total
+}
+
+// format returns text formatted like the language server's document formatting
+func (f *fixer) format(doc *parser.Document, text string) string {
+	return codeactions.ApplyEdits(text, f.formatter.FormatDocument(doc, text))
+}
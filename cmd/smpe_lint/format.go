@@ -0,0 +1,428 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// Formats lists the output formats accepted by --format
+var Formats = []string{"markdown", "json", "sarif", "junit", "checkstyle", "github", "gitlab"}
+
+// validFormat checks if a format is one of Formats
+func validFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReport writes the report in one of Formats
+func writeReport(w io.Writer, format string, report *Report) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "sarif":
+		return writeSARIF(w, report)
+	case "junit":
+		return writeJUnit(w, report)
+	case "checkstyle":
+		return writeCheckstyle(w, report)
+	case "github":
+		return writeGitHub(w, report)
+	case "gitlab":
+		return writeGitLab(w, report)
+	default:
+		return writeMarkdown(w, report)
+	}
+}
+
+// writeMarkdown writes the report as Markdown, e.g. for GitLab/GitHub CI job logs
+func writeMarkdown(w io.Writer, report *Report) error {
+	if len(report.Files) > 0 {
+		fmt.Fprintln(w, "# SMP/E Lint Report")
+		fmt.Fprintln(w)
+		for _, file := range report.Files {
+			fmt.Fprintf(w, "## File: `%s`\n", file.Path)
+			for _, d := range file.Diagnostics {
+				severityIcon := "🔴"
+				if d.Severity == "WARNING" {
+					severityIcon = "⚠️"
+				}
+
+				// Format: - 🔴 **ERROR** [code] (Line X, Col Y): Message
+				if d.Code != "" {
+					fmt.Fprintf(w, "- %s **%s** `%s` (Line %d, Col %d): %s\n",
+						severityIcon, d.Severity, d.Code, d.Line, d.Column, d.Message)
+				} else {
+					fmt.Fprintf(w, "- %s **%s** (Line %d, Col %d): %s\n",
+						severityIcon, d.Severity, d.Line, d.Column, d.Message)
+				}
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if len(report.FixedBaseline) > 0 {
+		fmt.Fprintln(w, "## Fixed Baseline Entries")
+		for _, entry := range report.FixedBaseline {
+			fmt.Fprintf(w, "- `%s` `%s` in %s: %s", entry.File, entry.Code, entry.Statement, entry.Message)
+			if entry.Count > 1 {
+				fmt.Fprintf(w, " (%d times)", entry.Count)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Summary Footer
+	fmt.Fprintln(w, "## Summary")
+	fmt.Fprintf(w, "- **Files checked**: %d\n", report.Summary.TotalFiles)
+	if report.Summary.Baselined > 0 || len(report.FixedBaseline) > 0 {
+		fmt.Fprintf(w, "- **Baseline**: %d findings suppressed, %d entries fixed", report.Summary.Baselined, len(report.FixedBaseline))
+		if len(report.FixedBaseline) > 0 {
+			fmt.Fprint(w, " (run --write-baseline to remove them)")
+		}
+		fmt.Fprintln(w)
+	}
+
+	if report.Summary.Success {
+		fmt.Fprintf(w, "- **Result**: ✅ SUCCESS\n")
+	} else {
+		fmt.Fprintf(w, "- **Files with issues**: %d\n", report.Summary.FilesWithIssues)
+		fmt.Fprintf(w, "- **Total Errors**: %d\n", report.Summary.TotalErrors)
+		fmt.Fprintf(w, "- **Total Warnings**: %d\n", report.Summary.TotalWarnings)
+		fmt.Fprintf(w, "- **Result**: 🔴 FAILURE\n")
+	}
+	return nil
+}
+
+// assignFingerprints sets the fingerprint of every finding. It is derived from the path, the
+// code, the message and the number of identical findings before it in the file, so it stays
+// the same when lines are inserted or removed elsewhere in the file.
+func assignFingerprints(report *Report) {
+	for i := range report.Files {
+		file := &report.Files[i]
+		seen := make(map[string]int)
+		for j := range file.Diagnostics {
+			d := &file.Diagnostics[j]
+			key := filepath.ToSlash(file.Path) + "\x00" + d.Code + "\x00" + d.Message
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, seen[key])))
+			seen[key]++
+			d.Fingerprint = hex.EncodeToString(sum[:16])
+		}
+	}
+}
+
+// sarifLevel maps an LSP severity to a SARIF level
+func sarifLevel(severity int) string {
+	switch severity {
+	case lsp.SeverityError:
+		return "error"
+	case lsp.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// SARIF 2.1.0 structures, limited to the properties smpe_lint fills in
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		HelpURI              string             `json:"helpUri"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+		Properties           sarifRuleProps     `json:"properties"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifRuleProps struct {
+		Tags []string `json:"tags"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             sarifMessage      `json:"message"`
+		Locations           []sarifLocation   `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifact `json:"artifactLocation"`
+		Region           sarifRegion   `json:"region"`
+	}
+	sarifArtifact struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+	}
+)
+
+// writeSARIF writes the report as SARIF 2.1.0, e.g. for code scanning dashboards
+func writeSARIF(w io.Writer, report *Report) error {
+	driver := sarifDriver{
+		Name:           "smpe_lint",
+		Version:        version,
+		InformationURI: "https://github.com/cybersorcerer/smpe_ls",
+	}
+	ruleIndex := make(map[string]int)
+	for i, rule := range diagnostics.Rules {
+		ruleIndex[rule.Code] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.Code,
+			ShortDescription:     sarifMessage{rule.Description},
+			HelpURI:              rule.DocsURL(),
+			DefaultConfiguration: sarifConfiguration{sarifLevel(rule.Severity)},
+			Properties:           sarifRuleProps{Tags: []string{rule.Group}},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{driver}, Results: []sarifResult{}}
+	for _, file := range report.Files {
+		for _, d := range file.Diagnostics {
+			level := "warning"
+			if d.Severity == "ERROR" {
+				level = "error"
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    d.Code,
+				RuleIndex: ruleIndex[d.Code],
+				Level:     level,
+				Message:   sarifMessage{d.Message},
+				Locations: []sarifLocation{{sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifact{filepath.ToSlash(file.Path)},
+					Region:           sarifRegion{StartLine: d.Line, StartColumn: d.Column},
+				}}},
+				PartialFingerprints: map[string]string{"smpeLint/v1": d.Fingerprint},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// JUnit XML structures: one test suite per file and one test case per finding; files
+// without findings get a single passing test case
+type (
+	junitSuites struct {
+		XMLName  xml.Name     `xml:"testsuites"`
+		Name     string       `xml:"name,attr"`
+		Tests    int          `xml:"tests,attr"`
+		Failures int          `xml:"failures,attr"`
+		Suites   []junitSuite `xml:"testsuite"`
+	}
+	junitSuite struct {
+		Name      string      `xml:"name,attr"`
+		Tests     int         `xml:"tests,attr"`
+		Failures  int         `xml:"failures,attr"`
+		TestCases []junitCase `xml:"testcase"`
+	}
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// writeJUnit writes the report as JUnit XML, e.g. for Jenkins test reports
+func writeJUnit(w io.Writer, report *Report) error {
+	suites := junitSuites{Name: "smpe_lint"}
+	for _, path := range report.Checked {
+		suite := junitSuite{Name: path}
+		for _, d := range report.diagnosticsOf(path) {
+			suite.TestCases = append(suite.TestCases, junitCase{
+				Name:      fmt.Sprintf("%s (line %d, col %d)", d.Code, d.Line, d.Column),
+				ClassName: path,
+				Failure: &junitFailure{
+					Message: d.Message,
+					Type:    d.Severity,
+					Text:    fmt.Sprintf("%s:%d:%d: %s %s: %s", path, d.Line, d.Column, d.Severity, d.Code, d.Message),
+				},
+			})
+			suite.Failures++
+		}
+		if len(suite.TestCases) == 0 {
+			suite.TestCases = []junitCase{{Name: "smpe_lint", ClassName: path}}
+		}
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return writeXML(w, suites)
+}
+
+// Checkstyle XML structures
+type (
+	checkstyleReport struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+)
+
+// writeCheckstyle writes the report as Checkstyle XML
+func writeCheckstyle(w io.Writer, report *Report) error {
+	out := checkstyleReport{Version: "4.3"}
+	for _, path := range report.Checked {
+		file := checkstyleFile{Name: path}
+		for _, d := range report.diagnosticsOf(path) {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     d.Line,
+				Column:   d.Column,
+				Severity: strings.ToLower(d.Severity),
+				Message:  d.Message,
+				Source:   "smpe_lint." + d.Code,
+			})
+		}
+		out.Files = append(out.Files, file)
+	}
+	return writeXML(w, out)
+}
+
+// writeXML writes an indented XML document
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeGitHub writes the findings as GitHub Actions workflow commands, which show up as
+// annotations on the changed files
+func writeGitHub(w io.Writer, report *Report) error {
+	for _, file := range report.Files {
+		for _, d := range file.Diagnostics {
+			command := "warning"
+			if d.Severity == "ERROR" {
+				command = "error"
+			}
+			fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s\n", command,
+				githubProperty(filepath.ToSlash(file.Path)), d.Line, d.Column,
+				githubProperty("smpe_lint "+d.Code), githubData(d.Message))
+		}
+	}
+	return nil
+}
+
+// githubData escapes the message of a workflow command
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property value of a workflow command
+func githubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(githubData(s))
+}
+
+// gitlabIssue is an entry of a GitLab Code Quality report
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// writeGitLab writes the report as a GitLab Code Quality report
+func writeGitLab(w io.Writer, report *Report) error {
+	issues := []gitlabIssue{}
+	for _, file := range report.Files {
+		for _, d := range file.Diagnostics {
+			severity := "minor"
+			if d.Severity == "ERROR" {
+				severity = "major"
+			}
+			issues = append(issues, gitlabIssue{
+				Description: d.Message,
+				CheckName:   d.Code,
+				Fingerprint: d.Fingerprint,
+				Severity:    severity,
+				Location: gitlabLocation{
+					Path:  filepath.ToSlash(file.Path),
+					Lines: gitlabLines{Begin: d.Line},
+				},
+			})
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
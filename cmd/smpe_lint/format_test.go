@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+)
+
+func sampleReport() *Report {
+	report := &Report{
+		Checked: []string{"a.smpe", "b.smpe"},
+		Files: []FileReport{{
+			Path:   "a.smpe",
+			Status: "failure",
+			Diagnostics: []DiagnosticItem{
+				{Line: 1, Column: 27, Severity: "WARNING", Code: "unknown_operand", Message: "Unknown operand 'FOO'"},
+				{Line: 2, Column: 1, Severity: "ERROR", Code: "missing_terminator", Message: "Missing terminator, 100%"},
+				{Line: 3, Column: 27, Severity: "WARNING", Code: "unknown_operand", Message: "Unknown operand 'FOO'"},
+			},
+		}},
+	}
+	report.Summary.TotalFiles = 2
+	assignFingerprints(report)
+	return report
+}
+
+func TestFingerprintsIgnoreLines(t *testing.T) {
+	report := sampleReport()
+	first := report.Files[0].Diagnostics
+	if first[0].Fingerprint == first[2].Fingerprint {
+		t.Errorf("Expected identical findings to get distinct fingerprints")
+	}
+
+	moved := sampleReport()
+	for i := range moved.Files[0].Diagnostics {
+		moved.Files[0].Diagnostics[i].Line += 10
+		moved.Files[0].Diagnostics[i].Fingerprint = ""
+	}
+	assignFingerprints(moved)
+	for i, d := range moved.Files[0].Diagnostics {
+		if d.Fingerprint != first[i].Fingerprint {
+			t.Errorf("Fingerprint of finding %d changed when its line moved", i)
+		}
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeReport(&out, "sarif", sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid SARIF JSON: %v", err)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(diagnostics.Rules) {
+		t.Errorf("Expected %d rules, got %d", len(diagnostics.Rules), len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(run.Results))
+	}
+	result := run.Results[1]
+	if run.Tool.Driver.Rules[result.RuleIndex].ID != "missing_terminator" || result.Level != "error" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.PartialFingerprints["smpeLint/v1"] == "" {
+		t.Errorf("Expected a fingerprint, got %+v", result)
+	}
+}
+
+func TestWriteXMLFormats(t *testing.T) {
+	for format, target := range map[string]any{"junit": &junitSuites{}, "checkstyle": &checkstyleReport{}} {
+		var out bytes.Buffer
+		if err := writeReport(&out, format, sampleReport()); err != nil {
+			t.Fatal(err)
+		}
+		if err := xml.Unmarshal(out.Bytes(), target); err != nil {
+			t.Errorf("%s: invalid XML: %v", format, err)
+		}
+		// Files without findings are listed as well
+		if !strings.Contains(out.String(), `"b.smpe"`) {
+			t.Errorf("%s: expected b.smpe in the report:\n%s", format, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	writeJUnit(&out, sampleReport())
+	if !strings.Contains(out.String(), `<testsuites name="smpe_lint" tests="4" failures="3">`) {
+		t.Errorf("Unexpected JUnit totals:\n%s", out.String())
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var out bytes.Buffer
+	writeGitHub(&out, sampleReport())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 annotations, got %q", lines)
+	}
+	if lines[1] != "::error file=a.smpe,line=2,col=1,title=smpe_lint missing_terminator::Missing terminator, 100%25" {
+		t.Errorf("Unexpected annotation: %s", lines[1])
+	}
+}
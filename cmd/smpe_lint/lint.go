@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cybersorcerer/smpe_ls/internal/codeactions"
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+	"github.com/cybersorcerer/smpe_ls/internal/formatting"
+	"github.com/cybersorcerer/smpe_ls/internal/index"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// linter lints sets of files with one configuration. Files are read, parsed and analyzed
+// concurrently; the parser, providers and smpe.json data are only read and shared.
+type linter struct {
+	statements  map[string]data.MCSStatement
+	parser      *parser.Parser
+	diagnostics *diagnostics.Provider
+	config      *diagnostics.Config
+	fixer       *fixer
+	fix         bool      // Apply safe fixes before linting (--fix)
+	baseline    *Baseline // Findings not to report, if any
+	jobs        int       // Number of files processed concurrently
+
+	warningsAsErrors bool
+}
+
+// newLinter creates a linter for the statements of a store
+func newLinter(store *data.Store, config *diagnostics.Config) *linter {
+	p := parser.NewParser(store.Statements)
+	provider := diagnostics.NewProvider(store)
+	return &linter{
+		statements:  store.Statements,
+		parser:      p,
+		diagnostics: provider,
+		config:      config,
+		fixer: &fixer{
+			parser:      p,
+			diagnostics: provider,
+			actions:     codeactions.NewProvider(store),
+			formatter:   formatting.NewProvider(),
+			config:      config,
+		},
+		jobs: runtime.GOMAXPROCS(0),
+	}
+}
+
+// forEach calls fn with 0 to n-1 on up to jobs goroutines
+func forEach(n, jobs int, fn func(i int)) {
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < min(max(jobs, 1), n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// lintFile is a file read for linting
+type lintFile struct {
+	path string
+	uri  string
+	text string
+	doc  *parser.Document
+}
+
+// source is a file to lint; content is set for stdin, which is never written back
+type source struct {
+	path    string
+	content []byte
+}
+
+// load reads and parses the sources, applying safe fixes if enabled. Files that cannot
+// be read are reported to stderr and counted.
+func (l *linter) load(sources []source) ([]lintFile, int) {
+	type loaded struct {
+		file     lintFile
+		ok       bool
+		messages []string // Written to stderr in the order of the sources
+	}
+	results := make([]loaded, len(sources))
+	forEach(len(sources), l.jobs, func(i int) {
+		results[i].file, results[i].ok, results[i].messages = l.loadFile(sources[i])
+	})
+
+	var files []lintFile
+	failed := 0
+	for _, r := range results {
+		for _, msg := range r.messages {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		if !r.ok {
+			failed++
+			continue
+		}
+		files = append(files, r.file)
+	}
+	return files, failed
+}
+
+// loadFile reads, fixes and parses a source. It returns the messages to report and
+// whether the file could be read (and written, if fixed).
+func (l *linter) loadFile(src source) (lintFile, bool, []string) {
+	content := src.content
+	if content == nil {
+		var err error
+		content, err = os.ReadFile(src.path)
+		if err != nil {
+			return lintFile{}, false, []string{fmt.Sprintf("Error reading file %s: %v", src.path, err)}
+		}
+	}
+
+	var messages []string
+	lf := lintFile{path: src.path, uri: fileURI(src.path), text: string(content)}
+	if l.fix && src.content == nil {
+		fixed, count := l.fixer.fix(lf.uri, lf.text)
+		if fixed != lf.text {
+			if err := os.WriteFile(src.path, []byte(fixed), 0644); err != nil {
+				return lintFile{}, false, []string{fmt.Sprintf("Error writing file %s: %v", src.path, err)}
+			}
+			messages = append(messages, fmt.Sprintf("Fixed %d issues in %s", count, src.path))
+			lf.text = fixed
+		}
+	}
+	lf.doc = l.parser.Parse(lf.text)
+	return lf, true, messages
+}
+
+// lint reads and lints the sources
+func (l *linter) lint(sources []source) *Report {
+	files, failed := l.load(sources)
+	checked := make([]string, len(sources))
+	for i, src := range sources {
+		checked[i] = src.path
+	}
+	return l.analyze(files, checked, failed)
+}
+
+// analyze lints parsed files. All files are indexed first, so SYSMOD IDs can be checked
+// across files. checked lists all files, failed the number that could not be read.
+func (l *linter) analyze(files []lintFile, checked []string, failed int) *Report {
+	report := &Report{
+		Files:   []FileReport{},
+		Checked: checked,
+	}
+	report.Summary.TotalFiles = len(checked)
+	report.Summary.TotalErrors = failed
+	hasErrors := failed > 0
+
+	var baseline *baselineMatcher
+	if l.baseline != nil {
+		baseline = newBaselineMatcher(l.baseline)
+	}
+
+	// Index in file order, so messages naming other files do not depend on scheduling
+	ix := index.New(l.parser, l.statements)
+	for _, lf := range files {
+		ix.Open(lf.uri, lf.doc, lf.text)
+	}
+
+	results := make([][]lsp.Diagnostic, len(files))
+	forEach(len(files), l.jobs, func(i int) {
+		diags := l.diagnostics.Analyze(files[i].uri, files[i].doc, ix, l.config, files[i].text)
+		sort.SliceStable(diags, func(a, b int) bool {
+			pa, pb := diags[a].Range.Start, diags[b].Range.Start
+			if pa.Line != pb.Line {
+				return pa.Line < pb.Line
+			}
+			return pa.Character < pb.Character
+		})
+		results[i] = diags
+	})
+
+	// The report is built in file order; baseline entries are consumed in this order too
+	for i, lf := range files {
+		file := lf.path
+		diags := results[i]
+
+		fileReport := FileReport{
+			Path:        file,
+			Status:      "success",
+			Diagnostics: []DiagnosticItem{},
+		}
+
+		lines := strings.Split(lf.text, "\n")
+		hasFileIssues := false
+		for _, d := range diags {
+			// Only process errors and warnings
+			if d.Severity == lsp.SeverityError || d.Severity == lsp.SeverityWarning {
+				item := DiagnosticItem{
+					Line:    d.Range.Start.Line + 1,
+					Column:  d.Range.Start.Character + 1,
+					Code:    d.Code,
+					Message: cleanMessage(d.Message),
+				}
+				item.baseline = baselineEntry(file, lf.doc, lines, item)
+				if baseline != nil && baseline.match(item.baseline) {
+					report.Summary.Baselined++
+					continue
+				}
+				hasFileIssues = true
+
+				if d.Severity == lsp.SeverityError {
+					item.Severity = "ERROR"
+					report.Summary.TotalErrors++
+					if fileReport.Status != "failure" {
+						fileReport.Status = "failure"
+					}
+					hasErrors = true
+				} else {
+					item.Severity = "WARNING"
+					report.Summary.TotalWarnings++
+					if fileReport.Status == "success" {
+						fileReport.Status = "warning"
+					}
+					// Warnings cause failure only if --warnings-as-errors is set
+					if l.warningsAsErrors {
+						hasErrors = true
+					}
+				}
+
+				fileReport.Diagnostics = append(fileReport.Diagnostics, item)
+			}
+		}
+
+		if hasFileIssues {
+			report.Summary.FilesWithIssues++
+			report.Files = append(report.Files, fileReport)
+		}
+	}
+
+	report.Summary.Success = !hasErrors
+	if baseline != nil {
+		report.FixedBaseline = baseline.fixed(checked)
+	}
+	assignFingerprints(report)
+	return report
+}
+
+// formatFiles compares the files with their formatted text. With write the files are
+// rewritten, otherwise the differences are written to w as unified diffs. It returns the
+// number of files not formatted.
+func (l *linter) formatFiles(w io.Writer, files []lintFile, write bool) (int, error) {
+	unformatted := 0
+	for _, lf := range files {
+		formatted := l.fixer.format(lf.doc, lf.text)
+		if formatted == lf.text {
+			continue
+		}
+		unformatted++
+		if !write {
+			fmt.Fprint(w, unifiedDiff(relativePath(lf.path), lf.text, formatted))
+			continue
+		}
+		if err := os.WriteFile(lf.path, []byte(formatted), 0644); err != nil {
+			return unformatted, fmt.Errorf("writing file %s: %w", lf.path, err)
+		}
+		fmt.Fprintf(os.Stderr, "Formatted %s\n", lf.path)
+	}
+	return unformatted, nil
+}
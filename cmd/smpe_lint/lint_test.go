@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+)
+
+// generateCorpus creates files of PTFs with a module each. SYSMODs require the previous
+// one, also across files, and every seventh has an unknown operand and an unresolved
+// requisite so that findings are spread over all files.
+func generateCorpus(files, sysmods int) []source {
+	corpus := make([]source, files)
+	id := 0
+	for f := range corpus {
+		var b strings.Builder
+		for s := 0; s < sysmods; s++ {
+			id++
+			fmt.Fprintf(&b, "++PTF(UA%05d) /* Fix %d */ .\n", id, id)
+			fmt.Fprintf(&b, "++VER(Z038) FMID(HBB77C0) PRE(UA%05d) .\n", max(id-1, 1))
+			fmt.Fprintf(&b, "++MOD(M%07d) DISTLIB(AMODLIB) RELFILE(1)", id)
+			if id%7 == 0 {
+				fmt.Fprintf(&b, " FOO(X) .\n++IF FMID(HBB77C0) THEN REQ(UZ%05d) .\n", id)
+			} else {
+				b.WriteString(" .\n")
+			}
+		}
+		corpus[f] = source{path: fmt.Sprintf("pkg%03d.smpe", f), content: []byte(b.String())}
+	}
+	return corpus
+}
+
+// corpusLinter creates a linter with the built-in smpe.json
+func corpusLinter(tb testing.TB, jobs int) *linter {
+	tb.Helper()
+	store, err := data.Load("")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	l := newLinter(store, diagnostics.DefaultConfig())
+	l.jobs = jobs
+	return l
+}
+
+func TestLintJobsDeterministic(t *testing.T) {
+	corpus := generateCorpus(20, 20)
+
+	var reports []string
+	for _, jobs := range []int{1, 8} {
+		var out bytes.Buffer
+		if err := writeReport(&out, "json", corpusLinter(t, jobs).lint(corpus)); err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, out.String())
+	}
+	if reports[0] != reports[1] {
+		t.Errorf("Report with 8 jobs differs from the sequential report")
+	}
+	if !strings.Contains(reports[0], "unknown_operand") || !strings.Contains(reports[0], "unresolved_sysmod_reference") {
+		t.Errorf("Expected findings in the corpus:\n%.500s", reports[0])
+	}
+}
+
+func BenchmarkLint(b *testing.B) {
+	corpus := generateCorpus(200, 50)
+	counts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		counts = append(counts, n)
+	}
+	for _, jobs := range counts {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			l := corpusLinter(b, jobs)
+			for b.Loop() {
+				l.lint(corpus)
+			}
+		})
+	}
+}
@@ -1,17 +1,16 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/cybersorcerer/smpe_ls/internal/data"
 	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
-	"github.com/cybersorcerer/smpe_ls/internal/parser"
-	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
 )
 
 var (
@@ -23,6 +22,20 @@ var (
 type Report struct {
 	Summary Summary      `json:"summary"`
 	Files   []FileReport `json:"files"`
+	Checked []string     `json:"-"` // All linted files, also those without findings
+
+	// FixedBaseline lists the baseline entries of the linted files that no longer occur
+	FixedBaseline []BaselineEntry `json:"fixed_baseline,omitempty"`
+}
+
+// diagnosticsOf returns the findings of a file
+func (r *Report) diagnosticsOf(path string) []DiagnosticItem {
+	for _, file := range r.Files {
+		if file.Path == path {
+			return file.Diagnostics
+		}
+	}
+	return nil
 }
 
 type Summary struct {
@@ -31,6 +44,9 @@ type Summary struct {
 	TotalErrors     int  `json:"total_errors"`
 	TotalWarnings   int  `json:"total_warnings"`
 	Success         bool `json:"success"`
+
+	// Baselined counts the findings not reported because they are recorded in the baseline
+	Baselined int `json:"baselined,omitempty"`
 }
 
 type FileReport struct {
@@ -45,46 +61,97 @@ type DiagnosticItem struct {
 	Severity string `json:"severity"`
 	Code     string `json:"code,omitempty"`
 	Message  string `json:"message"`
+
+	// Fingerprint identifies the finding independently of its line, see assignFingerprints
+	Fingerprint string `json:"fingerprint"`
+
+	baseline BaselineEntry // Identity of the finding in a baseline
 }
 
 func main() {
 	// Flags
-	jsonMode := flag.Bool("json", false, "Output results in JSON format")
+	jsonMode := flag.Bool("json", false, "Output results in JSON format (same as --format json)")
+	format := flag.String("format", "", "Output format: "+strings.Join(Formats, ", ")+" (default markdown)")
 	versionFlag := flag.Bool("version", false, "Show version information")
 	shortVersionFlag := flag.Bool("v", false, "Show version information")
 	configFile := flag.String("config", "", "Path to configuration file (.smpe_lint.yaml or .smpe_lint.json)")
 	warningsAsErrors := flag.Bool("warnings-as-errors", false, "Treat warnings as errors (exit code 1)")
 	initConfig := flag.String("init", "", "Create a sample configuration file (yaml or json)")
+	knownSysmods := flag.String("known-sysmods", "", "Path to a list of SYSMOD IDs that exist outside the linted files")
+	dataPath := flag.String("data", "", "Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json if present, else the built-in copy)")
+	dumpData := flag.String("dump-data", "", "Write the built-in smpe.json to a file (- for stdout) and exit")
+	targetRelease := flag.String("target-release", "", "SMP/E release the MCS are written for, e.g. V3R6")
+	baselinePath := flag.String("baseline", "", "Report only findings not recorded in this baseline file")
+	writeBaseline := flag.String("write-baseline", "", "Record the current findings in a baseline file and exit")
+	fixFlag := flag.Bool("fix", false, "Apply safe fixes to the files before linting them")
+	formatCheck := flag.Bool("format-check", false, "Print the changes formatting would make as a unified diff and exit")
+	formatWrite := flag.Bool("format-write", false, "Format the files in place and exit")
+	stdinFilename := flag.String("stdin-filename", "<stdin>", "Name of the file read from stdin (-) in the report")
+	watchMode := flag.Bool("watch", false, "Lint the files again whenever they change")
+	jobs := flag.Int("jobs", 0, "Number of files linted concurrently (default: number of CPUs)")
 	var disableFlags arrayFlags
 	flag.Var(&disableFlags, "disable", "Disable specific diagnostic (can be used multiple times)")
+	var severityFlags arrayFlags
+	flag.Var(&severityFlags, "severity", "Override the severity of a diagnostic, e.g. unknown_operand=error (can be used multiple times)")
+	var overlayFlags arrayFlags
+	flag.Var(&overlayFlags, "overlay", "Merge a data overlay into smpe.json (can be used multiple times)")
+	var extFlags arrayFlags
+	flag.Var(&extFlags, "ext", "Extensions of the files linted in directories, e.g. .smpe,.mcs (can be used multiple times)")
+	var includeFlags arrayFlags
+	flag.Var(&includeFlags, "include", "Lint only files matching a pattern in directories (can be used multiple times)")
+	var excludeFlags arrayFlags
+	flag.Var(&excludeFlags, "exclude", "Skip files matching a pattern in directories (can be used multiple times)")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <file-pattern>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nLints SMP/E MCS files and reports diagnostics.\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <file|directory|pattern|->...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nLints SMP/E MCS files and reports diagnostics. Directories are searched recursively,\n")
+		fmt.Fprintf(os.Stderr, "patterns may contain ** and - reads stdin.\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
-		fmt.Fprintf(os.Stderr, "  --config <path>       Path to configuration file (.smpe_lint.yaml or .smpe_lint.json)\n")
-		fmt.Fprintf(os.Stderr, "  --disable <code>      Disable specific diagnostic (can be used multiple times)\n")
-		fmt.Fprintf(os.Stderr, "  --init <format>       Create sample config file (yaml or json)\n")
-		fmt.Fprintf(os.Stderr, "  --json                Output results in JSON format\n")
-		fmt.Fprintf(os.Stderr, "  --version, -v         Show version information\n")
-		fmt.Fprintf(os.Stderr, "  --warnings-as-errors  Treat warnings as errors (exit code 1)\n")
+		fmt.Fprintf(os.Stderr, "  --baseline <path>       Report only findings not recorded in the baseline file\n")
+		fmt.Fprintf(os.Stderr, "  --config <path>         Path to configuration file (.smpe_lint.yaml or .smpe_lint.json)\n")
+		fmt.Fprintf(os.Stderr, "  --data <path>           Path to smpe.json (default: installed copy if present, else built-in)\n")
+		fmt.Fprintf(os.Stderr, "  --disable <code>        Disable specific diagnostic (can be used multiple times)\n")
+		fmt.Fprintf(os.Stderr, "  --dump-data <path>      Write the built-in smpe.json to a file (- for stdout) and exit\n")
+		fmt.Fprintf(os.Stderr, "  --exclude <pattern>     Skip files matching a pattern in directories (can be used multiple times)\n")
+		fmt.Fprintf(os.Stderr, "  --ext <extensions>      Extensions of the files linted in directories (default .smpe,.mcs,.ptf;\n")
+		fmt.Fprintf(os.Stderr, "                          . for files without an extension)\n")
+		fmt.Fprintf(os.Stderr, "  --fix                   Apply safe fixes (terminators, column 72, operand aliases, ...)\n")
+		fmt.Fprintf(os.Stderr, "                          to the files before linting them\n")
+		fmt.Fprintf(os.Stderr, "  --format <format>       Output format: markdown (default), json, sarif, junit,\n")
+		fmt.Fprintf(os.Stderr, "                          checkstyle, github or gitlab\n")
+		fmt.Fprintf(os.Stderr, "  --format-check          Print the changes formatting would make as a unified diff\n")
+		fmt.Fprintf(os.Stderr, "                          and exit (exit code 1 if a file is not formatted)\n")
+		fmt.Fprintf(os.Stderr, "  --format-write          Format the files in place and exit\n")
+		fmt.Fprintf(os.Stderr, "  --include <pattern>     Lint only files matching a pattern in directories (can be used multiple times)\n")
+		fmt.Fprintf(os.Stderr, "  --init <format>         Create sample config file (yaml or json)\n")
+		fmt.Fprintf(os.Stderr, "  --jobs <n>              Number of files linted concurrently (default: number of CPUs)\n")
+		fmt.Fprintf(os.Stderr, "  --json                  Output results in JSON format (same as --format json)\n")
+		fmt.Fprintf(os.Stderr, "  --known-sysmods <path>  List of SYSMOD IDs that exist outside the linted files\n")
+		fmt.Fprintf(os.Stderr, "  --overlay <path>        Merge a data overlay into smpe.json (can be used multiple times)\n")
+		fmt.Fprintf(os.Stderr, "  --severity <code=sev>   Override the severity of a diagnostic: off, hint, info, warning or error\n")
+		fmt.Fprintf(os.Stderr, "                          (can be used multiple times)\n")
+		fmt.Fprintf(os.Stderr, "  --stdin-filename <name> Name of the file read from stdin (-) in the report\n")
+		fmt.Fprintf(os.Stderr, "  --target-release <rel>  SMP/E release the MCS are written for, e.g. V3R6\n")
+		fmt.Fprintf(os.Stderr, "  --version, -v           Show version information\n")
+		fmt.Fprintf(os.Stderr, "  --warnings-as-errors    Treat warnings as errors (exit code 1)\n")
+		fmt.Fprintf(os.Stderr, "  --watch                 Lint the files again whenever they change\n")
+		fmt.Fprintf(os.Stderr, "  --write-baseline <path> Record the current findings in a baseline file and exit\n")
 		fmt.Fprintf(os.Stderr, "\nDiagnostic Codes:\n")
-		fmt.Fprintf(os.Stderr, "  Syntax:\n")
-		fmt.Fprintf(os.Stderr, "    unknown_statement, invalid_language_id, unbalanced_parentheses,\n")
-		fmt.Fprintf(os.Stderr, "    missing_terminator, missing_parameter, content_beyond_column_72\n")
-		fmt.Fprintf(os.Stderr, "  Operands:\n")
-		fmt.Fprintf(os.Stderr, "    unknown_operand, duplicate_operand, empty_operand_parameter,\n")
-		fmt.Fprintf(os.Stderr, "    missing_required_operand, dependency_violation, mutually_exclusive,\n")
-		fmt.Fprintf(os.Stderr, "    required_group\n")
-		fmt.Fprintf(os.Stderr, "  Sub-Operands:\n")
-		fmt.Fprintf(os.Stderr, "    unknown_sub_operand, sub_operand_validation\n")
-		fmt.Fprintf(os.Stderr, "  Structural:\n")
-		fmt.Fprintf(os.Stderr, "    missing_inline_data, standalone_comment_between_mcs\n")
+		printRuleCodes(os.Stderr)
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --exclude 'archive/' packages\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s \"packages/**/*.mcs\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  cat a.smpe | %s --stdin-filename a.smpe -\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --warnings-as-errors *.smpe\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --disable unknown_operand *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --severity duplicate_operand=error *.smpe\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --config .smpe_lint.yaml *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --format sarif *.smpe > smpe_lint.sarif\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --write-baseline baseline.json *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --baseline baseline.json --warnings-as-errors *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --fix *.smpe\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --format-check *.smpe\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -97,6 +164,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle --dump-data to write the built-in smpe.json for customization
+	if *dumpData != "" {
+		if err := data.DumpEmbedded(*dumpData); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing smpe.json: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Handle --init to create sample config
 	if *initConfig != "" {
 		if err := createSampleConfig(*initConfig); err != nil {
@@ -112,32 +188,36 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Collect files from all arguments
-	// This handles both:
-	// 1. Shell-expanded: smpe_lint file1.smpe file2.smpe file3.smpe
-	// 2. Quoted glob: smpe_lint "*.smpe"
-	var files []string
+	outputFormat := strings.ToLower(*format)
+	if outputFormat == "" {
+		outputFormat = "markdown"
+		if *jsonMode {
+			outputFormat = "json"
+		}
+	}
+	if !validFormat(outputFormat) {
+		fmt.Fprintf(os.Stderr, "Unknown format '%s' (use %s)\n", *format, strings.Join(Formats, ", "))
+		os.Exit(1)
+	}
+	if *formatCheck && *formatWrite {
+		fmt.Fprintf(os.Stderr, "--format-check and --format-write cannot be used together\n")
+		os.Exit(1)
+	}
+
+	// "-" lints stdin, which can neither be combined with files nor written back
+	stdin := flag.NArg() == 1 && flag.Arg(0) == "-"
 	for _, arg := range flag.Args() {
-		// Try to expand as glob pattern
-		matches, err := filepath.Glob(arg)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error expanding pattern '%s': %v\n", arg, err)
+		if arg == "-" && !stdin {
+			fmt.Fprintf(os.Stderr, "- (stdin) cannot be combined with other arguments\n")
 			os.Exit(1)
 		}
-		if len(matches) == 0 {
-			// No matches - could be an explicit file that doesn't exist
-			// or a pattern with no matches. Add it anyway to get proper error later.
-			files = append(files, arg)
-		} else {
-			files = append(files, matches...)
-		}
 	}
-
-	// Remove duplicates (in case shell expansion and glob both match same files)
-	files = uniqueFiles(files)
-
-	if len(files) == 0 {
-		fmt.Fprintf(os.Stderr, "No files found matching arguments\n")
+	if stdin && (*fixFlag || *formatWrite || *watchMode) {
+		fmt.Fprintf(os.Stderr, "--fix, --format-write and --watch cannot be used with stdin\n")
+		os.Exit(1)
+	}
+	if *watchMode && (*formatCheck || *formatWrite || *writeBaseline != "") {
+		fmt.Fprintf(os.Stderr, "--watch cannot be used with --format-check, --format-write or --write-baseline\n")
 		os.Exit(1)
 	}
 
@@ -163,156 +243,169 @@ func main() {
 		lintConfig.WarningsAsErrors = true
 	}
 
-	// Apply --disable flags
+	if *knownSysmods != "" {
+		lintConfig.KnownSysmodsFile = *knownSysmods
+	}
+
+	lintConfig.Overlays = append(lintConfig.Overlays, overlayFlags...)
+
+	if *targetRelease != "" {
+		lintConfig.TargetRelease = *targetRelease
+	}
+	if _, _, ok := data.ParseRelease(lintConfig.TargetRelease); lintConfig.TargetRelease != "" && !ok {
+		fmt.Fprintf(os.Stderr, "Invalid target release '%s' (expected e.g. V3R6)\n", lintConfig.TargetRelease)
+		os.Exit(1)
+	}
+
+	// Apply --disable and --severity flags
+	if lintConfig.Diagnostics == nil {
+		lintConfig.Diagnostics = make(map[string]RuleSetting)
+	}
 	for _, code := range disableFlags {
-		if lintConfig.Diagnostics == nil {
-			lintConfig.Diagnostics = make(map[DiagnosticCode]bool)
+		lintConfig.Diagnostics[code] = RuleSetting(diagnostics.SeverityOff)
+	}
+	for _, override := range severityFlags {
+		code, severity, ok := strings.Cut(override, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Invalid --severity '%s' (expected code=severity)\n", override)
+			os.Exit(1)
 		}
-		lintConfig.Diagnostics[DiagnosticCode(code)] = false
+		lintConfig.Diagnostics[strings.TrimSpace(code)] = RuleSetting(strings.TrimSpace(severity))
 	}
 
-	// Load smpe.json
-	dataPath := os.Getenv("HOME") + "/.local/share/smpe_ls/smpe.json"
-	store, err := data.Load(dataPath)
+	// Load smpe.json ("" selects the built-in copy) and merge the overlays, starting
+	// with the workspace overlay in the current directory
+	var layers []data.Layer
+	if _, err := os.Stat(data.OverlayFile); err == nil {
+		layers = append(layers, data.Layer{Name: data.OverlayFile, Path: data.OverlayFile})
+	}
+	for _, overlay := range lintConfig.Overlays {
+		layers = append(layers, data.Layer{Name: overlay, Path: overlay})
+	}
+	resolvedDataPath := data.ResolvePath(*dataPath)
+	store, err := data.LoadLayers(resolvedDataPath, layers)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading smpe.json from %s: %v\n", dataPath, err)
+		fmt.Fprintf(os.Stderr, "Error loading smpe.json %s: %v\n", resolvedDataPath, err)
 		os.Exit(1)
 	}
 
-	diagProvider := diagnostics.NewProvider(store)
 	diagConfig := lintConfig.ToDiagnosticsConfig()
-
-	report := Report{
-		Files: []FileReport{},
+	for _, err := range diagnostics.CheckSeverities(diagConfig.Severities) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
-	report.Summary.TotalFiles = len(files)
-	report.Summary.Success = true
-
-	hasErrors := false
-
-	for _, file := range files {
-		content, err := os.ReadFile(file)
+	if lintConfig.KnownSysmodsFile != "" {
+		diagConfig.KnownSysmods, err = diagnostics.LoadKnownSysmods(lintConfig.KnownSysmodsFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", file, err)
-			report.Summary.TotalErrors++
-			hasErrors = true
-			continue
+			fmt.Fprintf(os.Stderr, "Error loading known SYSMODs from %s: %v\n", lintConfig.KnownSysmodsFile, err)
+			os.Exit(1)
 		}
+	}
 
-		// Create parser and parse
-		p := parser.NewParser(store.Statements)
-		doc := p.Parse(string(content))
-
-		// Analyze with config
-		diags := diagProvider.AnalyzeASTWithConfigAndText(doc, diagConfig, string(content))
+	l := newLinter(store, diagConfig)
+	l.fix = *fixFlag
+	l.warningsAsErrors = lintConfig.WarningsAsErrors
+	if *jobs > 0 {
+		l.jobs = *jobs
+	}
 
-		fileReport := FileReport{
-			Path:        file,
-			Status:      "success",
-			Diagnostics: []DiagnosticItem{},
+	// Findings recorded in the baseline are not reported (unless a new baseline is written)
+	if *baselinePath != "" && *writeBaseline == "" {
+		l.baseline, err = LoadBaseline(*baselinePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading baseline %s: %v\n", *baselinePath, err)
+			os.Exit(1)
 		}
+	}
 
-		hasFileIssues := false
-		for _, d := range diags {
-			// Determine diagnostic code from message
-			code := determineDiagnosticCode(d.Message)
-
-			// Check if this diagnostic is enabled
-			if !lintConfig.IsEnabled(code) {
-				continue
-			}
-
-			// Only process errors and warnings
-			if d.Severity == lsp.SeverityError || d.Severity == lsp.SeverityWarning {
-				hasFileIssues = true
-
-				item := DiagnosticItem{
-					Line:    d.Range.Start.Line + 1,
-					Column:  d.Range.Start.Character + 1,
-					Code:    string(code),
-					Message: cleanMessage(d.Message),
-				}
-
-				if d.Severity == lsp.SeverityError {
-					item.Severity = "ERROR"
-					report.Summary.TotalErrors++
-					if fileReport.Status != "failure" {
-						fileReport.Status = "failure"
-					}
-					hasErrors = true
-				} else {
-					item.Severity = "WARNING"
-					report.Summary.TotalWarnings++
-					if fileReport.Status == "success" {
-						fileReport.Status = "warning"
-					}
-					// Warnings cause failure only if --warnings-as-errors is set
-					if lintConfig.WarningsAsErrors {
-						hasErrors = true
-					}
-				}
-
-				fileReport.Diagnostics = append(fileReport.Diagnostics, item)
-			}
+	// Collect the files: explicit files, directories (searched recursively) and patterns,
+	// also quoted ones such as "packages/**/*.smpe" the shell does not expand
+	ignoreLines, err := LoadIgnoreFile(IgnoreFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", IgnoreFile, err)
+		os.Exit(1)
+	}
+	selector := &fileSelector{
+		extensions: lintConfig.Extensions,
+		include:    parsePatterns(append(lintConfig.Include, includeFlags...)),
+		exclude:    parsePatterns(append(lintConfig.Exclude, excludeFlags...)),
+		ignore:     parsePatterns(ignoreLines),
+	}
+	if len(extFlags) > 0 {
+		selector.extensions = nil
+		for _, ext := range extFlags {
+			selector.extensions = append(selector.extensions, strings.Split(ext, ",")...)
 		}
+	}
+	if len(selector.extensions) == 0 {
+		selector.extensions = DefaultExtensions
+	}
 
-		if hasFileIssues {
-			report.Summary.FilesWithIssues++
-			report.Files = append(report.Files, fileReport)
+	if *watchMode {
+		if err := watch(os.Stdout, l, selector, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
-	report.Summary.Success = !hasErrors
-
-	if *jsonMode {
-		// JSON Output
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(report); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+	var sources []source
+	if stdin {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
 			os.Exit(1)
 		}
+		sources = append(sources, source{path: *stdinFilename, content: content})
 	} else {
-		// Markdown Output
-		if len(report.Files) > 0 {
-			fmt.Println("# SMP/E Lint Report")
-			fmt.Println()
-			for _, file := range report.Files {
-				fmt.Printf("## File: `%s`\n", file.Path)
-				for _, d := range file.Diagnostics {
-					severityIcon := "🔴"
-					if d.Severity == "WARNING" {
-						severityIcon = "⚠️"
-					}
-
-					// Format: - 🔴 **ERROR** [code] (Line X, Col Y): Message
-					if d.Code != "" {
-						fmt.Printf("- %s **%s** `%s` (Line %d, Col %d): %s\n",
-							severityIcon, d.Severity, d.Code, d.Line, d.Column, d.Message)
-					} else {
-						fmt.Printf("- %s **%s** (Line %d, Col %d): %s\n",
-							severityIcon, d.Severity, d.Line, d.Column, d.Message)
-					}
-				}
-				fmt.Println()
-			}
+		files, err := selector.collect(flag.Args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error collecting files: %v\n", err)
+			os.Exit(1)
 		}
+		for _, file := range files {
+			sources = append(sources, source{path: file})
+		}
+	}
+	if len(sources) == 0 {
+		fmt.Fprintf(os.Stderr, "No files found matching arguments\n")
+		os.Exit(1)
+	}
 
-		// Summary Footer
-		fmt.Println("## Summary")
-		fmt.Printf("- **Files checked**: %d\n", report.Summary.TotalFiles)
+	// Formatting modes only compare or rewrite the files
+	if *formatCheck || *formatWrite {
+		files, failed := l.load(sources)
+		unformatted, err := l.formatFiles(os.Stdout, files, *formatWrite)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *formatCheck && unformatted > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d files are not formatted\n", unformatted, len(sources))
+			os.Exit(1)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
-		if report.Summary.Success {
-			fmt.Printf("- **Result**: ✅ SUCCESS\n")
-		} else {
-			fmt.Printf("- **Files with issues**: %d\n", report.Summary.FilesWithIssues)
-			fmt.Printf("- **Total Errors**: %d\n", report.Summary.TotalErrors)
-			fmt.Printf("- **Total Warnings**: %d\n", report.Summary.TotalWarnings)
-			fmt.Printf("- **Result**: 🔴 FAILURE\n")
+	report := l.lint(sources)
+
+	if *writeBaseline != "" {
+		count, err := WriteBaseline(*writeBaseline, report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing baseline %s: %v\n", *writeBaseline, err)
+			os.Exit(1)
 		}
+		fmt.Fprintf(os.Stderr, "Recorded %d findings in %s\n", count, *writeBaseline)
+		os.Exit(0)
+	}
+
+	if err := writeReport(os.Stdout, outputFormat, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(1)
 	}
 
-	if hasErrors {
+	if !report.Summary.Success {
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -330,70 +423,23 @@ func (a *arrayFlags) Set(value string) error {
 	return nil
 }
 
-// determineDiagnosticCode maps diagnostic messages to codes
-func determineDiagnosticCode(message string) DiagnosticCode {
-	msg := strings.ToLower(message)
-
-	// Syntax errors
-	if strings.Contains(msg, "unknown statement") {
-		return DiagUnknownStatement
-	}
-	if strings.Contains(msg, "invalid language identifier") {
-		return DiagInvalidLanguageID
-	}
-	if strings.Contains(msg, "missing closing parenthesis") || strings.Contains(msg, "missing opening parenthesis") {
-		return DiagUnbalancedParentheses
-	}
-	if strings.Contains(msg, "must be terminated") {
-		return DiagMissingTerminator
-	}
-	if strings.Contains(msg, "missing required parameter") {
-		return DiagMissingParameter
-	}
-	if strings.Contains(msg, "beyond column 72") {
-		return DiagContentBeyondCol72
-	}
-
-	// Operand errors
-	if strings.Contains(msg, "unknown operand") {
-		return DiagUnknownOperand
-	}
-	if strings.Contains(msg, "duplicate operand") {
-		return DiagDuplicateOperand
-	}
-	if strings.Contains(msg, "requires a parameter") {
-		return DiagEmptyOperandParameter
-	}
-	if strings.Contains(msg, "missing required operand") {
-		return DiagMissingRequiredOperand
-	}
-	if strings.Contains(msg, "requires") && strings.Contains(msg, "to be specified") {
-		return DiagDependencyViolation
-	}
-	if strings.Contains(msg, "mutually exclusive") {
-		return DiagMutuallyExclusive
-	}
-	if strings.Contains(msg, "one of the following operands must be specified") {
-		return DiagRequiredGroup
-	}
-
-	// Sub-operand errors
-	if strings.Contains(msg, "unknown sub-operand") {
-		return DiagUnknownSubOperand
-	}
-	if strings.Contains(msg, "sub-operand") {
-		return DiagSubOperandValidation
-	}
-
-	// Structural errors
-	if strings.Contains(msg, "expects inline data") {
-		return DiagMissingInlineData
+// printRuleCodes lists the diagnostic codes by group
+func printRuleCodes(w io.Writer) {
+	var group string
+	var codes []string
+	flush := func() {
+		if len(codes) > 0 {
+			fmt.Fprintf(w, "  %s:\n    %s\n", group, strings.Join(codes, ", "))
+		}
 	}
-	if strings.Contains(msg, "comment not allowed") {
-		return DiagStandaloneCommentBetweenMCS
+	for _, rule := range diagnostics.Rules {
+		if rule.Group != group {
+			flush()
+			group, codes = rule.Group, nil
+		}
+		codes = append(codes, rule.Code)
 	}
-
-	return ""
+	flush()
 }
 
 // cleanMessage removes emoji prefixes from diagnostic messages
@@ -406,17 +452,12 @@ func cleanMessage(message string) string {
 	return msg
 }
 
-// uniqueFiles removes duplicate file paths from a slice
-func uniqueFiles(files []string) []string {
-	seen := make(map[string]bool)
-	result := make([]string, 0, len(files))
-	for _, file := range files {
-		if !seen[file] {
-			seen[file] = true
-			result = append(result, file)
-		}
+// fileURI returns the URI identifying a linted file in the index
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
 	}
-	return result
+	return workspace.PathToURI(path)
 }
 
 // createSampleConfig creates a sample configuration file
@@ -427,65 +468,10 @@ func createSampleConfig(format string) error {
 	switch strings.ToLower(format) {
 	case "yaml", "yml":
 		filename = ".smpe_lint.yaml"
-		content = `# SMP/E Lint Configuration
-# Generated by smpe_lint --init yaml
-
-# Treat all warnings as errors (causes exit code 1)
-warnings_as_errors: false
-
-# Enable/disable individual diagnostics (true/false)
-# All diagnostics are enabled by default
-diagnostics:
-  # Syntax Errors
-  unknown_statement: true
-  invalid_language_id: true
-  unbalanced_parentheses: true
-  missing_terminator: true
-  missing_parameter: true
-  content_beyond_column_72: true
-
-  # Operand Validation
-  unknown_operand: true
-  duplicate_operand: true
-  empty_operand_parameter: true
-  missing_required_operand: true
-  dependency_violation: true
-  mutually_exclusive: true
-  required_group: true
-
-  # Sub-Operand Validation
-  unknown_sub_operand: true
-  sub_operand_validation: true
-
-  # Structural Issues
-  missing_inline_data: true
-  standalone_comment_between_mcs: true
-`
+		content = sampleYAML()
 	case "json":
 		filename = ".smpe_lint.json"
-		content = `{
-  "warnings_as_errors": false,
-  "diagnostics": {
-    "unknown_statement": true,
-    "invalid_language_id": true,
-    "unbalanced_parentheses": true,
-    "missing_terminator": true,
-    "missing_parameter": true,
-    "content_beyond_column_72": true,
-    "unknown_operand": true,
-    "duplicate_operand": true,
-    "empty_operand_parameter": true,
-    "missing_required_operand": true,
-    "dependency_violation": true,
-    "mutually_exclusive": true,
-    "required_group": true,
-    "unknown_sub_operand": true,
-    "sub_operand_validation": true,
-    "missing_inline_data": true,
-    "standalone_comment_between_mcs": true
-  }
-}
-`
+		content = sampleJSON()
 	default:
 		return fmt.Errorf("unknown format '%s' (use 'yaml' or 'json')", format)
 	}
@@ -503,3 +489,69 @@ diagnostics:
 	fmt.Printf("Created %s\n", filename)
 	return nil
 }
+
+// sampleYAML returns the sample YAML configuration listing every rule at its default severity
+func sampleYAML() string {
+	var b strings.Builder
+	b.WriteString(`# SMP/E Lint Configuration
+# Generated by smpe_lint --init yaml
+
+# Treat all warnings as errors (causes exit code 1)
+warnings_as_errors: false
+
+# Severity of individual diagnostics: off, hint, info, warning or error
+# (true/false keeps the default severity or turns a rule off).
+# See https://github.com/cybersorcerer/smpe_ls/blob/main/docs/diagnostics.md
+diagnostics:
+`)
+	var group string
+	for _, rule := range diagnostics.Rules {
+		if rule.Group != group {
+			if group != "" {
+				b.WriteString("\n")
+			}
+			group = rule.Group
+			fmt.Fprintf(&b, "  # %s\n", group)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", rule.Code, diagnostics.SeverityName(rule.Severity))
+	}
+	b.WriteString(`
+# SYSMOD IDs that exist outside the linted files (e.g. on the target system),
+# one or more per line, separated by blanks or commas
+# known_sysmods_file: known_sysmods.txt
+
+# Data files merged into smpe.json after .smpe_ls/overlay.json, e.g. to
+# declare site-specific statements and operands
+# overlays:
+#   - site_overlay.json
+
+# SMP/E release the MCS are written for; statements, operands and values
+# not available in it are reported as unsupported_release
+# target_release: V3R6
+
+# Extensions of the files linted in directories ("" for files without one)
+# extensions: [".smpe", ".mcs", ".ptf"]
+
+# Files in directories to lint or skip, in the syntax of .smpe_lintignore
+# include:
+#   - "packages/**"
+# exclude:
+#   - "archive/"
+`)
+	return b.String()
+}
+
+// sampleJSON returns the sample JSON configuration listing every rule at its default severity
+func sampleJSON() string {
+	var b strings.Builder
+	b.WriteString("{\n  \"warnings_as_errors\": false,\n  \"diagnostics\": {\n")
+	for i, rule := range diagnostics.Rules {
+		sep := ","
+		if i == len(diagnostics.Rules)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&b, "    %q: %q%s\n", rule.Code, diagnostics.SeverityName(rule.Severity), sep)
+	}
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
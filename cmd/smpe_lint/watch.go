@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often --watch checks the files for changes
+const watchInterval = 500 * time.Millisecond
+
+// fileState identifies a version of a file on disk
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot returns the state of files; files that cannot be read are left out
+func snapshot(files []string) map[string]fileState {
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			states[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return states
+}
+
+// changedFiles returns the files added, changed or removed between two snapshots
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for file, state := range after {
+		if old, ok := before[file]; !ok || old != state {
+			changed = append(changed, file)
+		}
+	}
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watch lints the files selected by args whenever one of them is added, changed or
+// removed, until the process is interrupted. All files are linted again, so workspace
+// diagnostics stay correct.
+func watch(w io.Writer, l *linter, selector *fileSelector, args []string) error {
+	var last map[string]fileState
+	for {
+		files, err := selector.collect(args)
+		if err != nil {
+			return err
+		}
+		current := snapshot(files)
+		if changed := changedFiles(last, current); last == nil || len(changed) > 0 {
+			sources := make([]source, len(files))
+			for i, file := range files {
+				sources[i] = source{path: file}
+			}
+			report := l.lint(sources)
+			writeWatchSummary(w, report, changed, last == nil)
+
+			// --fix may have rewritten files
+			current = snapshot(files)
+		}
+		last = current
+		time.Sleep(watchInterval)
+	}
+}
+
+// writeWatchSummary writes the compact report of a --watch run, clearing the terminal first
+func writeWatchSummary(w io.Writer, report *Report, changed []string, initial bool) {
+	if isTerminal(w) {
+		fmt.Fprint(w, "\033[H\033[2J")
+	}
+
+	s := report.Summary
+	fmt.Fprintf(w, "[%s] %d files, %d errors, %d warnings", time.Now().Format("15:04:05"), s.TotalFiles, s.TotalErrors, s.TotalWarnings)
+	if !initial {
+		fmt.Fprintf(w, " (changed: %s)", strings.Join(changed, ", "))
+	}
+	fmt.Fprintln(w)
+
+	for _, file := range report.Files {
+		for _, d := range file.Diagnostics {
+			fmt.Fprintf(w, "%s:%d:%d: %s %s: %s\n", file.Path, d.Line, d.Column, strings.ToLower(d.Severity), d.Code, d.Message)
+		}
+	}
+	fmt.Fprintln(w, "Watching for changes, press Ctrl+C to stop")
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
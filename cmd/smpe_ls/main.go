@@ -4,9 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
-	"runtime"
 
+	"github.com/cybersorcerer/smpe_ls/internal/data"
 	"github.com/cybersorcerer/smpe_ls/internal/handler"
 	"github.com/cybersorcerer/smpe_ls/internal/logger"
 	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
@@ -17,28 +16,31 @@ var (
 	commit   = "unknown" // Set via ldflags: -X main.commit=...
 	debug    = flag.Bool("debug", false, "Enable debug logging")
 	showVer  = flag.Bool("version", false, "Show version")
-	dataPath = flag.String("data", "", "Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json)")
+	dataPath = flag.String("data", "", "Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json if present, else the built-in copy)")
+	dumpData = flag.String("dump-data", "", "Write the built-in smpe.json to a file (- for stdout) and exit")
+	validate = flag.Bool("validate-data", false, "Validate the smpe.json data file and exit")
 )
 
-func getDefaultDataPath() string {
-	homeDir, err := os.UserHomeDir()
+// validateData checks the data file and prints the problems found, returning the exit code
+func validateData(path string) int {
+	problems, err := data.Validate(path)
+	name := path
+	if name == "" {
+		name = "built-in smpe.json"
+	}
 	if err != nil {
-		return ""
+		fmt.Fprintf(os.Stderr, "Error validating %s: %v\n", name, err)
+		return 2
 	}
-
-	// Platform-specific data directory
-	if runtime.GOOS == "windows" {
-		// Windows: %LOCALAPPDATA%\smpe_ls\smpe.json
-		localAppData := os.Getenv("LOCALAPPDATA")
-		if localAppData != "" {
-			return filepath.Join(localAppData, "smpe_ls", "smpe.json")
-		}
-		// Fallback if LOCALAPPDATA not set
-		return filepath.Join(homeDir, "AppData", "Local", "smpe_ls", "smpe.json")
+	for _, problem := range problems {
+		fmt.Printf("%s: %s\n", name, problem)
 	}
-
-	// Linux/macOS: ~/.local/share/smpe_ls/smpe.json
-	return filepath.Join(homeDir, ".local", "share", "smpe_ls", "smpe.json")
+	if len(problems) > 0 {
+		fmt.Printf("%d problem(s) found\n", len(problems))
+		return 1
+	}
+	fmt.Printf("%s: OK\n", name)
+	return 0
 }
 
 func main() {
@@ -46,9 +48,13 @@ func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  --data string\n")
-		fmt.Fprintf(os.Stderr, "    	Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json)\n")
+		fmt.Fprintf(os.Stderr, "    	Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json if present, else the built-in copy)\n")
 		fmt.Fprintf(os.Stderr, "  --debug\n")
 		fmt.Fprintf(os.Stderr, "    	Enable debug logging\n")
+		fmt.Fprintf(os.Stderr, "  --dump-data string\n")
+		fmt.Fprintf(os.Stderr, "    	Write the built-in smpe.json to a file (- for stdout) and exit\n")
+		fmt.Fprintf(os.Stderr, "  --validate-data\n")
+		fmt.Fprintf(os.Stderr, "    	Validate the smpe.json data file and exit\n")
 		fmt.Fprintf(os.Stderr, "  --version\n")
 		fmt.Fprintf(os.Stderr, "    	Show version\n")
 	}
@@ -62,6 +68,21 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *dumpData != "" {
+		if err := data.DumpEmbedded(*dumpData); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing smpe.json: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Determine data path ("" selects the built-in copy)
+	finalDataPath := data.ResolvePath(*dataPath)
+
+	if *validate {
+		os.Exit(validateData(finalDataPath))
+	}
+
 	// Initialize logger
 	if err := logger.Init(*debug); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
@@ -71,12 +92,11 @@ func main() {
 
 	logger.Info("smpe_ls version %s (commit: %s) starting", version, commit)
 
-	// Determine data path
-	finalDataPath := *dataPath
 	if finalDataPath == "" {
-		finalDataPath = getDefaultDataPath()
+		logger.Info("Data file: built-in smpe.json")
+	} else {
+		logger.Info("Data file: %s", finalDataPath)
 	}
-	logger.Info("Data file: %s", finalDataPath)
 
 	// Create handler
 	h, err := handler.New(version, commit, finalDataPath)
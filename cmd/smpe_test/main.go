@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,9 +36,11 @@ type TestResult struct {
 }
 
 func main() {
-	// Load smpe.json
-	dataPath := os.Getenv("HOME") + "/.local/share/smpe_ls/smpe.json"
-	store, err := data.Load(dataPath)
+	dataPath := flag.String("data", "", "Path to smpe.json data file (default: ~/.local/share/smpe_ls/smpe.json if present, else the built-in copy)")
+	flag.Parse()
+
+	// Load smpe.json ("" selects the built-in copy)
+	store, err := data.Load(data.ResolvePath(*dataPath))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Error loading statements: %v\n", err)
 		os.Exit(1)
@@ -51,8 +54,8 @@ func main() {
 
 	// Find test-files directory
 	testDir := "./test-files"
-	if len(os.Args) > 1 {
-		testDir = os.Args[1]
+	if flag.NArg() > 0 {
+		testDir = flag.Arg(0)
 	}
 
 	// Get all .smpe test files
@@ -0,0 +1,10 @@
+// Package data embeds the canonical SMP/E MCS statement definitions, so the binaries work
+// without an installed smpe.json
+package data
+
+import _ "embed"
+
+// SMPEJSON is the content of smpe.json
+//
+//go:embed smpe.json
+var SMPEJSON []byte
@@ -0,0 +1,685 @@
+package codeactions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+	"github.com/cybersorcerer/smpe_ls/internal/formatting"
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// InsertSnippetCommand is the client command used to insert a snippet with cursor placement.
+// Arguments: document URI, range to replace, snippet text.
+const InsertSnippetCommand = "smpe.insertSnippet"
+
+// continuationIndent is used for lines created by a fix that have no indentation to inherit
+const continuationIndent = "   "
+
+// maxSuggestionDistance is the maximum edit distance for "did you mean" suggestions
+const maxSuggestionDistance = 2
+
+// Fix is a quick fix resolving a single diagnostic
+type Fix struct {
+	Title string
+	Code  string
+	Edits []lsp.TextEdit
+	// Snippet is the same change with snippet placeholders for cursor placement, if any
+	Snippet *lsp.TextEdit
+	// Preferred fixes are safe to apply without user interaction (e.g. by smpe_lint --fix)
+	Preferred bool
+}
+
+// Provider provides quick fixes for diagnostics
+type Provider struct {
+	statements map[string]data.MCSStatement
+}
+
+// NewProvider creates a new code action provider with shared data
+func NewProvider(store *data.Store) *Provider {
+	return &Provider{
+		statements: store.Statements,
+	}
+}
+
+// CodeActions returns quick fix code actions for the given diagnostics.
+// If snippetCommand is set, fixes with snippets are delivered via InsertSnippetCommand.
+func (p *Provider) CodeActions(uri string, doc *parser.Document, text string, diags []lsp.Diagnostic, snippetCommand bool) []lsp.CodeAction {
+	actions := make([]lsp.CodeAction, 0)
+
+	for _, diag := range diags {
+		for _, fix := range p.Fixes(doc, text, diag) {
+			action := lsp.CodeAction{
+				Title:       fix.Title,
+				Kind:        lsp.CodeActionKindQuickFix,
+				Diagnostics: []lsp.Diagnostic{diag},
+				IsPreferred: fix.Preferred,
+			}
+			if snippetCommand && fix.Snippet != nil {
+				action.Command = &lsp.Command{
+					Title:     fix.Title,
+					Command:   InsertSnippetCommand,
+					Arguments: []interface{}{uri, fix.Snippet.Range, fix.Snippet.NewText},
+				}
+			} else {
+				action.Edit = &lsp.WorkspaceEdit{
+					Changes: map[string][]lsp.TextEdit{uri: fix.Edits},
+				}
+			}
+			actions = append(actions, action)
+		}
+	}
+
+	logger.Debug("Code actions: %d actions for %d diagnostics", len(actions), len(diags))
+	return actions
+}
+
+// FixAll applies the preferred fix of every diagnostic whose edits do not overlap
+// with an earlier fix. It returns the new text and the number of fixes applied.
+// Fixes can expose further issues, so callers should re-analyze and repeat until
+// no more fixes are applied.
+func (p *Provider) FixAll(doc *parser.Document, text string, diags []lsp.Diagnostic) (string, int) {
+	var accepted []lsp.TextEdit
+	applied := 0
+
+	for _, diag := range diags {
+		for _, fix := range p.Fixes(doc, text, diag) {
+			if !fix.Preferred {
+				continue
+			}
+			if overlapsAny(fix.Edits, accepted) {
+				break
+			}
+			accepted = append(accepted, fix.Edits...)
+			applied++
+			break
+		}
+	}
+
+	return ApplyEdits(text, accepted), applied
+}
+
+// NormalizeAliases replaces abbreviated operand names with their primary name, e.g. DESC
+// with DESCRIPTION. Aliases that negate the operand (PACK|NOPACK) are kept. It returns the
+// new text and the number of operands renamed.
+func (p *Provider) NormalizeAliases(doc *parser.Document, text string) (string, int) {
+	var edits []lsp.TextEdit
+	var walk func(nodes []*parser.Node)
+	walk = func(nodes []*parser.Node) {
+		for _, n := range nodes {
+			if n.Type == parser.NodeTypeOperand && n.OperandDef != nil {
+				primary := n.OperandDef.PrimaryName()
+				if n.Name != primary && n.Name != "NO"+primary && n.Position.Length == len(n.Name) {
+					start := nodeStart(n)
+					end := lsp.Position{Line: start.Line, Character: start.Character + n.Position.Length}
+					edits = append(edits, lsp.TextEdit{Range: lsp.Range{Start: start, End: end}, NewText: primary})
+				}
+			}
+			walk(n.Children)
+		}
+	}
+	if doc != nil {
+		walk(doc.Statements)
+	}
+	return ApplyEdits(text, edits), len(edits)
+}
+
+// overlapsAny reports whether any edit overlaps one of the accepted edits
+func overlapsAny(edits []lsp.TextEdit, accepted []lsp.TextEdit) bool {
+	for _, e := range edits {
+		for _, a := range accepted {
+			if rangesOverlap(e.Range, a.Range) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Fixes returns the quick fixes available for a diagnostic
+func (p *Provider) Fixes(doc *parser.Document, text string, diag lsp.Diagnostic) []Fix {
+	if doc == nil || text == "" {
+		return nil
+	}
+
+	src := newSource(text)
+	code := diag.Code
+
+	var fixes []Fix
+	switch code {
+	case diagnostics.CodeMissingTerminator:
+		fixes = p.fixMissingTerminator(doc, src, diag)
+	case diagnostics.CodeUnbalancedParentheses:
+		fixes = p.fixUnbalancedParentheses(doc, src, diag)
+	case diagnostics.CodeMissingParameter:
+		fixes = p.fixMissingParameter(doc, src, diag)
+	case diagnostics.CodeUnknownStatement:
+		fixes = p.fixUnknownStatement(diag)
+	case diagnostics.CodeUnknownOperand:
+		fixes = p.fixUnknownOperand(doc, src, diag)
+	case diagnostics.CodeMutuallyExclusive:
+		fixes = p.fixRemoveOperand(doc, src, diag)
+	case diagnostics.CodeDuplicateOperand:
+		fixes = p.fixDuplicateOperand(doc, src, diag)
+	case diagnostics.CodeEmptyOperandParameter:
+		fixes = p.fixEmptyOperandParameter(doc, src, diag)
+	case diagnostics.CodeMissingRequiredOperand, diagnostics.CodeRequiredGroup, diagnostics.CodeDependencyViolation:
+		fixes = p.fixMissingOperands(doc, src, diag, diagnostics.FixDataOf(diag).Operands)
+	case diagnostics.CodeContentBeyondCol72:
+		fixes = p.fixContentBeyondColumn72(src, diag)
+	case diagnostics.CodeStandaloneCommentBetweenMCS:
+		fixes = p.fixStandaloneComment(doc, src, diag)
+	}
+
+	for i := range fixes {
+		fixes[i].Code = code
+	}
+	return fixes
+}
+
+// fixMissingTerminator inserts the '.' terminator after the statement's last operand
+func (p *Provider) fixMissingTerminator(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	stmt := findStatement(doc, diag.Range.Start)
+	if stmt == nil {
+		return nil
+	}
+
+	ext := src.scanStatement(nodeStart(stmt), lastNodeLine(stmt))
+	if ext.terminator != nil {
+		return nil
+	}
+
+	return []Fix{{
+		Title:     "Add terminator '.'",
+		Edits:     []lsp.TextEdit{insertAt(ext.contentEnd, ".")},
+		Preferred: true,
+	}}
+}
+
+// fixUnbalancedParentheses inserts a missing closing parenthesis at the end of the statement
+func (p *Provider) fixUnbalancedParentheses(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	stmt := findStatement(doc, diag.Range.Start)
+	if stmt == nil || stmt.UnbalancedParens <= 0 {
+		return nil
+	}
+
+	ext := src.scanStatement(nodeStart(stmt), lastNodeLine(stmt))
+	pos := ext.contentEnd
+	line := src.line(pos.Line)
+	if pos.Character > 0 && pos.Character <= len(line) && line[pos.Character-1] == '.' {
+		pos.Character--
+	}
+
+	return []Fix{{
+		Title: "Add closing parenthesis ')'",
+		Edits: []lsp.TextEdit{insertAt(pos, strings.Repeat(")", stmt.UnbalancedParens))},
+	}}
+}
+
+// fixMissingParameter inserts empty parentheses after the statement name
+func (p *Provider) fixMissingParameter(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	stmt := findStatement(doc, diag.Range.Start)
+	if stmt == nil || stmt.StatementDef == nil {
+		return nil
+	}
+
+	pos := lsp.Position{Line: stmt.Position.Line, Character: stmt.Position.Character + stmt.Position.Length}
+	return []Fix{{
+		Title:   "Add parameter " + stmt.StatementDef.Parameter,
+		Edits:   []lsp.TextEdit{insertAt(pos, "()")},
+		Snippet: snippetAt(pos, "($1)"),
+	}}
+}
+
+// fixUnknownStatement suggests known statements with a similar name
+func (p *Provider) fixUnknownStatement(diag lsp.Diagnostic) []Fix {
+	name := diagnostics.FixDataOf(diag).Name
+	if name == "" {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(p.statements))
+	for stmtName := range p.statements {
+		candidates = append(candidates, stmtName)
+	}
+
+	var fixes []Fix
+	for _, suggestion := range suggestions(name, candidates) {
+		fixes = append(fixes, Fix{
+			Title: "Change to " + suggestion,
+			Edits: []lsp.TextEdit{{Range: diag.Range, NewText: suggestion}},
+		})
+	}
+	return fixes
+}
+
+// fixUnknownOperand suggests valid operands with a similar name and offers removal
+func (p *Provider) fixUnknownOperand(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	node := findNode(doc, diag.Range.Start)
+	if node == nil || node.Type != parser.NodeTypeOperand {
+		return nil
+	}
+	stmt := statementOf(node)
+
+	var fixes []Fix
+	if stmt != nil && stmt.StatementDef != nil {
+		var candidates []string
+		for _, op := range stmt.StatementDef.Operands {
+			candidates = append(candidates, strings.Split(op.Name, "|")...)
+		}
+		nameRange := lsp.Range{
+			Start: diag.Range.Start,
+			End:   lsp.Position{Line: diag.Range.Start.Line, Character: diag.Range.Start.Character + len([]rune(node.Name))},
+		}
+		for _, suggestion := range suggestions(node.Name, candidates) {
+			fixes = append(fixes, Fix{
+				Title: "Change to " + suggestion,
+				Edits: []lsp.TextEdit{{Range: nameRange, NewText: suggestion}},
+			})
+		}
+	}
+
+	return append(fixes, removeOperandFix(src, node, "Remove unknown operand "+node.Name))
+}
+
+// fixRemoveOperand removes the operand the diagnostic points at
+func (p *Provider) fixRemoveOperand(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	node := findNode(doc, diag.Range.Start)
+	if node == nil || node.Type != parser.NodeTypeOperand {
+		return nil
+	}
+	return []Fix{removeOperandFix(src, node, "Remove operand "+node.Name)}
+}
+
+// fixDuplicateOperand merges a duplicate list operand into its first occurrence,
+// or removes the duplicate for other operand types
+func (p *Provider) fixDuplicateOperand(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	dup := findNode(doc, diag.Range.Start)
+	if dup == nil || dup.Type != parser.NodeTypeOperand || dup.Parent == nil {
+		return nil
+	}
+
+	var first *parser.Node
+	for _, child := range dup.Parent.Children {
+		if child.Type == parser.NodeTypeOperand && child.Name == dup.Name {
+			first = child
+			break
+		}
+	}
+	if first == nil || first == dup {
+		return nil
+	}
+
+	removeDup := src.removalRange(nodeStart(dup), src.operandEnd(nodeStart(dup)))
+	firstValues := operandValues(first)
+	dupValues := operandValues(dup)
+
+	if first.OperandDef != nil && first.OperandDef.Type == "list" && len(firstValues) > 0 {
+		merged := firstValues
+		seen := make(map[string]bool)
+		for _, v := range firstValues {
+			seen[v] = true
+		}
+		for _, v := range dupValues {
+			if !seen[v] {
+				seen[v] = true
+				merged = append(merged, v)
+			}
+		}
+
+		// Replace the content between the first occurrence's parentheses
+		end := src.operandEnd(nodeStart(first))
+		open := lsp.Position{Line: first.Position.Line, Character: first.Position.Character + len([]rune(first.Name)) + 1}
+		closing := lsp.Position{Line: end.Line, Character: end.Character - 1}
+		if !positionBefore(open, end) {
+			return nil
+		}
+
+		return []Fix{{
+			Title: "Merge duplicate operand " + dup.Name,
+			Edits: []lsp.TextEdit{
+				{Range: lsp.Range{Start: open, End: closing}, NewText: strings.Join(merged, ",")},
+				{Range: removeDup, NewText: ""},
+			},
+			Preferred: true,
+		}}
+	}
+
+	// Removing is only safe without user interaction if nothing is lost
+	return []Fix{{
+		Title:     "Remove duplicate operand " + dup.Name,
+		Edits:     []lsp.TextEdit{{Range: removeDup, NewText: ""}},
+		Preferred: strings.Join(firstValues, ",") == strings.Join(dupValues, ","),
+	}}
+}
+
+// fixEmptyOperandParameter adds parentheses to an operand written without a parameter
+func (p *Provider) fixEmptyOperandParameter(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	node := findNode(doc, diag.Range.Start)
+	if node == nil || node.Type != parser.NodeTypeOperand {
+		return nil
+	}
+
+	pos := lsp.Position{Line: node.Position.Line, Character: node.Position.Character + len([]rune(node.Name))}
+	line := src.line(pos.Line)
+	if pos.Character < len(line) && line[pos.Character] == '(' {
+		// Parentheses exist but are empty - nothing to insert
+		return nil
+	}
+
+	return []Fix{{
+		Title:   "Add parameter to " + node.Name,
+		Edits:   []lsp.TextEdit{insertAt(pos, "()")},
+		Snippet: snippetAt(pos, "($1)"),
+	}}
+}
+
+// fixMissingOperands offers to insert a skeleton for each of the given operands
+func (p *Provider) fixMissingOperands(doc *parser.Document, src *source, diag lsp.Diagnostic, names []string) []Fix {
+	stmt := findStatement(doc, diag.Range.Start)
+	if stmt == nil || stmt.StatementDef == nil {
+		return nil
+	}
+
+	var fixes []Fix
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		op := findOperandDef(stmt.StatementDef, name)
+		if name == "" || op == nil {
+			continue
+		}
+		fix := operandInsertionFix(src, stmt, name, op.Parameter != "")
+		fixes = append(fixes, fix)
+	}
+	return fixes
+}
+
+// fixContentBeyondColumn72 re-wraps a line at column 72
+func (p *Provider) fixContentBeyondColumn72(src *source, diag lsp.Diagnostic) []Fix {
+	lineNum := diag.Range.Start.Line
+	line := src.line(lineNum)
+	if line == nil {
+		return nil
+	}
+
+	indent := src.indentation(lineNum)
+	if indent == "" {
+		indent = continuationIndent
+	}
+	content := string(line[:src.contentEnd(lineNum)])
+	wrapped := formatting.WrapLine(content, indent)
+	for _, l := range strings.Split(wrapped, "\n") {
+		if len([]rune(l)) > diagnostics.MaxColumn {
+			// No suitable break point
+			return nil
+		}
+	}
+
+	return []Fix{{
+		Title: "Wrap line at column 72",
+		Edits: []lsp.TextEdit{{
+			Range: lsp.Range{
+				Start: lsp.Position{Line: lineNum, Character: 0},
+				End:   lsp.Position{Line: lineNum, Character: len(line)},
+			},
+			NewText: wrapped,
+		}},
+		Preferred: true,
+	}}
+}
+
+// fixStandaloneComment moves a comment between MCS statements onto the terminator
+// line of the preceding statement, where SMP/E accepts it. Blank lines between the
+// statements are kept, so the fix is not preferred and FixAll leaves comments alone.
+func (p *Provider) fixStandaloneComment(doc *parser.Document, src *source, diag lsp.Diagnostic) []Fix {
+	commentStart := diag.Range.Start
+
+	// Find the preceding non-blank line
+	prev := commentStart.Line - 1
+	for prev >= 0 && src.contentEnd(prev) == 0 {
+		prev--
+	}
+	if prev < 0 || len(doc.Statements) == 0 || doc.Statements[0].Position.Line > prev {
+		// Comment before the first statement - there is no line to attach it to
+		return nil
+	}
+
+	prevEnd := lsp.Position{Line: prev, Character: src.contentEnd(prev)}
+	commentLine := src.line(commentStart.Line)
+	firstLine := strings.TrimRight(string(commentLine[commentStart.Character:]), " \t")
+	ownLine := strings.TrimSpace(string(commentLine[:commentStart.Character])) == "" && strings.HasSuffix(firstLine, "*/")
+
+	var edits []lsp.TextEdit
+	if ownLine && prevEnd.Character+1+len([]rune(firstLine)) <= diagnostics.MaxColumn {
+		// Move the comment onto the terminator line and remove its own line
+		lineEnd := lsp.Position{Line: commentStart.Line + 1}
+		if lineEnd.Line >= len(src.lines) {
+			lineEnd = lsp.Position{Line: commentStart.Line, Character: len(commentLine)}
+		}
+		edits = []lsp.TextEdit{
+			insertAt(prevEnd, " "+firstLine),
+			{Range: lsp.Range{Start: lsp.Position{Line: commentStart.Line}, End: lineEnd}},
+		}
+	} else {
+		// Open the comment on the terminator line and let it continue on its own line
+		edits = []lsp.TextEdit{
+			insertAt(prevEnd, " /*"),
+			{
+				Range: lsp.Range{
+					Start: commentStart,
+					End:   lsp.Position{Line: commentStart.Line, Character: commentStart.Character + 2},
+				},
+				NewText: "  ",
+			},
+		}
+	}
+
+	return []Fix{{
+		Title: "Convert to inline comment",
+		Edits: edits,
+	}}
+}
+
+// operandInsertionFix builds a fix inserting an operand before the statement's terminator
+func operandInsertionFix(src *source, stmt *parser.Node, name string, hasParameter bool) Fix {
+	ext := src.scanStatement(nodeStart(stmt), lastNodeLine(stmt))
+	pos := ext.contentEnd
+	if ext.terminator != nil {
+		pos = *ext.terminator
+	}
+
+	text := name
+	snippet := name
+	if hasParameter {
+		text += "()"
+		snippet += "($1)"
+	}
+
+	// Start a continuation line if the operand does not fit before column 72
+	separator := " "
+	if len(src.line(pos.Line))+1+len([]rune(text)) > diagnostics.MaxColumn {
+		separator = "\n" + continuationIndent
+	}
+
+	fix := Fix{
+		Title: "Add operand " + name,
+		Edits: []lsp.TextEdit{insertAt(pos, separator+text)},
+	}
+	if hasParameter {
+		fix.Snippet = snippetAt(pos, separator+snippet)
+	}
+	return fix
+}
+
+// removeOperandFix builds a fix removing an operand including its parameter
+func removeOperandFix(src *source, node *parser.Node, title string) Fix {
+	start := nodeStart(node)
+	return Fix{
+		Title: title,
+		Edits: []lsp.TextEdit{{Range: src.removalRange(start, src.operandEnd(start)), NewText: ""}},
+	}
+}
+
+// operandValues returns the parameter values of an operand, one per list item
+func operandValues(op *parser.Node) []string {
+	var values []string
+	for _, param := range op.Children {
+		if param.Type != parser.NodeTypeParameter {
+			continue
+		}
+		if len(param.Children) > 0 {
+			for _, item := range param.Children {
+				if v := strings.TrimSpace(item.Value); item.Type == parser.NodeTypeParameter && v != "" {
+					values = append(values, v)
+				}
+			}
+			continue
+		}
+		for _, v := range strings.Split(param.Value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// findOperandDef finds an operand definition by any of its names
+func findOperandDef(stmtDef *data.MCSStatement, name string) *data.Operand {
+	for i, op := range stmtDef.Operands {
+		for _, alias := range strings.Split(op.Name, "|") {
+			if alias == name {
+				return &stmtDef.Operands[i]
+			}
+		}
+	}
+	return nil
+}
+
+// findNode returns the deepest node starting exactly at pos
+func findNode(doc *parser.Document, pos lsp.Position) *parser.Node {
+	var found *parser.Node
+	var walk func(nodes []*parser.Node)
+	walk = func(nodes []*parser.Node) {
+		for _, n := range nodes {
+			if n.Position.Line == pos.Line && n.Position.Character == pos.Character {
+				found = n
+			}
+			walk(n.Children)
+		}
+	}
+	walk(doc.Statements)
+	return found
+}
+
+// findStatement returns the statement containing the node at pos, or the last
+// statement starting at or before pos
+func findStatement(doc *parser.Document, pos lsp.Position) *parser.Node {
+	if node := findNode(doc, pos); node != nil {
+		return statementOf(node)
+	}
+
+	var stmt *parser.Node
+	for _, s := range doc.Statements {
+		if s.Position.Line > pos.Line {
+			break
+		}
+		stmt = s
+	}
+	return stmt
+}
+
+// statementOf returns the statement a node belongs to
+func statementOf(node *parser.Node) *parser.Node {
+	for n := node; n != nil; n = n.Parent {
+		if n.Type == parser.NodeTypeStatement {
+			return n
+		}
+	}
+	return nil
+}
+
+// lastNodeLine returns the last line covered by a node or any of its descendants
+func lastNodeLine(node *parser.Node) int {
+	last := node.Position.Line
+	for _, child := range node.Children {
+		if l := lastNodeLine(child); l > last {
+			last = l
+		}
+	}
+	return last
+}
+
+// nodeStart returns the start position of a node
+func nodeStart(node *parser.Node) lsp.Position {
+	return lsp.Position{Line: node.Position.Line, Character: node.Position.Character}
+}
+
+// insertAt creates an edit inserting text at pos
+func insertAt(pos lsp.Position, text string) lsp.TextEdit {
+	return lsp.TextEdit{Range: lsp.Range{Start: pos, End: pos}, NewText: text}
+}
+
+// snippetAt creates a snippet edit inserting snippet at pos
+func snippetAt(pos lsp.Position, snippet string) *lsp.TextEdit {
+	edit := insertAt(pos, snippet)
+	return &edit
+}
+
+// suggestions returns the candidates within maxSuggestionDistance of name, closest first
+func suggestions(name string, candidates []string) []string {
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	upper := strings.ToUpper(name)
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+		if d := editDistance(upper, c); d <= maxSuggestionDistance {
+			matches = append(matches, scored{c, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var result []string
+	for i, m := range matches {
+		if i == 3 {
+			break
+		}
+		result = append(result, m.name)
+	}
+	return result
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,255 @@
+package codeactions
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/diagnostics"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+type testEnv struct {
+	parser      *parser.Parser
+	diagnostics *diagnostics.Provider
+	provider    *Provider
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	return &testEnv{
+		parser:      parser.NewParser(store.Statements),
+		diagnostics: diagnostics.NewProvider(store),
+		provider:    NewProvider(store),
+	}
+}
+
+// fixesFor analyzes text and returns the fixes for the first diagnostic with the given code
+func (e *testEnv) fixesFor(t *testing.T, text string, code string) []Fix {
+	t.Helper()
+	doc := e.parser.Parse(text)
+	for _, d := range e.diagnostics.AnalyzeASTWithConfigAndText(doc, nil, text) {
+		if d.Code == code {
+			return e.provider.Fixes(doc, text, d)
+		}
+	}
+	t.Fatalf("No %s diagnostic for:\n%s", code, text)
+	return nil
+}
+
+// applyFix applies the fix with the given title prefix
+func applyFix(t *testing.T, text string, fixes []Fix, title string) string {
+	t.Helper()
+	for _, f := range fixes {
+		if strings.HasPrefix(f.Title, title) {
+			return ApplyEdits(text, f.Edits)
+		}
+	}
+	t.Fatalf("No fix %q in %+v", title, fixes)
+	return ""
+}
+
+func TestFixMissingTerminator(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++PTF(UA12345) DESC('TEST')\n++VER(Z038) FMID(HBB7790).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeMissingTerminator)
+	got := applyFix(t, text, fixes, "Add terminator")
+
+	want := "++PTF(UA12345) DESC('TEST').\n++VER(Z038) FMID(HBB7790).\n"
+	if got != want {
+		t.Errorf("Got:\n%q\nWant:\n%q", got, want)
+	}
+	if !fixes[0].Preferred {
+		t.Error("Terminator fix should be preferred")
+	}
+}
+
+func TestFixMutuallyExclusive(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++MOD(MYMOD) DISTLIB(AOSFMID)\n  DALIAS(ALIAS1) TALIAS(ALIAS2) RELFILE(1).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeMutuallyExclusive)
+	got := applyFix(t, text, fixes, "Remove operand")
+
+	if strings.Contains(got, "DALIAS(") == strings.Contains(got, "TALIAS(") {
+		t.Errorf("Expected one of DALIAS/TALIAS to be removed, got:\n%s", got)
+	}
+	if strings.Contains(got, "  RELFILE") || strings.Contains(got, "( ") {
+		t.Errorf("Removal left stray blanks:\n%q", got)
+	}
+}
+
+func TestFixMissingRequiredOperand(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++MOD(MYMOD) RELFILE(1).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeMissingRequiredOperand)
+	got := applyFix(t, text, fixes, "Add operand DISTLIB")
+
+	if got != "++MOD(MYMOD) RELFILE(1) DISTLIB().\n" {
+		t.Errorf("Unexpected result: %q", got)
+	}
+
+	var snippet *lsp.TextEdit
+	for _, f := range fixes {
+		if f.Snippet != nil {
+			snippet = f.Snippet
+		}
+	}
+	if snippet == nil || snippet.NewText != " DISTLIB($1)" {
+		t.Errorf("Expected snippet with cursor placeholder, got %+v", snippet)
+	}
+}
+
+func TestFixesReadDiagnosticData(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++MOD(MYMOD) RELFILE(1).\n"
+	doc := env.parser.Parse(text)
+
+	for _, d := range env.diagnostics.AnalyzeASTWithConfigAndText(doc, nil, text) {
+		if d.Code != diagnostics.CodeMissingRequiredOperand {
+			continue
+		}
+		// The client returns the diagnostic as JSON; the message is not used
+		raw, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var returned lsp.Diagnostic
+		if err := json.Unmarshal(raw, &returned); err != nil {
+			t.Fatal(err)
+		}
+		returned.Message = "Reworded"
+		applyFix(t, text, env.provider.Fixes(doc, text, returned), "Add operand DISTLIB")
+		return
+	}
+	t.Fatal("No missing required operand diagnostic")
+}
+
+func TestFixDuplicateListOperandIsMerged(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++VER(Z038) FMID(HBB7790) PRE(UA00001,UA00002)\n  PRE(UA00002,UA00003).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeDuplicateOperand)
+	got := applyFix(t, text, fixes, "Merge duplicate operand PRE")
+
+	want := "++VER(Z038) FMID(HBB7790) PRE(UA00001,UA00002,UA00003)\n  .\n"
+	if got != want {
+		t.Errorf("Got:\n%q\nWant:\n%q", got, want)
+	}
+}
+
+func TestFixContentBeyondColumn72(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++VER(Z038) FMID(HBB7790) PRE(UA00001,UA00002,UA00003,UA00004,UA00005,UA00006).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeContentBeyondCol72)
+	got := applyFix(t, text, fixes, "Wrap line")
+
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > diagnostics.MaxColumn {
+			t.Errorf("Line still exceeds column 72: %q", line)
+		}
+	}
+	if strings.ReplaceAll(strings.ReplaceAll(got, "\n", ""), " ", "") != strings.ReplaceAll(strings.ReplaceAll(text, "\n", ""), " ", "") {
+		t.Errorf("Wrapping changed content:\n%s", got)
+	}
+}
+
+func TestFixStandaloneComment(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++PTF(UA12345).\n\n/* explains the VER */\n\n++VER(Z038) FMID(HBB7790).\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeStandaloneCommentBetweenMCS)
+	got := applyFix(t, text, fixes, "Convert to inline comment")
+
+	// Only the comment moves, the blank lines stay
+	want := "++PTF(UA12345). /* explains the VER */\n\n\n++VER(Z038) FMID(HBB7790).\n"
+	if got != want {
+		t.Errorf("Got:\n%q\nWant:\n%q", got, want)
+	}
+	if fixes[0].Preferred {
+		t.Error("Moving a comment needs review and should not be preferred")
+	}
+}
+
+func TestFixUnknownOperandSuggestion(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++PTF(UA12345) DESCRIPTON('TEST').\n"
+
+	fixes := env.fixesFor(t, text, diagnostics.CodeUnknownOperand)
+	got := applyFix(t, text, fixes, "Change to DESCRIPTION")
+
+	if got != "++PTF(UA12345) DESCRIPTION('TEST').\n" {
+		t.Errorf("Unexpected result: %q", got)
+	}
+}
+
+func TestFixAllConverges(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++PTF(UA12345)\n/* comment */\n++VER(Z038) FMID(HBB7790) PRE(UA00001)\n  PRE(UA00002)\n"
+
+	for pass := 0; pass < 5; pass++ {
+		doc := env.parser.Parse(text)
+		diags := env.diagnostics.AnalyzeASTWithConfigAndText(doc, nil, text)
+		var applied int
+		text, applied = env.provider.FixAll(doc, text, diags)
+		if applied == 0 {
+			break
+		}
+	}
+
+	// The standalone comment is left where it is
+	if !strings.Contains(text, "\n/* comment */\n") {
+		t.Errorf("FixAll moved the comment:\n%s", text)
+	}
+	doc := env.parser.Parse(text)
+	for _, d := range env.diagnostics.AnalyzeASTWithConfigAndText(doc, nil, text) {
+		if d.Severity == lsp.SeverityError && d.Code != diagnostics.CodeStandaloneCommentBetweenMCS {
+			t.Errorf("Remaining error after FixAll: %s\n%s", d.Message, text)
+		}
+	}
+}
+
+func TestCodeActionsUseSnippetCommand(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++MOD(MYMOD) RELFILE(1).\n"
+	doc := env.parser.Parse(text)
+	diags := env.diagnostics.AnalyzeASTWithConfigAndText(doc, nil, text)
+
+	for _, snippetCommand := range []bool{false, true} {
+		actions := env.provider.CodeActions("file:///a.smpe", doc, text, diags, snippetCommand)
+		if len(actions) == 0 {
+			t.Fatal("Expected code actions")
+		}
+		for _, a := range actions {
+			if a.Kind != lsp.CodeActionKindQuickFix {
+				t.Errorf("Unexpected kind %q", a.Kind)
+			}
+			if snippetCommand && a.Command != nil && a.Command.Command != InsertSnippetCommand {
+				t.Errorf("Unexpected command %q", a.Command.Command)
+			}
+			if !snippetCommand && a.Edit == nil {
+				t.Errorf("Expected workspace edit for %q", a.Title)
+			}
+		}
+	}
+}
+
+func TestNormalizeAliases(t *testing.T) {
+	env := newTestEnv(t)
+	text := "++PTF(UA12345) DESC('TEST').\n++VER(Z038) FMID(HBB7790).\n++MOD(MYMOD) DISTLIB(AMODLIB) LEPARM(AMOD(31) NCAL NOPACK).\n"
+
+	got, renamed := env.provider.NormalizeAliases(env.parser.Parse(text), text)
+	want := "++PTF(UA12345) DESCRIPTION('TEST').\n++VER(Z038) FMID(HBB7790).\n++MOD(MYMOD) DISTLIB(AMODLIB) LEPARM(AMODE(31) NOCALL NOPACK).\n"
+	if got != want || renamed != 3 {
+		t.Errorf("NormalizeAliases = %d, %q", renamed, got)
+	}
+}
@@ -0,0 +1,290 @@
+package codeactions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// source gives rune-based access to the lines of a document
+type source struct {
+	lines [][]rune
+}
+
+func newSource(text string) *source {
+	raw := strings.Split(text, "\n")
+	lines := make([][]rune, len(raw))
+	for i, line := range raw {
+		lines[i] = []rune(strings.TrimSuffix(line, "\r"))
+	}
+	return &source{lines: lines}
+}
+
+// line returns the runes of a line, or nil if out of range
+func (s *source) line(n int) []rune {
+	if n < 0 || n >= len(s.lines) {
+		return nil
+	}
+	return s.lines[n]
+}
+
+// contentEnd returns the column after the last non-blank character of a line
+func (s *source) contentEnd(n int) int {
+	line := s.line(n)
+	end := len(line)
+	for end > 0 && (line[end-1] == ' ' || line[end-1] == '\t') {
+		end--
+	}
+	return end
+}
+
+// indentation returns the leading whitespace of a line
+func (s *source) indentation(n int) string {
+	line := s.line(n)
+	i := 0
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return string(line[:i])
+}
+
+// statementExtent describes where a statement's content ends
+type statementExtent struct {
+	contentEnd lsp.Position  // position after the last significant character
+	terminator *lsp.Position // position of the '.' terminator, nil if missing
+}
+
+// scanStatement scans a statement starting at the given position, skipping comments
+// and quoted strings, until its terminator is found. Without a terminator the scan
+// stops at the next statement or at the first balanced line after lastLine.
+func (s *source) scanStatement(start lsp.Position, lastLine int) statementExtent {
+	ext := statementExtent{contentEnd: start}
+	depth := 0
+	inQuote := false
+	inComment := false
+
+	for ln := start.Line; ln < len(s.lines); ln++ {
+		line := s.lines[ln]
+		if ln > start.Line && !inComment && !inQuote {
+			trimmed := strings.TrimSpace(string(line))
+			if strings.HasPrefix(trimmed, "++") {
+				break
+			}
+			if ln > lastLine && depth <= 0 && trimmed != "" && !strings.HasPrefix(trimmed, "/*") && !strings.HasPrefix(trimmed, ".") {
+				break
+			}
+		}
+
+		col := 0
+		if ln == start.Line {
+			col = start.Character
+		}
+		for ; col < len(line); col++ {
+			ch := line[col]
+			switch {
+			case inComment:
+				if ch == '*' && col+1 < len(line) && line[col+1] == '/' {
+					inComment = false
+					col++
+				}
+			case inQuote:
+				if ch == '\'' {
+					if col+1 < len(line) && line[col+1] == '\'' {
+						col++
+					} else {
+						inQuote = false
+					}
+				}
+				ext.contentEnd = lsp.Position{Line: ln, Character: col + 1}
+			case ch == '/' && col+1 < len(line) && line[col+1] == '*':
+				inComment = true
+				col++
+			case ch == '\'':
+				inQuote = true
+				ext.contentEnd = lsp.Position{Line: ln, Character: col + 1}
+			case ch == '.' && depth <= 0:
+				ext.terminator = &lsp.Position{Line: ln, Character: col}
+				return ext
+			case ch == ' ' || ch == '\t':
+			default:
+				if ch == '(' {
+					depth++
+				} else if ch == ')' {
+					depth--
+				}
+				ext.contentEnd = lsp.Position{Line: ln, Character: col + 1}
+			}
+		}
+	}
+
+	return ext
+}
+
+// operandEnd returns the position after an operand starting at start,
+// including its parenthesized parameter (which may span several lines)
+func (s *source) operandEnd(start lsp.Position) lsp.Position {
+	line := s.line(start.Line)
+	col := start.Character
+	for col < len(line) && isNameChar(line[col]) {
+		col++
+	}
+	end := lsp.Position{Line: start.Line, Character: col}
+	if col >= len(line) || line[col] != '(' {
+		return end
+	}
+
+	depth := 0
+	inQuote := false
+	for ln := start.Line; ln < len(s.lines); ln++ {
+		line = s.lines[ln]
+		if ln > start.Line {
+			col = 0
+		}
+		for ; col < len(line); col++ {
+			ch := line[col]
+			if inQuote {
+				if ch == '\'' {
+					if col+1 < len(line) && line[col+1] == '\'' {
+						col++
+					} else {
+						inQuote = false
+					}
+				}
+				continue
+			}
+			switch ch {
+			case '\'':
+				inQuote = true
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return lsp.Position{Line: ln, Character: col + 1}
+				}
+			}
+		}
+	}
+
+	// Unbalanced - treat the name alone as the operand
+	return end
+}
+
+// removalRange widens the range of a construct so that removing it leaves no
+// stray blanks: whole lines are removed if nothing else is on them, otherwise
+// the preceding blanks on the same line are removed too
+func (s *source) removalRange(start, end lsp.Position) lsp.Range {
+	startLine := s.line(start.Line)
+	endLine := s.line(end.Line)
+
+	blankBefore := strings.TrimSpace(string(startLine[:start.Character])) == ""
+	blankAfter := end.Character >= len(endLine) || strings.TrimSpace(string(endLine[end.Character:])) == ""
+	if blankBefore && blankAfter && end.Line+1 < len(s.lines) {
+		return lsp.Range{
+			Start: lsp.Position{Line: start.Line, Character: 0},
+			End:   lsp.Position{Line: end.Line + 1, Character: 0},
+		}
+	}
+
+	col := start.Character
+	for col > 0 && (startLine[col-1] == ' ' || startLine[col-1] == '\t') {
+		col--
+	}
+	if col == 0 && !blankAfter {
+		// Operand starts the line - remove the blanks after it instead
+		col = start.Character
+		for end.Character < len(endLine) && (endLine[end.Character] == ' ' || endLine[end.Character] == '\t') {
+			end.Character++
+		}
+	}
+	return lsp.Range{Start: lsp.Position{Line: start.Line, Character: col}, End: end}
+}
+
+// text returns the text between two positions
+func (s *source) text(start, end lsp.Position) string {
+	if start.Line == end.Line {
+		line := s.line(start.Line)
+		if start.Character > len(line) || end.Character > len(line) || start.Character > end.Character {
+			return ""
+		}
+		return string(line[start.Character:end.Character])
+	}
+
+	var sb strings.Builder
+	sb.WriteString(string(s.line(start.Line)[start.Character:]))
+	for ln := start.Line + 1; ln < end.Line; ln++ {
+		sb.WriteString("\n")
+		sb.WriteString(string(s.line(ln)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(string(s.line(end.Line)[:end.Character]))
+	return sb.String()
+}
+
+// isNameChar reports whether ch can be part of a statement or operand name
+func isNameChar(ch rune) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+		ch == '+' || ch == '@' || ch == '#' || ch == '$'
+}
+
+// ApplyEdits applies non-overlapping text edits to text and returns the result
+func ApplyEdits(text string, edits []lsp.TextEdit) string {
+	if len(edits) == 0 {
+		return text
+	}
+
+	runes := []rune(text)
+
+	// Rune offset of each line start
+	lineStarts := []int{0}
+	for i, r := range runes {
+		if r == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	offset := func(pos lsp.Position) int {
+		if pos.Line >= len(lineStarts) {
+			return len(runes)
+		}
+		lineEnd := len(runes)
+		if pos.Line+1 < len(lineStarts) {
+			lineEnd = lineStarts[pos.Line+1] - 1
+		}
+		off := lineStarts[pos.Line] + pos.Character
+		if off > lineEnd {
+			off = lineEnd
+		}
+		return off
+	}
+
+	sorted := make([]lsp.TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return offset(sorted[i].Range.Start) > offset(sorted[j].Range.Start)
+	})
+
+	for _, e := range sorted {
+		start, end := offset(e.Range.Start), offset(e.Range.End)
+		if start > end {
+			continue
+		}
+		updated := make([]rune, 0, len(runes)-(end-start)+len(e.NewText))
+		updated = append(updated, runes[:start]...)
+		updated = append(updated, []rune(e.NewText)...)
+		updated = append(updated, runes[end:]...)
+		runes = updated
+	}
+
+	return string(runes)
+}
+
+// positionBefore reports whether a comes strictly before b
+func positionBefore(a, b lsp.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
+
+// rangesOverlap reports whether two ranges overlap or touch
+func rangesOverlap(a, b lsp.Range) bool {
+	return !positionBefore(a.End, b.Start) && !positionBefore(b.End, a.Start)
+}
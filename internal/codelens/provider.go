@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/references"
 	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
 )
 
@@ -16,8 +17,14 @@ func NewProvider() *Provider {
 	return &Provider{}
 }
 
-// GetCodeLenses returns CodeLens items for the given document
-func (p *Provider) GetCodeLenses(doc *parser.Document) []lsp.CodeLens {
+// ReferenceCounter counts the references to a SYSMOD ID or FMID across the workspace
+type ReferenceCounter interface {
+	Count(symbolType references.SymbolType, name string) int
+}
+
+// GetCodeLenses returns CodeLens items for the given document.
+// If counter is not nil, SYSMOD definitions also get a lens with their workspace reference count.
+func (p *Provider) GetCodeLenses(doc *parser.Document, counter ReferenceCounter) []lsp.CodeLens {
 	if doc == nil {
 		return nil
 	}
@@ -30,6 +37,13 @@ func (p *Provider) GetCodeLenses(doc *parser.Document) []lsp.CodeLens {
 			for _, child := range stmt.Children {
 				if child.Type == parser.NodeTypeParameter && child.Parent == stmt && child.Value != "" {
 					lenses = append(lenses, makeSysmodLens(child))
+					if counter != nil {
+						symbolType := references.SymbolTypeSYSMOD
+						if stmt.Name == "++FUNCTION" {
+							symbolType = references.SymbolTypeFMID
+						}
+						lenses = append(lenses, makeReferenceCountLens(child, counter.Count(symbolType, child.Value)))
+					}
 				}
 			}
 		}
@@ -136,6 +150,24 @@ func makeSysmodLens(node *parser.Node) lsp.CodeLens {
 	}
 }
 
+// makeReferenceCountLens creates an informational CodeLens showing how often a SYSMOD is referenced
+func makeReferenceCountLens(node *parser.Node, count int) lsp.CodeLens {
+	title := fmt.Sprintf("%d references", count)
+	if count == 1 {
+		title = "1 reference"
+	}
+	return lsp.CodeLens{
+		Range: lsp.Range{
+			Start: lsp.Position{Line: node.Position.Line, Character: node.Position.Character},
+			End:   lsp.Position{Line: node.Position.Line, Character: node.Position.Character + len(node.Value)},
+		},
+		Command: &lsp.Command{
+			Title:   title,
+			Command: "", // informational only
+		},
+	}
+}
+
 // makeSysmodListLens creates a single CodeLens for all SYSMODs in a list operand.
 // The SYSMOD IDs are passed as a string array; the filter is built by the extension.
 func makeSysmodListLens(operand *parser.Node, firstParam *parser.Node, refs []string) lsp.CodeLens {
@@ -12,7 +12,8 @@ import (
 
 // Provider provides code completion
 type Provider struct {
-	statements map[string]data.MCSStatement
+	statements    map[string]data.MCSStatement
+	targetRelease string
 }
 
 // NewProvider creates a new completion provider with shared data
@@ -22,6 +23,12 @@ func NewProvider(store *data.Store) *Provider {
 	}
 }
 
+// SetTargetRelease sets the SMP/E release (e.g. V3R6) whose statements, operands and
+// values are offered. An empty release offers all of them.
+func (p *Provider) SetTargetRelease(release string) {
+	p.targetRelease = release
+}
+
 // GetCompletionsAST returns completion items using the AST
 func (p *Provider) GetCompletionsAST(doc *parser.Document, text string, line, character int) []lsp.CompletionItem {
 	// Convert line/character to absolute position
@@ -465,6 +472,11 @@ func (p *Provider) getOperandCompletionsAST(stmt *parser.Node, text string, line
 			continue
 		}
 
+		// Skip operands that do not exist in the target release
+		if !op.InRelease(p.targetRelease) {
+			continue
+		}
+
 		// Check mutually_exclusive
 		if op.MutuallyExclusive != "" {
 			exclusiveOps := strings.Split(op.MutuallyExclusive, "|")
@@ -558,6 +570,9 @@ func (p *Provider) getOperandValueCompletionsAST(operandNode *parser.Node, fullT
 		// These are sub-operands (e.g., DSN, NUMBER for FROMDS)
 		var items []lsp.CompletionItem
 		for _, subOp := range operandNode.OperandDef.Values {
+			if !subOp.InRelease(p.targetRelease) {
+				continue
+			}
 			// Handle aliases (e.g., "AMODE|AMOD" -> ["AMODE", "AMOD"])
 			names := strings.Split(subOp.Name, "|")
 			primaryName := strings.TrimSpace(names[0])
@@ -608,6 +623,9 @@ func (p *Provider) getOperandValueCompletionsAST(operandNode *parser.Node, fullT
 	if len(operandNode.OperandDef.Values) > 0 {
 		var items []lsp.CompletionItem
 		for _, value := range operandNode.OperandDef.Values {
+			if !value.InRelease(p.targetRelease) {
+				continue
+			}
 			// Handle aliases (e.g., "AMODE|AMOD" -> ["AMODE", "AMOD"])
 			names := strings.Split(value.Name, "|")
 			primaryName := strings.TrimSpace(names[0])
@@ -737,6 +755,9 @@ func (p *Provider) getMCSCompletions(replaceRange *lsp.Range) []lsp.CompletionIt
 			logger.Debug("getMCSCompletions: Statement %s NOT found in p.statements", name)
 			continue
 		}
+		if !stmt.InRelease(p.targetRelease) {
+			continue
+		}
 
 		// If this statement supports language variants, add both the base statement AND the variants
 		logger.Debug("getMCSCompletions: Processing %s, LanguageVariants=%v", name, stmt.LanguageVariants)
@@ -760,3 +760,51 @@ func TestCompletionTextEditRange(t *testing.T) {
 		}
 	}
 }
+
+// Test: Completion hides definitions not available in the target release
+func TestCompletionTargetRelease(t *testing.T) {
+	store, _, _ := createTestProviders()
+	ver := store.Statements["++VER"]
+	ver.Since = "V3R7"
+	store.Statements["++VER"] = ver
+	usermod := store.Statements["++USERMOD"]
+	usermod.Operands = append([]data.Operand(nil), usermod.Operands...)
+	usermod.Operands[1].Since = "V3R7"
+	store.Statements["++USERMOD"] = usermod
+	mac := store.Statements["++MAC"]
+	mac.Operands = append([]data.Operand(nil), mac.Operands...)
+	mac.Operands[1].Values = append([]data.AllowedValue(nil), mac.Operands[1].Values...)
+	mac.Operands[1].Values[1].Until = "V3R5"
+	store.Statements["++MAC"] = mac
+	p := parser.NewParser(store.Statements)
+	cp := NewProvider(store)
+
+	labels := func(text string, character int) map[string]bool {
+		found := make(map[string]bool)
+		for _, item := range cp.GetCompletionsAST(p.Parse(text), text, 0, character) {
+			found[item.Label] = true
+		}
+		return found
+	}
+
+	// Without a target release everything is offered
+	if found := labels("+", 1); !found["++VER"] || !found["++USERMOD"] {
+		t.Errorf("Expected ++VER and ++USERMOD without target release, got %v", found)
+	}
+
+	cp.SetTargetRelease("V3R6")
+	if found := labels("+", 1); found["++VER"] || !found["++USERMOD"] {
+		t.Errorf("Expected ++USERMOD but not ++VER in V3R6, got %v", found)
+	}
+	if found := labels("++USERMOD(LJS2012) ", 19); found["DESC"] || !found["REWORK"] {
+		t.Errorf("Expected REWORK but not DESC in V3R6, got %v", found)
+	}
+	if found := labels("++MAC(MYMAC) FROMDS(", 20); found["VOL"] || !found["DSN"] {
+		t.Errorf("Expected DSN but not VOL in V3R6, got %v", found)
+	}
+
+	cp.SetTargetRelease("V3R7")
+	if found := labels("+", 1); !found["++VER"] {
+		t.Errorf("Expected ++VER in V3R7, got %v", found)
+	}
+}
@@ -3,7 +3,6 @@ package data
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 )
 
 // MCSStatement represents a MCS statement definition from smpe.json
@@ -16,6 +15,36 @@ type MCSStatement struct {
 	Type             string    `json:"type"`
 	InlineData       bool      `json:"inline_data,omitempty"`
 	Operands         []Operand `json:"operands,omitempty"`
+
+	// Pattern is a regular expression the whole statement parameter must match,
+	// PatternDescription explains the expected format
+	Pattern            string `json:"pattern,omitempty"`
+	PatternDescription string `json:"pattern_description,omitempty"`
+
+	// ReservedPattern matches parameter values that are valid but reserved (e.g. SYSMOD ID
+	// prefixes IBM uses for its own SYSMODs), ReservedDescription explains the reservation
+	ReservedPattern     string `json:"reserved_pattern,omitempty"`
+	ReservedDescription string `json:"reserved_description,omitempty"`
+
+	// Modes define operand requirements that depend on the form of the statement,
+	// e.g. ADD/REPLACE vs DELETE
+	Modes []Mode `json:"modes,omitempty"`
+
+	// Since and Until are the first and last SMP/E release (e.g. V3R6) supporting the statement
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+
+	Layer string `json:"-"` // Overlay that added or changed the statement, empty for the base data
+}
+
+// Mode represents a form of a statement with its own required operands. A mode is active
+// when one of its When operands is specified; modes without When are active when no other
+// mode is.
+type Mode struct {
+	Name          string     `json:"name"`
+	When          string     `json:"when,omitempty"`            // Operands selecting the mode, separated by |
+	Required      []string   `json:"required,omitempty"`        // Operands required in this mode
+	RequiredOneOf [][]string `json:"required_one_of,omitempty"` // Groups of operands of which one is required
 }
 
 // Operand represents an operand definition
@@ -31,6 +60,22 @@ type Operand struct {
 	Values            []AllowedValue `json:"values,omitempty"`
 	MutuallyExclusive string         `json:"mutually_exclusive,omitempty"`
 	AllowedIf         string         `json:"allowed_if,omitempty"`
+
+	// RequiredIf makes the operand required when one of the given operands (separated by |)
+	// is specified, RequiredUnless when none of them is
+	RequiredIf     string `json:"required_if,omitempty"`
+	RequiredUnless string `json:"required_unless,omitempty"`
+
+	// Pattern is a regular expression every parameter value (each list item) must match,
+	// PatternDescription explains the expected format
+	Pattern            string `json:"pattern,omitempty"`
+	PatternDescription string `json:"pattern_description,omitempty"`
+
+	// Since and Until are the first and last SMP/E release supporting the operand
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+
+	Layer string `json:"-"` // Overlay that added or changed the operand, empty for the base data
 }
 
 // AllowedValue represents an allowed value for an operand
@@ -41,12 +86,15 @@ type AllowedValue struct {
 	Parameter   string `json:"parameter,omitempty"`   // Parameter syntax (e.g., "24|31|64" for AMODE)
 	Type        string `json:"type,omitempty"`        // Type constraint (string, integer, etc.) for sub-operands
 	Length      int    `json:"length,omitempty"`      // Maximum length constraint for sub-operands
+	Since       string `json:"since,omitempty"`       // First SMP/E release supporting the value
+	Until       string `json:"until,omitempty"`       // Last SMP/E release supporting the value
 }
 
 // Store holds the shared MCS statement data
 type Store struct {
 	Statements map[string]MCSStatement
 	List       []MCSStatement
+	Layers     []string // Names of the overlays merged into the base data, in order
 }
 
 // --- private types for new-format parsing ---
@@ -69,6 +117,16 @@ type mcsStatementRaw struct {
 	Type             string            `json:"type"`
 	InlineData       bool              `json:"inline_data,omitempty"`
 	OperandsRaw      []json.RawMessage `json:"operands,omitempty"`
+
+	Pattern             string `json:"pattern,omitempty"`
+	PatternDescription  string `json:"pattern_description,omitempty"`
+	ReservedPattern     string `json:"reserved_pattern,omitempty"`
+	ReservedDescription string `json:"reserved_description,omitempty"`
+
+	Modes []Mode `json:"modes,omitempty"`
+
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
 }
 
 // refEntry is used to detect {"$ref": "template_name"} entries.
@@ -78,28 +136,34 @@ type refEntry struct {
 
 // Load reads and parses the smpe.json file, resolving any $ref template
 // references at load time. Supports both the new object format and the
-// legacy plain-array format for backwards compatibility.
+// legacy plain-array format for backwards compatibility. An empty path
+// loads the smpe.json embedded in the binary.
 func Load(dataPath string) (*Store, error) {
-	fileBytes, err := os.ReadFile(dataPath)
-	if err != nil {
-		return nil, err
+	return LoadLayers(dataPath, nil)
+}
+
+// decode decodes the statements of a data file in either format
+func decode(fileBytes []byte) ([]MCSStatement, error) {
+	if isNewFormat(fileBytes) {
+		return decodeNewFormat(fileBytes)
 	}
+	return decodeLegacyFormat(fileBytes)
+}
 
-	// Detect format by first non-whitespace character:
-	// '{' => new format  |  '[' => legacy format
+// isNewFormat detects the format by the first non-whitespace character:
+// '{' => new format  |  '[' => legacy format
+func isNewFormat(fileBytes []byte) bool {
 	for _, b := range fileBytes {
 		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
 			continue
 		}
-		if b == '{' {
-			return loadNewFormat(fileBytes)
-		}
-		break
+		return b == '{'
 	}
-	return loadLegacyFormat(fileBytes)
+	return false
 }
 
-func loadNewFormat(fileBytes []byte) (*Store, error) {
+// decodeNewFormat decodes the statements of the new format and resolves their $ref entries
+func decodeNewFormat(fileBytes []byte) ([]MCSStatement, error) {
 	var wrapper smpeFileNew
 	if err := json.Unmarshal(fileBytes, &wrapper); err != nil {
 		return nil, fmt.Errorf("parsing smpe.json (new format): %w", err)
@@ -115,6 +179,16 @@ func loadNewFormat(fileBytes []byte) (*Store, error) {
 			Length:           raw.Length,
 			Type:             raw.Type,
 			InlineData:       raw.InlineData,
+
+			Pattern:             raw.Pattern,
+			PatternDescription:  raw.PatternDescription,
+			ReservedPattern:     raw.ReservedPattern,
+			ReservedDescription: raw.ReservedDescription,
+
+			Modes: raw.Modes,
+
+			Since: raw.Since,
+			Until: raw.Until,
 		}
 
 		resolved, err := resolveOperands(raw.OperandsRaw, wrapper.Templates)
@@ -125,15 +199,16 @@ func loadNewFormat(fileBytes []byte) (*Store, error) {
 		statements = append(statements, stmt)
 	}
 
-	return buildStore(statements), nil
+	return statements, nil
 }
 
-func loadLegacyFormat(fileBytes []byte) (*Store, error) {
+// decodeLegacyFormat decodes the statements of the legacy plain-array format
+func decodeLegacyFormat(fileBytes []byte) ([]MCSStatement, error) {
 	var statements []MCSStatement
 	if err := json.Unmarshal(fileBytes, &statements); err != nil {
 		return nil, fmt.Errorf("parsing smpe.json: %w", err)
 	}
-	return buildStore(statements), nil
+	return statements, nil
 }
 
 // resolveOperands processes a slice of raw JSON operand entries.
@@ -171,13 +246,22 @@ func resolveOperands(raw []json.RawMessage, templates map[string][]Operand) ([]O
 	return operands, nil
 }
 
-func buildStore(statements []MCSStatement) *Store {
+func buildStore(statements []MCSStatement) (*Store, error) {
 	stmtMap := make(map[string]MCSStatement, len(statements))
 	for _, stmt := range statements {
+		if err := validatePatterns(stmt); err != nil {
+			return nil, fmt.Errorf("statement %q: %w", stmt.Name, err)
+		}
+		if err := validateRules(stmt); err != nil {
+			return nil, fmt.Errorf("statement %q: %w", stmt.Name, err)
+		}
+		if err := validateReleases(stmt); err != nil {
+			return nil, fmt.Errorf("statement %q: %w", stmt.Name, err)
+		}
 		stmtMap[stmt.Name] = stmt
 	}
 	return &Store{
 		Statements: stmtMap,
 		List:       statements,
-	}
+	}, nil
 }
@@ -1,8 +1,11 @@
 package data
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -259,3 +262,365 @@ func TestLoadNewFormatUnknownRefReturnsError(t *testing.T) {
 		t.Error("Expected error for unknown $ref, got nil")
 	}
 }
+
+func TestLoadInvalidPattern(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "smpe_pattern.json")
+	content := `{
+		"statements": [
+			{
+				"name": "++TESTSTMT",
+				"type": "SYSMOD",
+				"description": "Test statement",
+				"operands": [{"name": "PRE", "pattern": "[A-Z"}]
+			}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid operand pattern, got nil")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	pattern := "[A-Z0-9$@#]{7}"
+	for value, want := range map[string]bool{"UA12345": true, "LU$0001": true, "UA1234": false, "UA123456": false, "ua12345": false} {
+		if got := MatchPattern(pattern, value); got != want {
+			t.Errorf("MatchPattern(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if !MatchPattern("", "anything") {
+		t.Error("Empty pattern must match every value")
+	}
+}
+
+func TestInRelease(t *testing.T) {
+	if CompareReleases("V3R6", "V3R10") >= 0 || CompareReleases("v3r6", "V3R6") != 0 || CompareReleases("V4R1", "V3R9") <= 0 {
+		t.Error("Releases must compare by version and release number")
+	}
+	for _, tc := range []struct {
+		since, until, target string
+		want                 bool
+	}{
+		{"", "", "V3R6", true},
+		{"V3R7", "", "V3R6", false},
+		{"V3R7", "", "V3R7", true},
+		{"", "V3R5", "V3R6", false},
+		{"", "V3R6", "V3R6", true},
+		{"V3R5", "V3R6", "V3R6", true},
+		{"V3R7", "", "", true},
+		{"V3R7", "", "latest", true},
+	} {
+		if got := InRelease(tc.since, tc.until, tc.target); got != tc.want {
+			t.Errorf("InRelease(%q, %q, %q) = %v, want %v", tc.since, tc.until, tc.target, got, tc.want)
+		}
+	}
+}
+
+func TestLoadInvalidRelease(t *testing.T) {
+	for _, operand := range []string{
+		`{"name": "PRE", "since": "3.6"}`,
+		`{"name": "PRE", "since": "V3R7", "until": "V3R6"}`,
+		`{"name": "PRE", "values": [{"name": "X", "until": "R6"}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "smpe_release.json")
+		content := `{"statements": [{"name": "++TESTSTMT", "type": "SYSMOD", "operands": [` + operand + `]}]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write temp: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected error for %s, got nil", operand)
+		}
+	}
+}
+
+func TestLoadRulesReferenceUnknownOperand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "smpe_rules.json")
+	content := `{
+		"statements": [
+			{
+				"name": "++TESTSTMT",
+				"type": "MCS",
+				"description": "Test statement",
+				"operands": [{"name": "DISTLIB"}, {"name": "DELETE"}],
+				"modes": [
+					{"name": "ADD", "required": ["DISTLIB"]},
+					{"name": "DELETE", "when": "DELET"}
+				]
+			}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for mode selected by unknown operand, got nil")
+	}
+}
+
+func TestLoadModes(t *testing.T) {
+	store, err := Load(smpeJSONPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	mod := store.Statements["++MOD"]
+	if len(mod.Modes) != 2 || mod.Modes[0].Required[0] != "DISTLIB" || mod.Modes[1].When != "DELETE" {
+		t.Errorf("Expected ADD/REPLACE and DELETE modes for ++MOD, got %+v", mod.Modes)
+	}
+	if op, ok := store.Statements["++MOVE"].Operand("TODISTLIB"); !ok || op.RequiredIf != "DISTLIB" {
+		t.Errorf("Expected TODISTLIB to be required with DISTLIB, got %+v", op)
+	}
+}
+
+func TestValidateRealData(t *testing.T) {
+	problems, err := Validate(smpeJSONPath)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	for _, problem := range problems {
+		t.Errorf("Unexpected problem: %s", problem)
+	}
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "smpe_problems.json")
+	content := `{
+		"templates": {
+			"unused_operands": [{"name": "DISTLIB"}]
+		},
+		"statements": [
+			{
+				"name": "++TESTSTMT",
+				"type": "MCS",
+				"description": "Test statement",
+				"operands": [
+					{"name": "DELETE", "mutualy_exclusive": "FROMDS"},
+					{"name": "FROMDS", "allowed_if": "FROMLIB"},
+					{"name": "DESCRIPTION|DESC", "required": true, "required_group": true, "required_group_id": "desc"},
+					{"name": "DESC", "values": [{"name": "X", "descripton": "typo"}]}
+				]
+			}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	problems, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expected := []string{
+		`statements[++TESTSTMT].operands[DELETE]: unknown field "mutualy_exclusive"`,
+		`statements[++TESTSTMT].operands[DESC].values[X]: unknown field "descripton"`,
+		`templates[unused_operands]: template is not used`,
+		`statements[++TESTSTMT].operands[FROMDS]: allowed_if: unknown operand "FROMLIB"`,
+		`statements[++TESTSTMT].operands[DESC]: name "DESC" collides with operand "DESCRIPTION|DESC"`,
+		`statements[++TESTSTMT]: required group "desc" has only one member (DESCRIPTION|DESC)`,
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		if got := problems[i].String(); got != want {
+			t.Errorf("Problem %d: got %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestSchemaMatchesDataTypes(t *testing.T) {
+	schemaBytes, err := os.ReadFile("../../data/smpe.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	for def, known := range map[string]map[string]bool{
+		"statement": knownStatementFields,
+		"operand":   jsonFields(reflect.TypeOf(Operand{})),
+		"value":     knownValueFields,
+		"mode":      knownModeFields,
+	} {
+		props := schema.Defs[def].Properties
+		if len(props) != len(known) {
+			t.Errorf("Schema %s has %d properties, data types have %d", def, len(props), len(known))
+		}
+		for name := range props {
+			if !known[name] {
+				t.Errorf("Schema %s property %q is not a known field", def, name)
+			}
+		}
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	store, err := Load("")
+	if err != nil {
+		t.Fatalf("Load embedded failed: %v", err)
+	}
+	fromFile, err := Load(smpeJSONPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(store.List) != len(fromFile.List) {
+		t.Errorf("Embedded copy has %d statements, data/smpe.json %d", len(store.List), len(fromFile.List))
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("LOCALAPPDATA", filepath.Join(home, "AppData", "Local"))
+
+	if got := ResolvePath("custom.json"); got != "custom.json" {
+		t.Errorf("Expected explicit path, got %q", got)
+	}
+	if got := ResolvePath(""); got != "" {
+		t.Errorf("Expected built-in copy without installed file, got %q", got)
+	}
+
+	installed := DefaultPath()
+	if err := os.MkdirAll(filepath.Dir(installed), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := DumpEmbedded(installed); err != nil {
+		t.Fatalf("DumpEmbedded failed: %v", err)
+	}
+	if got := ResolvePath(""); got != installed {
+		t.Errorf("Expected installed file %q, got %q", installed, got)
+	}
+	if _, err := Load(installed); err != nil {
+		t.Errorf("Dumped copy does not load: %v", err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "smpe.json")
+	baseContent := `{
+		"templates": {
+			"lib_operands": [
+				{"name": "DISTLIB", "type": "string", "length": 8, "description": "Distribution library"},
+				{"name": "VERSION|VER", "type": "string", "description": "Version",
+				 "values": [{"name": "V1", "description": "Version 1"}]}
+			]
+		},
+		"statements": [
+			{"name": "++MAC", "type": "data", "description": "Macro", "operands": [{"$ref": "lib_operands"}]},
+			{"name": "++SRC", "type": "data", "description": "Source", "operands": [{"$ref": "lib_operands"}]}
+		]
+	}`
+	overlayPath := filepath.Join(dir, "overlay.json")
+	overlayContent := `{
+		"statements": [
+			{"name": "++MAC", "description": "Site macro", "operands": [
+				{"name": "VER", "description": "Site version", "values": [{"name": "V2", "description": "Version 2"}]},
+				{"name": "SITEOPT", "type": "boolean", "description": "Site option"}
+			]},
+			{"name": "++SITE", "type": "control", "description": "Site statement",
+			 "operands": [{"name": "OWNER", "type": "string"}]}
+		]
+	}`
+	if err := os.WriteFile(base, []byte(baseContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlayPath, []byte(overlayContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadLayers(base, []Layer{{Name: "site", Path: overlayPath}})
+	if err != nil {
+		t.Fatalf("LoadLayers failed: %v", err)
+	}
+	if !reflect.DeepEqual(store.Layers, []string{"site"}) {
+		t.Errorf("Expected layers [site], got %v", store.Layers)
+	}
+
+	mac := store.Statements["++MAC"]
+	if mac.Description != "Site macro" || mac.Type != "data" || mac.Layer != "site" {
+		t.Errorf("Expected patched ++MAC description, got %+v", mac)
+	}
+	if len(mac.Operands) != 3 {
+		t.Fatalf("Expected DISTLIB, VERSION and SITEOPT, got %+v", mac.Operands)
+	}
+	if mac.Operands[0].Layer != "" || mac.Operands[0].Length != 8 {
+		t.Errorf("Expected DISTLIB from the base, got %+v", mac.Operands[0])
+	}
+	version := mac.Operands[1]
+	if version.Name != "VERSION|VER" || version.Description != "Site version" || version.Type != "string" ||
+		version.Layer != "site" || len(version.Values) != 1 || version.Values[0].Name != "V2" {
+		t.Errorf("Expected VERSION overridden by alias, got %+v", version)
+	}
+	if mac.Operands[2].Name != "SITEOPT" || mac.Operands[2].Layer != "site" {
+		t.Errorf("Expected added SITEOPT, got %+v", mac.Operands[2])
+	}
+
+	// The template shared with ++SRC is unchanged
+	src := store.Statements["++SRC"]
+	if src.Layer != "" || src.Operands[1].Description != "Version" || src.Operands[1].Values[0].Name != "V1" {
+		t.Errorf("Expected ++SRC unchanged, got %+v", src)
+	}
+
+	site, ok := store.Statements["++SITE"]
+	if !ok || site.Layer != "site" || len(site.Operands) != 1 || site.Operands[0].Layer != "site" {
+		t.Errorf("Expected added ++SITE, got %+v", site)
+	}
+	if store.List[len(store.List)-1].Name != "++SITE" {
+		t.Errorf("Expected ++SITE appended to the list")
+	}
+
+	// Overlay errors name the layer
+	if err := os.WriteFile(overlayPath, []byte(`{"statements": [{"description": "x"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLayers(base, []Layer{{Name: "site", Path: overlayPath}}); err == nil || !strings.Contains(err.Error(), "overlay site") {
+		t.Errorf("Expected overlay error, got %v", err)
+	}
+}
+
+func TestValidateLayer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overlay.json")
+	content := `{
+		"templates": {"unused": [{"name": "X"}]},
+		"statements": [
+			{"name": "++MOD", "operands": [{"name": "DISTLIB", "mutually_exclusive": "SYSLIB", "requird": true}]}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+
+	problems, err := ValidateLayer(Layer{Name: "overlay.json", Path: path})
+	if err != nil {
+		t.Fatalf("ValidateLayer failed: %v", err)
+	}
+	// SYSLIB is defined by the base file and not reported
+	want := []string{
+		`statements[++MOD].operands[DISTLIB]: unknown field "requird"`,
+		"templates[unused]: template is not used",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, problems)
+	}
+	for i := range want {
+		if problems[i].String() != want[i] {
+			t.Errorf("Problem %d = %q, want %q", i, problems[i], want[i])
+		}
+	}
+}
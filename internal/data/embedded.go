@@ -0,0 +1,67 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	smpedata "github.com/cybersorcerer/smpe_ls/data"
+)
+
+// Embedded returns the smpe.json compiled into the binary
+func Embedded() []byte {
+	return smpedata.SMPEJSON
+}
+
+// DefaultPath returns the platform-specific location of an installed smpe.json
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	// Platform-specific data directory
+	if runtime.GOOS == "windows" {
+		// Windows: %LOCALAPPDATA%\smpe_ls\smpe.json
+		localAppData := os.Getenv("LOCALAPPDATA")
+		if localAppData != "" {
+			return filepath.Join(localAppData, "smpe_ls", "smpe.json")
+		}
+		// Fallback if LOCALAPPDATA not set
+		return filepath.Join(homeDir, "AppData", "Local", "smpe_ls", "smpe.json")
+	}
+
+	// Linux/macOS: ~/.local/share/smpe_ls/smpe.json
+	return filepath.Join(homeDir, ".local", "share", "smpe_ls", "smpe.json")
+}
+
+// ResolvePath returns the data file to load: the given path if set, otherwise the
+// installed smpe.json at DefaultPath if it exists, otherwise "" for the embedded copy
+func ResolvePath(dataPath string) string {
+	if dataPath != "" {
+		return dataPath
+	}
+	if path := DefaultPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// DumpEmbedded writes the embedded smpe.json to path, or to stdout if path is "-"
+func DumpEmbedded(path string) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(smpedata.SMPEJSON)
+		return err
+	}
+	return os.WriteFile(path, smpedata.SMPEJSON, 0o644)
+}
+
+// readData reads a data file, or returns the embedded smpe.json for an empty path
+func readData(dataPath string) ([]byte, error) {
+	if dataPath == "" {
+		return smpedata.SMPEJSON, nil
+	}
+	return os.ReadFile(dataPath)
+}
@@ -0,0 +1,170 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OverlayFile is the workspace-relative path of the overlay loaded automatically
+const OverlayFile = ".smpe_ls/overlay.json"
+
+// Layer is an overlay data file merged on top of the base smpe.json
+type Layer struct {
+	Name string // Shown in hover, e.g. the workspace-relative path
+	Path string
+}
+
+// overlayFile is the structure of an overlay: the new smpe.json format, where statements
+// are kept raw so only the fields present in the overlay are merged
+type overlayFile struct {
+	Templates  map[string][]Operand `json:"templates"`
+	Statements []json.RawMessage    `json:"statements"`
+}
+
+// LoadLayers loads the base data file (the embedded smpe.json if base is empty) and merges
+// the overlays in order. An overlay can add statements, add operands and override any
+// field of existing statements and operands (matched by name or alias), e.g. to patch
+// descriptions. Statements and operands added or changed by an overlay record its name
+// in their Layer field.
+func LoadLayers(base string, overlays []Layer) (*Store, error) {
+	fileBytes, err := readData(base)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := decode(fileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range overlays {
+		overlayBytes, err := os.ReadFile(layer.Path)
+		if err != nil {
+			return nil, err
+		}
+		statements, err = mergeOverlay(statements, overlayBytes, layer.Name)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", layer.Name, err)
+		}
+	}
+
+	store, err := buildStore(statements)
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range overlays {
+		store.Layers = append(store.Layers, layer.Name)
+	}
+	return store, nil
+}
+
+// mergeOverlay merges the statements of an overlay into statements
+func mergeOverlay(statements []MCSStatement, overlayBytes []byte, layer string) ([]MCSStatement, error) {
+	var overlay overlayFile
+	if err := json.Unmarshal(overlayBytes, &overlay); err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int, len(statements))
+	for i, stmt := range statements {
+		positions[stmt.Name] = i
+	}
+
+	for _, raw := range overlay.Statements {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+		var name string
+		if err := json.Unmarshal(fields["name"], &name); err != nil || name == "" {
+			return nil, fmt.Errorf("statement without name")
+		}
+
+		var operandsRaw []json.RawMessage
+		if ops, ok := fields["operands"]; ok {
+			if err := json.Unmarshal(ops, &operandsRaw); err != nil {
+				return nil, fmt.Errorf("statement %q: %w", name, err)
+			}
+			delete(fields, "operands")
+		}
+		operands, err := resolveOperands(operandsRaw, overlay.Templates)
+		if err != nil {
+			return nil, fmt.Errorf("statement %q: %w", name, err)
+		}
+
+		pos, exists := positions[name]
+		if !exists {
+			var stmt MCSStatement
+			if err := json.Unmarshal(raw, &stmt); err != nil {
+				return nil, fmt.Errorf("statement %q: %w", name, err)
+			}
+			stmt.Operands = operands
+			stmt.Layer = layer
+			for i := range stmt.Operands {
+				stmt.Operands[i].Layer = layer
+			}
+			positions[name] = len(statements)
+			statements = append(statements, stmt)
+			continue
+		}
+
+		stmt := statements[pos]
+		stmt.Operands = append([]Operand(nil), stmt.Operands...)
+		delete(fields, "name")
+		if len(fields) > 0 {
+			patch, _ := json.Marshal(fields)
+			if err := json.Unmarshal(patch, &stmt); err != nil {
+				return nil, fmt.Errorf("statement %q: %w", name, err)
+			}
+			stmt.Layer = layer
+		}
+		fromTemplate := isTemplateRef(operandsRaw)
+		for i, op := range operands {
+			target := findOperand(stmt.Operands, op.Name)
+			if target < 0 {
+				op.Layer = layer
+				stmt.Operands = append(stmt.Operands, op)
+				continue
+			}
+			// Only the fields present in the overlay override the existing definition
+			patch := []byte(operandsRaw[i])
+			if fromTemplate {
+				patch, _ = json.Marshal(op)
+			}
+			merged := stmt.Operands[target]
+			merged.Values = append([]AllowedValue(nil), merged.Values...) // may be shared through a template
+			if err := json.Unmarshal(patch, &merged); err != nil {
+				return nil, fmt.Errorf("statement %q operand %q: %w", name, op.Name, err)
+			}
+			merged.Name = stmt.Operands[target].Name // keep the aliases
+			merged.Layer = layer
+			stmt.Operands[target] = merged
+		}
+		statements[pos] = stmt
+	}
+	return statements, nil
+}
+
+// isTemplateRef checks if raw operands are a single {"$ref": "name"} entry
+func isTemplateRef(raw []json.RawMessage) bool {
+	if len(raw) != 1 {
+		return false
+	}
+	var ref refEntry
+	return json.Unmarshal(raw[0], &ref) == nil && ref.Ref != ""
+}
+
+// findOperand returns the index of the operand sharing a name or alias with name, or -1
+func findOperand(operands []Operand, name string) int {
+	for i, op := range operands {
+		for _, alias := range strings.Split(op.Name, "|") {
+			for _, other := range strings.Split(name, "|") {
+				if alias == other {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// patterns caches compiled patterns by their source
+var patterns sync.Map // string -> *regexp.Regexp
+
+// compilePattern compiles a pattern anchored to the whole value
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// MatchPattern reports whether the whole value matches pattern.
+// An empty pattern matches everything; an invalid pattern matches nothing.
+func MatchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// validatePatterns checks that the patterns of a statement and its operands compile
+func validatePatterns(stmt MCSStatement) error {
+	for _, pattern := range []string{stmt.Pattern, stmt.ReservedPattern} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, op := range stmt.Operands {
+		if op.Pattern == "" {
+			continue
+		}
+		if _, err := compilePattern(op.Pattern); err != nil {
+			return fmt.Errorf("operand %q: invalid pattern %q: %w", op.Name, op.Pattern, err)
+		}
+	}
+	return nil
+}
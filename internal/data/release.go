@@ -0,0 +1,132 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// releasePattern matches an SMP/E release such as V3R6
+var releasePattern = regexp.MustCompile(`^[Vv](\d+)[Rr](\d+)$`)
+
+// ParseRelease parses an SMP/E release such as V3R6 into its version and release numbers
+func ParseRelease(release string) (version, rel int, ok bool) {
+	m := releasePattern.FindStringSubmatch(strings.TrimSpace(release))
+	if m == nil {
+		return 0, 0, false
+	}
+	version, _ = strconv.Atoi(m[1])
+	rel, _ = strconv.Atoi(m[2])
+	return version, rel, true
+}
+
+// CompareReleases compares two SMP/E releases, returning -1, 0 or 1.
+// Releases that cannot be parsed sort before all others.
+func CompareReleases(a, b string) int {
+	av, ar, aok := ParseRelease(a)
+	bv, br, bok := ParseRelease(b)
+	switch {
+	case !aok || !bok:
+		return compareBool(aok, bok)
+	case av != bv:
+		return compareInt(av, bv)
+	default:
+		return compareInt(ar, br)
+	}
+}
+
+// InRelease reports whether a definition available from since up to and including until
+// exists in the target release. Empty bounds and an empty or invalid target are unrestricted.
+func InRelease(since, until, target string) bool {
+	if _, _, ok := ParseRelease(target); !ok {
+		return true
+	}
+	if since != "" && CompareReleases(target, since) < 0 {
+		return false
+	}
+	if until != "" && CompareReleases(target, until) > 0 {
+		return false
+	}
+	return true
+}
+
+// Availability describes the releases of a definition, e.g. "since V3R7" or "V3R5 to V3R6".
+// It is empty if the definition has no release bounds.
+func Availability(since, until string) string {
+	switch {
+	case since != "" && until != "":
+		return since + " to " + until
+	case since != "":
+		return "since " + since
+	case until != "":
+		return "until " + until
+	}
+	return ""
+}
+
+// InRelease reports whether the statement exists in the target release
+func (stmt MCSStatement) InRelease(target string) bool {
+	return InRelease(stmt.Since, stmt.Until, target)
+}
+
+// InRelease reports whether the operand exists in the target release
+func (op Operand) InRelease(target string) bool {
+	return InRelease(op.Since, op.Until, target)
+}
+
+// InRelease reports whether the value exists in the target release
+func (v AllowedValue) InRelease(target string) bool {
+	return InRelease(v.Since, v.Until, target)
+}
+
+// validateReleases checks the since and until attributes of a statement, its operands
+// and their values
+func validateReleases(stmt MCSStatement) error {
+	check := func(context, since, until string) error {
+		for _, release := range []string{since, until} {
+			if _, _, ok := ParseRelease(release); release != "" && !ok {
+				return fmt.Errorf("%sinvalid release %q (expected e.g. V3R6)", context, release)
+			}
+		}
+		if since != "" && until != "" && CompareReleases(since, until) > 0 {
+			return fmt.Errorf("%ssince %s is after until %s", context, since, until)
+		}
+		return nil
+	}
+
+	if err := check("", stmt.Since, stmt.Until); err != nil {
+		return err
+	}
+	for _, op := range stmt.Operands {
+		if err := check("operand "+op.Name+": ", op.Since, op.Until); err != nil {
+			return err
+		}
+		for _, v := range op.Values {
+			if err := check("operand "+op.Name+" value "+v.Name+": ", v.Since, v.Until); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrimaryName returns the operand name without its aliases, e.g. DESCRIPTION for DESCRIPTION|DESC
+func (op Operand) PrimaryName() string {
+	name, _, _ := strings.Cut(op.Name, "|")
+	return name
+}
+
+// Operand returns the definition of an operand by its primary name or one of its aliases
+func (stmt MCSStatement) Operand(name string) (Operand, bool) {
+	for _, op := range stmt.Operands {
+		for _, alias := range strings.Split(op.Name, "|") {
+			if alias == name {
+				return op, true
+			}
+		}
+	}
+	return Operand{}, false
+}
+
+// validateRules checks that the operands named in requirement rules and modes exist
+func validateRules(stmt MCSStatement) error {
+	check := func(context, names string) error {
+		for _, name := range strings.Split(names, "|") {
+			if _, ok := stmt.Operand(name); !ok {
+				return fmt.Errorf("%s: unknown operand %q", context, name)
+			}
+		}
+		return nil
+	}
+
+	for _, op := range stmt.Operands {
+		if op.RequiredIf != "" {
+			if err := check("operand "+op.Name+" required_if", op.RequiredIf); err != nil {
+				return err
+			}
+		}
+		if op.RequiredUnless != "" {
+			if err := check("operand "+op.Name+" required_unless", op.RequiredUnless); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, mode := range stmt.Modes {
+		context := "mode " + mode.Name
+		if mode.When != "" {
+			if err := check(context+" when", mode.When); err != nil {
+				return err
+			}
+		}
+		for _, name := range mode.Required {
+			if err := check(context+" required", name); err != nil {
+				return err
+			}
+		}
+		for _, group := range mode.RequiredOneOf {
+			if err := check(context+" required_one_of", strings.Join(group, "|")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Problem describes an inconsistency in a smpe.json file found by Validate
+type Problem struct {
+	Path    string // Location in the file, e.g. statements[++MOD].operands[DISTLIB]
+	Message string
+}
+
+// String formats the problem as "path: message"
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// Validate checks a smpe.json file beyond what Load requires: unknown fields (typos),
+// operand names referenced by allowed_if, mutually_exclusive and the requirement rules,
+// required groups with a single member, colliding operand aliases and unused templates.
+// An empty path validates the embedded smpe.json. An error is returned only if the file
+// cannot be read or parsed.
+func Validate(dataPath string) ([]Problem, error) {
+	fileBytes, err := readData(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := decode(fileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw any
+	if err := json.Unmarshal(fileBytes, &raw); err != nil {
+		return nil, err
+	}
+
+	problems := checkFields(raw)
+	problems = append(problems, checkTemplates(raw)...)
+
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		path := fmt.Sprintf("statements[%s]", stmt.Name)
+		if seen[stmt.Name] {
+			problems = append(problems, Problem{path, "duplicate statement"})
+		}
+		seen[stmt.Name] = true
+		problems = append(problems, checkStatement(path, stmt)...)
+	}
+	return problems, nil
+}
+
+// ValidateLayer checks an overlay for unknown fields and unused templates. Operand
+// references are not checked, as an overlay may refer to the operands of the files below
+// it; they are checked when the layers are merged. An error is returned only if the file
+// cannot be read or parsed.
+func ValidateLayer(layer Layer) ([]Problem, error) {
+	fileBytes, err := os.ReadFile(layer.Path)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if err := json.Unmarshal(fileBytes, &raw); err != nil {
+		return nil, err
+	}
+	return append(checkFields(raw), checkTemplates(raw)...), nil
+}
+
+// checkStatement checks the operand references within a statement definition
+func checkStatement(path string, stmt MCSStatement) []Problem {
+	var problems []Problem
+	add := func(p, format string, args ...any) {
+		problems = append(problems, Problem{p, fmt.Sprintf(format, args...)})
+	}
+
+	if err := validatePatterns(stmt); err != nil {
+		add(path, "%v", err)
+	}
+	if err := validateRules(stmt); err != nil {
+		add(path, "%v", err)
+	}
+	if err := validateReleases(stmt); err != nil {
+		add(path, "%v", err)
+	}
+
+	owners := make(map[string]string)
+	groups := make(map[string][]string)
+	for _, op := range stmt.Operands {
+		opPath := fmt.Sprintf("%s.operands[%s]", path, op.Name)
+		for _, alias := range strings.Split(op.Name, "|") {
+			if owner, ok := owners[alias]; ok {
+				add(opPath, "name %q collides with operand %q", alias, owner)
+			}
+			owners[alias] = op.Name
+		}
+
+		if op.AllowedIf != "" {
+			if _, ok := stmt.Operand(op.AllowedIf); !ok {
+				add(opPath, "allowed_if: unknown operand %q", op.AllowedIf)
+			}
+		}
+		for _, name := range strings.Split(op.MutuallyExclusive, "|") {
+			if _, ok := stmt.Operand(name); name != "" && !ok {
+				add(opPath, "mutually_exclusive: unknown operand %q", name)
+			}
+		}
+
+		switch {
+		case op.RequiredGroup && op.RequiredGroupID == "":
+			add(opPath, "required_group without required_group_id")
+		case op.RequiredGroupID != "" && !op.RequiredGroup:
+			add(opPath, "required_group_id without required_group")
+		case op.RequiredGroup:
+			groups[op.RequiredGroupID] = append(groups[op.RequiredGroupID], op.Name)
+		}
+	}
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if len(groups[id]) < 2 {
+			add(path, "required group %q has only one member (%s)", id, groups[id][0])
+		}
+	}
+	return problems
+}
+
+// checkTemplates reports templates that no statement refers to
+func checkTemplates(raw any) []Problem {
+	root, ok := raw.(map[string]any)
+	if !ok {
+		return nil
+	}
+	templates, _ := root["templates"].(map[string]any)
+	statements, _ := root["statements"].([]any)
+
+	used := make(map[string]bool)
+	for _, s := range statements {
+		stmt, _ := s.(map[string]any)
+		operands, _ := stmt["operands"].([]any)
+		for _, o := range operands {
+			if op, ok := o.(map[string]any); ok {
+				if ref, ok := op["$ref"].(string); ok {
+					used[ref] = true
+				}
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, name := range sortedKeys(templates) {
+		if !used[name] {
+			problems = append(problems, Problem{fmt.Sprintf("templates[%s]", name), "template is not used"})
+		}
+	}
+	return problems
+}
+
+// checkFields reports object keys that do not correspond to a known field, e.g. typos
+// such as "mutualy_exclusive"
+func checkFields(raw any) []Problem {
+	var problems []Problem
+	unknown := func(path string, obj map[string]any, known map[string]bool) {
+		for _, key := range sortedKeys(obj) {
+			if !known[key] {
+				problems = append(problems, Problem{path, fmt.Sprintf("unknown field %q", key)})
+			}
+		}
+	}
+
+	checkOperands := func(path string, operands []any) {
+		for i, o := range operands {
+			op, ok := o.(map[string]any)
+			if !ok {
+				continue
+			}
+			opPath := fmt.Sprintf("%s.operands[%v]", path, nameOr(op, i))
+			unknown(opPath, op, knownOperandFields)
+			values, _ := op["values"].([]any)
+			for j, v := range values {
+				if value, ok := v.(map[string]any); ok {
+					unknown(fmt.Sprintf("%s.values[%v]", opPath, nameOr(value, j)), value, knownValueFields)
+				}
+			}
+		}
+	}
+
+	var statements []any
+	switch root := raw.(type) {
+	case map[string]any:
+		unknown("", root, knownFileFields)
+		templates, _ := root["templates"].(map[string]any)
+		for _, name := range sortedKeys(templates) {
+			operands, _ := templates[name].([]any)
+			checkOperands(fmt.Sprintf("templates[%s]", name), operands)
+		}
+		statements, _ = root["statements"].([]any)
+	case []any:
+		statements = root
+	}
+
+	for i, s := range statements {
+		stmt, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("statements[%v]", nameOr(stmt, i))
+		unknown(path, stmt, knownStatementFields)
+		operands, _ := stmt["operands"].([]any)
+		checkOperands(path, operands)
+		modes, _ := stmt["modes"].([]any)
+		for j, m := range modes {
+			if mode, ok := m.(map[string]any); ok {
+				unknown(fmt.Sprintf("%s.modes[%v]", path, nameOr(mode, j)), mode, knownModeFields)
+			}
+		}
+	}
+	return problems
+}
+
+var (
+	knownFileFields      = map[string]bool{"$schema": true, "templates": true, "statements": true}
+	knownStatementFields = jsonFields(reflect.TypeOf(MCSStatement{}))
+	knownOperandFields   = jsonFields(reflect.TypeOf(Operand{}), "$ref")
+	knownValueFields     = jsonFields(reflect.TypeOf(AllowedValue{}))
+	knownModeFields      = jsonFields(reflect.TypeOf(Mode{}))
+)
+
+// jsonFields returns the JSON field names of a struct type plus extra names
+func jsonFields(t reflect.Type, extra ...string) map[string]bool {
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	for _, name := range extra {
+		fields[name] = true
+	}
+	return fields
+}
+
+// nameOr returns the "name" of a JSON object, or its index if it has none
+func nameOr(obj map[string]any, index int) any {
+	if name, ok := obj["name"].(string); ok && name != "" {
+		return name
+	}
+	return index
+}
+
+// sortedKeys returns the keys of a JSON object in sorted order
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package diagnostics
+
+import (
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// Diagnostic codes identify the rule that produced a diagnostic.
+// They match the keys used in the smpe_lint configuration.
+const (
+	// Syntax
+	CodeUnknownStatement      = "unknown_statement"
+	CodeInvalidLanguageID     = "invalid_language_id"
+	CodeUnbalancedParentheses = "unbalanced_parentheses"
+	CodeMissingTerminator     = "missing_terminator"
+	CodeMissingParameter      = "missing_parameter"
+	CodeContentBeyondCol72    = "content_beyond_column_72"
+	CodeInvalidFormat         = "invalid_format"
+	CodeReservedSysmodPrefix  = "reserved_sysmod_prefix"
+	CodeValueTooLong          = "value_too_long"
+
+	// Operands
+	CodeUnknownOperand         = "unknown_operand"
+	CodeDuplicateOperand       = "duplicate_operand"
+	CodeEmptyOperandParameter  = "empty_operand_parameter"
+	CodeMissingRequiredOperand = "missing_required_operand"
+	CodeDependencyViolation    = "dependency_violation"
+	CodeMutuallyExclusive      = "mutually_exclusive"
+	CodeRequiredGroup          = "required_group"
+	CodeUnsupportedRelease     = "unsupported_release"
+
+	// Sub-operands
+	CodeUnknownSubOperand    = "unknown_sub_operand"
+	CodeSubOperandValidation = "sub_operand_validation"
+
+	// Structural
+	CodeMissingInlineData           = "missing_inline_data"
+	CodeStandaloneCommentBetweenMCS = "standalone_comment_between_mcs"
+
+	// JCL
+	CodeJCLSyntax      = "jcl_syntax"
+	CodeJCLMissingDSN  = "jcl_missing_dsn"
+	CodeJCLNameTooLong = "jcl_name_too_long"
+
+	// IEBUPDTE
+	CodeUpdateSyntax       = "iebupdte_syntax"
+	CodeUpdateSequence     = "iebupdte_sequence"
+	CodeUpdateNameMismatch = "iebupdte_name_mismatch"
+
+	// Workspace
+	CodeDuplicateSysmodDefinition = "duplicate_sysmod_definition"
+	CodeUnresolvedSysmodReference = "unresolved_sysmod_reference"
+
+	// Suppressions
+	CodeUnusedSuppression = "unused_suppression"
+)
+
+// DocsURL is the documentation of all rules; each rule has an anchor named after its code
+const DocsURL = "https://github.com/cybersorcerer/smpe_ls/blob/main/docs/diagnostics.md"
+
+// Rule describes a diagnostic rule
+type Rule struct {
+	Code        string
+	Group       string // Heading the rule is listed under, e.g. "Syntax"
+	Severity    int    // Default LSP severity
+	Description string
+}
+
+// DocsURL returns the URL of the rule's documentation
+func (r Rule) DocsURL() string {
+	return DocsURL + "#" + r.Code
+}
+
+// Rules lists all diagnostic rules, grouped and in documentation order
+var Rules = []Rule{
+	{CodeUnknownStatement, "Syntax", lsp.SeverityError, "Unrecognized MCS statement type"},
+	{CodeInvalidLanguageID, "Syntax", lsp.SeverityError, "Invalid language identifier suffix"},
+	{CodeUnbalancedParentheses, "Syntax", lsp.SeverityError, "Missing opening or closing parenthesis"},
+	{CodeMissingTerminator, "Syntax", lsp.SeverityError, "Statement not terminated with '.'"},
+	{CodeMissingParameter, "Syntax", lsp.SeverityError, "Required statement parameter missing"},
+	{CodeContentBeyondCol72, "Syntax", lsp.SeverityError, "Content extends past column 72"},
+	{CodeInvalidFormat, "Syntax", lsp.SeverityError, "SYSMOD ID, FMID or other value does not match its format"},
+	{CodeReservedSysmodPrefix, "Syntax", lsp.SeverityWarning, "USERMOD ID uses a prefix IBM reserves for its own SYSMODs"},
+	{CodeValueTooLong, "Syntax", lsp.SeverityWarning, "Statement parameter or operand value exceeds its maximum length"},
+
+	{CodeUnknownOperand, "Operands", lsp.SeverityWarning, "Operand not valid for this statement"},
+	{CodeDuplicateOperand, "Operands", lsp.SeverityHint, "Same operand specified multiple times"},
+	{CodeEmptyOperandParameter, "Operands", lsp.SeverityError, "Operand requires a parameter value"},
+	{CodeMissingRequiredOperand, "Operands", lsp.SeverityWarning, "Required operand not specified, also when required by another operand or the statement mode"},
+	{CodeDependencyViolation, "Operands", lsp.SeverityInformation, "Operand requires another operand"},
+	{CodeMutuallyExclusive, "Operands", lsp.SeverityError, "Conflicting operands specified"},
+	{CodeRequiredGroup, "Operands", lsp.SeverityError, "One of a group of operands required, also per statement mode"},
+	{CodeUnsupportedRelease, "Operands", lsp.SeverityWarning, "Statement, operand or value not available in the target release"},
+
+	{CodeUnknownSubOperand, "Sub-Operands", lsp.SeverityWarning, "Sub-operand not valid"},
+	{CodeSubOperandValidation, "Sub-Operands", lsp.SeverityWarning, "Sub-operand value empty or too long"},
+
+	{CodeMissingInlineData, "Structural", lsp.SeverityWarning, "Statement expects inline data that is missing"},
+	{CodeStandaloneCommentBetweenMCS, "Structural", lsp.SeverityError, "Comment on its own line between MCS statements"},
+
+	{CodeJCLSyntax, "JCL", lsp.SeverityError, "Inline JCL of ++JCLIN with a bad continuation, unbalanced parentheses or quotes, or an unknown operation"},
+	{CodeJCLMissingDSN, "JCL", lsp.SeverityWarning, "DD statement in ++JCLIN without a data set name"},
+	{CodeJCLNameTooLong, "JCL", lsp.SeverityError, "Name in ++JCLIN longer than 8 characters, or data set name longer than 44"},
+
+	{CodeUpdateSyntax, "IEBUPDTE", lsp.SeverityError, "IEBUPDTE control statement in ++MACUPD or ++SRCUPD with an unknown operation, invalid or missing parameters"},
+	{CodeUpdateSequence, "IEBUPDTE", lsp.SeverityError, "Sequence numbers in columns 73-80 missing, not numeric or not ascending"},
+	{CodeUpdateNameMismatch, "IEBUPDTE", lsp.SeverityError, "NAME of an IEBUPDTE control statement differs from the element updated"},
+
+	{CodeDuplicateSysmodDefinition, "Workspace", lsp.SeverityWarning, "SYSMOD ID defined more than once in the workspace"},
+	{CodeUnresolvedSysmodReference, "Workspace", lsp.SeverityWarning, "Referenced SYSMOD not defined in the workspace or the known SYSMODs"},
+
+	{CodeUnusedSuppression, "Suppressions", lsp.SeverityWarning, "Suppression comment that does not suppress any diagnostic"},
+}
+
+// LookupRule returns the rule with the given code
+func LookupRule(code string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.Code == code {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
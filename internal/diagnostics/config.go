@@ -0,0 +1,112 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// Severity is the severity a rule is reported with, as written in the configuration
+type Severity string
+
+// Severities accepted in the configuration
+const (
+	SeverityOff     Severity = "off"
+	SeverityHint    Severity = "hint"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// lspSeverities maps the configured severities to LSP severities
+var lspSeverities = map[Severity]int{
+	SeverityOff:     0,
+	SeverityHint:    lsp.SeverityHint,
+	SeverityInfo:    lsp.SeverityInformation,
+	SeverityWarning: lsp.SeverityWarning,
+	SeverityError:   lsp.SeverityError,
+}
+
+// ParseSeverity parses a configured severity, ignoring case. The LSP severity is 0 for off.
+func ParseSeverity(s string) (int, bool) {
+	severity, ok := lspSeverities[Severity(strings.ToLower(strings.TrimSpace(s)))]
+	return severity, ok
+}
+
+// SeverityName returns the configured name of an LSP severity
+func SeverityName(severity int) Severity {
+	for name, s := range lspSeverities {
+		if s == severity {
+			return name
+		}
+	}
+	return SeverityOff
+}
+
+// Config holds the configuration of the diagnostic rules
+type Config struct {
+	// Severities overrides the default severity of rules by code; SeverityOff disables a
+	// rule. Rules not listed are reported with the default severity from Rules.
+	Severities map[string]Severity
+
+	// TargetRelease is the SMP/E release (e.g. V3R6) the MCS are written for. Statements,
+	// operands and values that do not exist in it are reported as unsupported_release.
+	TargetRelease string
+
+	// KnownSysmods lists SYSMOD IDs that exist outside the workspace (e.g. on the target
+	// system) and satisfy references without being defined in a workspace file
+	KnownSysmods map[string]bool
+}
+
+// DefaultConfig returns a config with all rules enabled at their default severity
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Severity returns the LSP severity of a rule, or 0 if the rule is off
+func (c *Config) Severity(code string) int {
+	if s, ok := c.Severities[code]; ok {
+		if severity, ok := ParseSeverity(string(s)); ok {
+			return severity
+		}
+	}
+	rule, _ := LookupRule(code)
+	return rule.Severity
+}
+
+// Enabled checks if a rule is reported
+func (c *Config) Enabled(code string) bool {
+	return c.Severity(code) != 0
+}
+
+// Override returns a copy of the config with the severity of a rule replaced
+func (c *Config) Override(code string, severity Severity) *Config {
+	copied := *c
+	copied.Severities = make(map[string]Severity, len(c.Severities)+1)
+	for k, v := range c.Severities {
+		copied.Severities[k] = v
+	}
+	copied.Severities[code] = severity
+	return &copied
+}
+
+// CheckSeverities reports unknown rule codes and invalid severities in overrides
+func CheckSeverities(severities map[string]Severity) []error {
+	codes := make([]string, 0, len(severities))
+	for code := range severities {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var errs []error
+	for _, code := range codes {
+		if _, ok := LookupRule(code); !ok {
+			errs = append(errs, fmt.Errorf("unknown diagnostic code %q", code))
+		} else if _, ok := ParseSeverity(string(severities[code])); !ok {
+			errs = append(errs, fmt.Errorf("invalid severity %q for %s (use off, hint, info, warning or error)", severities[code], code))
+		}
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package diagnostics
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -16,50 +17,6 @@ import (
 // Columns 73-80 are ignored by SMP/E
 const MaxColumn = 72
 
-// Config holds the configuration for which diagnostics to enable/disable
-type Config struct {
-	UnknownStatement            bool
-	InvalidLanguageId           bool
-	UnbalancedParentheses       bool
-	MissingTerminator           bool
-	MissingParameter            bool
-	UnknownOperand              bool
-	DuplicateOperand            bool
-	EmptyOperandParameter       bool
-	MissingRequiredOperand      bool
-	DependencyViolation         bool
-	MutuallyExclusive           bool
-	RequiredGroup               bool
-	MissingInlineData           bool
-	UnknownSubOperand           bool
-	SubOperandValidation        bool
-	ContentBeyondColumn72       bool
-	StandaloneCommentBetweenMCS bool
-}
-
-// DefaultConfig returns a config with all diagnostics enabled
-func DefaultConfig() *Config {
-	return &Config{
-		UnknownStatement:            true,
-		InvalidLanguageId:           true,
-		UnbalancedParentheses:       true,
-		MissingTerminator:           true,
-		MissingParameter:            true,
-		UnknownOperand:              true,
-		DuplicateOperand:            true,
-		EmptyOperandParameter:       true,
-		MissingRequiredOperand:      true,
-		DependencyViolation:         true,
-		MutuallyExclusive:           true,
-		RequiredGroup:               true,
-		MissingInlineData:           true,
-		UnknownSubOperand:           true,
-		SubOperandValidation:        true,
-		ContentBeyondColumn72:       true,
-		StandaloneCommentBetweenMCS: true,
-	}
-}
-
 // Provider provides diagnostics
 type Provider struct {
 	statements map[string]data.MCSStatement
@@ -86,33 +43,39 @@ func (p *Provider) AnalyzeASTWithConfig(doc *parser.Document, config *Config) []
 // AnalyzeASTWithConfigAndText analyzes an AST document and returns diagnostics based on config
 // The text parameter is needed for column 72 checking
 func (p *Provider) AnalyzeASTWithConfigAndText(doc *parser.Document, config *Config, text string) []lsp.Diagnostic {
-	logger.Debug("Analyzing AST for diagnostics")
-
 	if config == nil {
 		config = DefaultConfig()
 	}
+	return parseSuppressions(doc).apply(p.analyzeAST(doc, config, text))
+}
+
+// analyzeAST returns the diagnostics of a document including suppressed ones
+func (p *Provider) analyzeAST(doc *parser.Document, config *Config, text string) []lsp.Diagnostic {
+	logger.Debug("Analyzing AST for diagnostics")
 
 	// Initialize as empty array to ensure it serializes as [] not null in JSON
 	diagnostics := make([]lsp.Diagnostic, 0)
 
 	// Check for content beyond column 72 (needs original text)
-	if config.ContentBeyondColumn72 && text != "" {
-		diagnostics = append(diagnostics, p.checkContentBeyondColumn72(text)...)
+	if config.Enabled(CodeContentBeyondCol72) && text != "" {
+		diagnostics = append(diagnostics, p.checkContentBeyondColumn72(doc, text, config)...)
 	}
 
 	// Analyze each statement in the AST
 	for _, stmt := range doc.Statements {
 		diagnostics = append(diagnostics, p.analyzeStatementWithConfig(stmt, config)...)
+		diagnostics = append(diagnostics, p.checkJCL(stmt, config)...)
+		diagnostics = append(diagnostics, p.checkUpdate(stmt, config)...)
 	}
 
 	// Check for statements expecting inline data that might be missing it
-	if config.MissingInlineData {
-		diagnostics = append(diagnostics, p.checkMissingInlineData(doc)...)
+	if config.Enabled(CodeMissingInlineData) {
+		diagnostics = append(diagnostics, p.checkMissingInlineData(doc, config)...)
 	}
 
 	// Check for standalone comments between MCS statements
-	if config.StandaloneCommentBetweenMCS {
-		diagnostics = append(diagnostics, p.checkStandaloneCommentsBetweenMCS(doc, text)...)
+	if config.Enabled(CodeStandaloneCommentBetweenMCS) {
+		diagnostics = append(diagnostics, p.checkStandaloneCommentsBetweenMCS(doc, text, config)...)
 	}
 
 	logger.Debug("Found %d diagnostics from AST", len(diagnostics))
@@ -124,16 +87,19 @@ func (p *Provider) analyzeStatementWithConfig(stmt *parser.Node, config *Config)
 	var diagnostics []lsp.Diagnostic
 
 	// Validate statement exists in smpe.json
-	if stmt.StatementDef == nil && (config.UnknownStatement || config.InvalidLanguageId) {
+	if stmt.StatementDef == nil && (config.Enabled(CodeUnknownStatement) || config.Enabled(CodeInvalidLanguageID)) {
+		// Each of the two rules can be turned off on its own
+		report := func(code, message string) {
+			if config.Enabled(code) {
+				diagnostics = append(diagnostics, withFixData(p.newDiagnostic(stmt, code, config, message), FixData{Name: stmt.Name}))
+			}
+		}
+
 		// Check if this looks like a language variant statement with invalid language ID
 		baseName, langID, hasLangID := langid.ExtractLanguageID(stmt.Name)
 		if hasLangID {
 			// Valid language ID but statement doesn't exist - shouldn't happen with proper validation
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				stmt,
-				lsp.SeverityError,
-				"Unknown statement type: "+baseName+" (with language ID "+langID+")",
-			))
+			report(CodeUnknownStatement, "Unknown statement type: "+baseName+" (with language ID "+langID+")")
 		} else {
 			// Check if this could be an invalid language variant
 			// Try to extract last 3 characters as potential language ID
@@ -144,25 +110,13 @@ func (p *Provider) analyzeStatementWithConfig(stmt *parser.Node, config *Config)
 				// Check if base exists and supports language variants
 				if langid.IsLanguageVariantStatement(potentialBase) {
 					// This is a language variant statement with invalid language ID
-					diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-						stmt,
-						lsp.SeverityError,
-						"Invalid language identifier '"+potentialLangID+"' for statement "+potentialBase,
-					))
+					report(CodeInvalidLanguageID, "Invalid language identifier '"+potentialLangID+"' for statement "+potentialBase)
 				} else {
 					// Just an unknown statement
-					diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-						stmt,
-						lsp.SeverityError,
-						"Unknown statement type: "+stmt.Name,
-					))
+					report(CodeUnknownStatement, "Unknown statement type: "+stmt.Name)
 				}
 			} else {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
-					"Unknown statement type: "+stmt.Name,
-				))
+				report(CodeUnknownStatement, "Unknown statement type: "+stmt.Name)
 			}
 		}
 		return diagnostics
@@ -172,33 +126,30 @@ func (p *Provider) analyzeStatementWithConfig(stmt *parser.Node, config *Config)
 	// not that it MUST have one. Per syntax diagram, ++SAMP and ++SAMPENU are both valid.
 
 	// Check for unbalanced parentheses first (more specific error)
-	if config.UnbalancedParentheses {
+	if config.Enabled(CodeUnbalancedParentheses) {
 		if stmt.UnbalancedParens > 0 {
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				stmt,
-				lsp.SeverityError,
+			diagnostics = append(diagnostics, p.newDiagnostic(
+				stmt, CodeUnbalancedParentheses, config,
 				"Missing closing parenthesis ')'",
 			))
 		} else if stmt.UnbalancedParens < 0 {
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				stmt,
-				lsp.SeverityError,
+			diagnostics = append(diagnostics, p.newDiagnostic(
+				stmt, CodeUnbalancedParentheses, config,
 				"Missing opening parenthesis '(' or extra closing parenthesis ')'",
 			))
 		}
 	}
 
 	// Check for missing terminator (only if parens are balanced)
-	if config.MissingTerminator && !stmt.HasTerminator && stmt.UnbalancedParens == 0 {
-		diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-			stmt,
-			lsp.SeverityError,
+	if config.Enabled(CodeMissingTerminator) && !stmt.HasTerminator && stmt.UnbalancedParens == 0 {
+		diagnostics = append(diagnostics, p.newDiagnostic(
+			stmt, CodeMissingTerminator, config,
 			"Statement must be terminated with '.'",
 		))
 	}
 
 	// Check for required statement parameter and its length
-	if config.MissingParameter && stmt.StatementDef != nil && stmt.StatementDef.Parameter != "" {
+	if stmt.StatementDef != nil && stmt.StatementDef.Parameter != "" {
 		var paramNode *parser.Node
 		for _, child := range stmt.Children {
 			if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
@@ -208,22 +159,32 @@ func (p *Provider) analyzeStatementWithConfig(stmt *parser.Node, config *Config)
 		}
 
 		if paramNode == nil {
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				stmt,
-				lsp.SeverityError,
-				"Missing required parameter: "+stmt.StatementDef.Parameter,
-			))
-		} else if stmt.StatementDef.Length > 0 && len(paramNode.Value) > stmt.StatementDef.Length {
+			if config.Enabled(CodeMissingParameter) {
+				diagnostics = append(diagnostics, p.newDiagnostic(
+					stmt, CodeMissingParameter, config,
+					"Missing required parameter: "+stmt.StatementDef.Parameter,
+				))
+			}
+		} else if config.Enabled(CodeValueTooLong) && stmt.StatementDef.Length > 0 && len(paramNode.Value) > stmt.StatementDef.Length {
 			// Check parameter length
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				paramNode,
-				lsp.SeverityWarning,
+			diagnostics = append(diagnostics, p.newDiagnostic(
+				paramNode, CodeValueTooLong, config,
 				fmt.Sprintf("Parameter '%s' exceeds maximum length (%d > %d)",
 					stmt.StatementDef.Parameter, len(paramNode.Value), stmt.StatementDef.Length),
 			))
 		}
 	}
 
+	// Check statement parameter and operand values against their patterns
+	if config.Enabled(CodeInvalidFormat) || config.Enabled(CodeReservedSysmodPrefix) {
+		diagnostics = append(diagnostics, p.checkPatterns(stmt, config)...)
+	}
+
+	// Check that the statement, its operands and values exist in the target release
+	if config.Enabled(CodeUnsupportedRelease) && config.TargetRelease != "" {
+		diagnostics = append(diagnostics, p.checkReleases(stmt, config)...)
+	}
+
 	// Collect operands from children
 	operands := make(map[string]*parser.Node)
 	var operandList []*parser.Node
@@ -261,12 +222,11 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 	}
 
 	// Check for unknown operands
-	if config.UnknownOperand {
+	if config.Enabled(CodeUnknownOperand) {
 		for opName, opNode := range operands {
 			if !validOperands[opName] {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					opNode,
-					lsp.SeverityWarning,
+				diagnostics = append(diagnostics, p.newDiagnostic(
+					opNode, CodeUnknownOperand, config,
 					"Unknown operand '"+opName+"' for statement "+stmt.Name,
 				))
 			}
@@ -277,16 +237,15 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 	seen := make(map[string]*parser.Node)
 	for _, opNode := range operandList {
 		if prevNode, exists := seen[opNode.Name]; exists {
-			if config.DuplicateOperand {
+			if config.Enabled(CodeDuplicateOperand) {
 				// Create diagnostic pointing to the duplicate
 				msg := "Duplicate operand '" + opNode.Name + "'"
 				if prevNode.Position.Line != opNode.Position.Line {
 					// Only mention line if different
 					msg += " (first occurrence at line " + strconv.Itoa(prevNode.Position.Line+1) + ")"
 				}
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					opNode,
-					lsp.SeverityHint,
+				diagnostics = append(diagnostics, p.newDiagnostic(
+					opNode, CodeDuplicateOperand, config,
 					msg,
 				))
 			}
@@ -302,7 +261,7 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 		for _, name := range names {
 			if opNode, exists := operands[name]; exists {
 				// Check if this operand expects a parameter
-				if config.EmptyOperandParameter && op.Parameter != "" {
+				if config.Enabled(CodeEmptyOperandParameter) && op.Parameter != "" {
 					// Check if operand has children (either parameters or sub-operands)
 					hasParam := false
 					for _, child := range opNode.Children {
@@ -319,9 +278,8 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 					}
 
 					if !hasParam {
-						diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-							opNode,
-							lsp.SeverityError,
+						diagnostics = append(diagnostics, p.newDiagnostic(
+							opNode, CodeEmptyOperandParameter, config,
 							"Operand '"+name+"' requires a parameter: "+op.Parameter,
 						))
 					}
@@ -329,7 +287,7 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 
 				// Check length constraints for operand parameters
 				// Only check if operand has a length defined and has a parameter value
-				if op.Length > 0 {
+				if op.Length > 0 && config.Enabled(CodeValueTooLong) {
 					for _, child := range opNode.Children {
 						if child.Type == parser.NodeTypeParameter {
 							if op.Type == "list" {
@@ -338,9 +296,8 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 									if listItem.Type == parser.NodeTypeParameter {
 										itemValue := strings.TrimSpace(listItem.Value)
 										if itemValue != "" && len(itemValue) > op.Length {
-											diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-												listItem,
-												lsp.SeverityWarning,
+											diagnostics = append(diagnostics, p.newDiagnostic(
+												listItem, CodeValueTooLong, config,
 												fmt.Sprintf("List element '%s' in operand '%s' exceeds maximum length (%d > %d)", itemValue, name, len(itemValue), op.Length),
 											))
 										}
@@ -364,9 +321,8 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 									}
 								}
 								if paramValue != "" && len(paramValue) > op.Length {
-									diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-										child,
-										lsp.SeverityWarning,
+									diagnostics = append(diagnostics, p.newDiagnostic(
+										child, CodeValueTooLong, config,
 										fmt.Sprintf("Operand '%s' parameter exceeds maximum length (%d > %d)", name, len(paramValue), op.Length),
 									))
 								}
@@ -377,7 +333,7 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 				}
 
 				// Check if this operand has sub-operands (values array) that need validation
-				if (config.UnknownSubOperand || config.SubOperandValidation) && len(op.Values) > 0 && strings.Contains(op.Parameter, "(") {
+				if (config.Enabled(CodeUnknownSubOperand) || config.Enabled(CodeSubOperandValidation)) && len(op.Values) > 0 && strings.Contains(op.Parameter, "(") {
 					// This operand has sub-operands - validate them
 					subDiags := p.validateSubOperandsASTWithConfig(opNode, op.Values, config)
 					diagnostics = append(diagnostics, subDiags...)
@@ -386,48 +342,12 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 		}
 	}
 
-	// Check for missing required operands
+	// Check for missing required operands (required, required_if, required_unless and modes)
 	// Note: Operands with required_group are handled separately below
-	if config.MissingRequiredOperand {
-		requiredOperands := getRequiredOperands(stmt.Name, operands)
-		for _, requiredOp := range requiredOperands {
-			// Check if any alias of this operand is present
-			found := false
-			isRequiredGroup := false
-			for _, op := range stmtDef.Operands {
-				names := strings.Split(op.Name, "|")
-				primaryName := names[0]
-
-				if primaryName == requiredOp {
-					// Skip if this is part of a required_group (handled separately)
-					if op.RequiredGroup {
-						isRequiredGroup = true
-						break
-					}
-
-					// Check if this operand (or any of its aliases) is present
-					for _, name := range names {
-						if _, exists := operands[name]; exists {
-							found = true
-							break
-						}
-					}
-					break
-				}
-			}
-
-			if !found && !isRequiredGroup {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityWarning,
-					"Missing required operand: "+requiredOp,
-				))
-			}
-		}
-	}
+	diagnostics = append(diagnostics, p.checkRequirements(stmt, stmtDef, operands, config)...)
 
 	// Check for dependency violations (allowed_if)
-	if config.DependencyViolation {
+	if config.Enabled(CodeDependencyViolation) {
 		for _, op := range stmtDef.Operands {
 			names := strings.Split(op.Name, "|")
 			primaryName := names[0]
@@ -447,11 +367,10 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 				if operandPresent {
 					// Check if dependency is met
 					if _, exists := operands[op.AllowedIf]; !exists {
-						diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-							operandNode,
-							lsp.SeverityInformation,
+						diagnostics = append(diagnostics, withFixData(p.newDiagnostic(
+							operandNode, CodeDependencyViolation, config,
 							primaryName+" requires "+op.AllowedIf+" to be specified",
-						))
+						), FixData{Operands: []string{op.AllowedIf}}))
 					}
 				}
 			}
@@ -459,7 +378,7 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 	}
 
 	// Check for mutually exclusive operands
-	if config.MutuallyExclusive {
+	if config.Enabled(CodeMutuallyExclusive) {
 		for _, op := range stmtDef.Operands {
 			names := strings.Split(op.Name, "|")
 			primaryName := names[0]
@@ -481,9 +400,8 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 					exclusiveOperands := strings.Split(op.MutuallyExclusive, "|")
 					for _, exclusive := range exclusiveOperands {
 						if _, exists := operands[exclusive]; exists {
-							diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-								operandNode,
-								lsp.SeverityError,
+							diagnostics = append(diagnostics, p.newDiagnostic(
+								operandNode, CodeMutuallyExclusive, config,
 								primaryName+" is mutually exclusive with "+exclusive,
 							))
 						}
@@ -495,7 +413,7 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 
 	// Check for required_group: when multiple operands are marked as required + required_group,
 	// at least one of them must be present
-	if config.RequiredGroup {
+	if config.Enabled(CodeRequiredGroup) {
 		requiredGroups := make(map[string][]string) // required_group_id -> list of operand names
 		for _, op := range stmtDef.Operands {
 			if op.Required && op.RequiredGroup && op.RequiredGroupID != "" {
@@ -531,70 +449,11 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 			if !atLeastOnePresent {
 				// Build a human-readable list of options
 				optionsList := strings.Join(groupMembers, ", ")
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
+				diagnostics = append(diagnostics, withFixData(p.newDiagnostic(
+					stmt, CodeRequiredGroup, config,
 					"One of the following operands must be specified: "+optionsList,
-				))
-			}
-		}
-	}
-
-	// Special validation for ++MOVE based on syntax diagrams
-	// From syntax_diagrams/move-distlib.png and move-syslib.png
-	if stmt.Name == "++MOVE" {
-		hasDistlib := operands["DISTLIB"] != nil
-		hasSyslib := operands["SYSLIB"] != nil
-
-		// DISTLIB mode validation
-		if hasDistlib {
-			// TODISTLIB is required when DISTLIB is present
-			if operands["TODISTLIB"] == nil {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
-					"TODISTLIB is required when DISTLIB is specified",
-				))
-			}
-
-			// One of MAC, MOD, or SRC is required in DISTLIB mode
-			if operands["MAC"] == nil && operands["MOD"] == nil && operands["SRC"] == nil {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
-					"One of MAC, MOD, or SRC is required when DISTLIB is specified",
-				))
-			}
-		}
-
-		// SYSLIB mode validation
-		if hasSyslib {
-			// TOSYSLIB is required when SYSLIB is present
-			if operands["TOSYSLIB"] == nil {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
-					"TOSYSLIB is required when SYSLIB is specified",
-				))
+				), FixData{Operands: groupMembers}))
 			}
-
-			// One of MAC, SRC, LMOD, or FMID is required in SYSLIB mode
-			if operands["MAC"] == nil && operands["SRC"] == nil && operands["LMOD"] == nil && operands["FMID"] == nil {
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityError,
-					"One of MAC, SRC, LMOD, or FMID is required when SYSLIB is specified",
-				))
-			}
-		}
-
-		// At least one mode must be specified (DISTLIB or SYSLIB)
-		if !hasDistlib && !hasSyslib {
-			diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-				stmt,
-				lsp.SeverityError,
-				"Either DISTLIB or SYSLIB must be specified",
-			))
 		}
 	}
 
@@ -603,13 +462,15 @@ func (p *Provider) validateOperandsASTWithConfig(stmt *parser.Node, operands map
 
 // checkContentBeyondColumn72 checks for content that extends beyond column 72
 // Per IBM documentation, columns 73-80 are ignored by SMP/E
-func (p *Provider) checkContentBeyondColumn72(text string) []lsp.Diagnostic {
+// Inline JCL and IEBUPDTE records may hold sequence numbers there and are not checked
+func (p *Provider) checkContentBeyondColumn72(doc *parser.Document, text string, config *Config) []lsp.Diagnostic {
 	var diagnostics []lsp.Diagnostic
 	lines := strings.Split(text, "\n")
+	records := recordLines(doc)
 
 	for lineNum, line := range lines {
-		// Skip empty lines
-		if len(line) == 0 {
+		// Skip empty lines and records of inline data
+		if len(line) == 0 || records[lineNum] {
 			continue
 		}
 
@@ -621,21 +482,16 @@ func (p *Provider) checkContentBeyondColumn72(text string) []lsp.Diagnostic {
 			beyondContent := strings.TrimSpace(string(runes[MaxColumn:]))
 			if beyondContent != "" {
 				// There's actual content beyond column 72
-				diagnostics = append(diagnostics, lsp.Diagnostic{
-					Range: lsp.Range{
-						Start: lsp.Position{
-							Line:      lineNum,
-							Character: MaxColumn,
-						},
-						End: lsp.Position{
-							Line:      lineNum,
-							Character: len(runes),
-						},
+				diagnostics = append(diagnostics, newRangeDiagnostic(lsp.Range{
+					Start: lsp.Position{
+						Line:      lineNum,
+						Character: MaxColumn,
 					},
-					Severity: lsp.SeverityError,
-					Source:   "smpe_ls",
-					Message:  "🔴 Content beyond column 72 will be ignored by SMP/E",
-				})
+					End: lsp.Position{
+						Line:      lineNum,
+						Character: len(runes),
+					},
+				}, CodeContentBeyondCol72, config, "Content beyond column 72 will be ignored by SMP/E"))
 			}
 		}
 	}
@@ -643,6 +499,29 @@ func (p *Provider) checkContentBeyondColumn72(text string) []lsp.Diagnostic {
 	return diagnostics
 }
 
+// recordLines returns the lines of inline JCL and IEBUPDTE input, which are 80-column
+// records rather than MCS
+func recordLines(doc *parser.Document) map[int]bool {
+	lines := make(map[int]bool)
+	for _, stmt := range doc.Statements {
+		if stmt.JCL != nil {
+			for _, s := range stmt.JCL.Statements {
+				for line := s.Line; line <= s.EndLine; line++ {
+					lines[line] = true
+				}
+			}
+		}
+		if stmt.Update != nil {
+			for _, control := range stmt.Update.Controls {
+				for line := control.Line; line <= control.LastLine(); line++ {
+					lines[line] = true
+				}
+			}
+		}
+	}
+	return lines
+}
+
 // checkStandaloneCommentsBetweenMCS checks for comments that stand alone between MCS statements
 // Per IBM rules: Comments are allowed on the same line as the terminator (.) and can span multiple lines,
 // but a comment that starts on a NEW line between statements causes a SMP/E syntax error.
@@ -651,7 +530,7 @@ func (p *Provider) checkContentBeyondColumn72(text string) []lsp.Diagnostic {
 // - Comments AFTER the last statement in the file
 // Note: Statements with inline_data (++MAC, ++SRC, etc.) are excluded from this check
 // because comments after them are part of inline data.
-func (p *Provider) checkStandaloneCommentsBetweenMCS(doc *parser.Document, text string) []lsp.Diagnostic {
+func (p *Provider) checkStandaloneCommentsBetweenMCS(doc *parser.Document, text string, config *Config) []lsp.Diagnostic {
 	var diagnostics []lsp.Diagnostic
 
 	if text == "" {
@@ -748,25 +627,20 @@ func (p *Provider) checkStandaloneCommentsBetweenMCS(doc *parser.Document, text
 				// This is a standalone comment - ERROR
 				var message string
 				if isBeforeFirstStmt {
-					message = "🔴 Comment not allowed before first MCS statement - SMP/E syntax error"
+					message = "Comment not allowed before first MCS statement - SMP/E syntax error"
 				} else {
-					message = "🔴 Comment not allowed between MCS statements - SMP/E syntax error"
+					message = "Comment not allowed between MCS statements - SMP/E syntax error"
 				}
-				diagnostics = append(diagnostics, lsp.Diagnostic{
-					Range: lsp.Range{
-						Start: lsp.Position{
-							Line:      lineNum,
-							Character: strings.Index(line, "/*"),
-						},
-						End: lsp.Position{
-							Line:      lineNum,
-							Character: len(line),
-						},
+				diagnostics = append(diagnostics, newRangeDiagnostic(lsp.Range{
+					Start: lsp.Position{
+						Line:      lineNum,
+						Character: strings.Index(line, "/*"),
 					},
-					Severity: lsp.SeverityError,
-					Source:   "smpe_ls",
-					Message:  message,
-				})
+					End: lsp.Position{
+						Line:      lineNum,
+						Character: len(line),
+					},
+				}, CodeStandaloneCommentBetweenMCS, config, message))
 				// Skip remaining lines of this multi-line comment
 				if !strings.Contains(trimmed, "*/") {
 					for lineNum++; lineNum < endLine && lineNum < len(lines); lineNum++ {
@@ -827,7 +701,7 @@ func (p *Provider) checkStandaloneCommentsBetweenMCS(doc *parser.Document, text
 }
 
 // checkMissingInlineData checks if statements expecting inline data actually have it
-func (p *Provider) checkMissingInlineData(doc *parser.Document) []lsp.Diagnostic {
+func (p *Provider) checkMissingInlineData(doc *parser.Document, config *Config) []lsp.Diagnostic {
 	var diagnostics []lsp.Diagnostic
 
 	// If a statement expecting inline data is followed by another statement (or comment + statement),
@@ -864,16 +738,14 @@ func (p *Provider) checkMissingInlineData(doc *parser.Document) []lsp.Diagnostic
 		if !stmt.HasInlineData {
 			if stmtIndex != -1 && stmtIndex < len(doc.Statements)-1 {
 				// Statement is not the last one - inline data should come before next statement
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityWarning,
+				diagnostics = append(diagnostics, p.newDiagnostic(
+					stmt, CodeMissingInlineData, config,
 					p.getMissingInlineDataMessage(stmt)+" before next statement",
 				))
 			} else if stmtIndex == len(doc.Statements)-1 {
 				// This is the last statement in the document - inline data is missing
-				diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-					stmt,
-					lsp.SeverityWarning,
+				diagnostics = append(diagnostics, p.newDiagnostic(
+					stmt, CodeMissingInlineData, config,
 					p.getMissingInlineDataMessage(stmt),
 				))
 			}
@@ -934,10 +806,9 @@ func (p *Provider) validateSubOperandsASTWithConfig(operandNode *parser.Node, su
 			subOpDef, exists := subOpDefMap[child.Name]
 			if !exists {
 				// Unknown sub-operand
-				if config.UnknownSubOperand {
-					diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-						child,
-						lsp.SeverityWarning,
+				if config.Enabled(CodeUnknownSubOperand) {
+					diagnostics = append(diagnostics, p.newDiagnostic(
+						child, CodeUnknownSubOperand, config,
 						"Unknown sub-operand '"+child.Name+"' for "+operandNode.Name,
 					))
 				}
@@ -956,13 +827,12 @@ func (p *Provider) validateSubOperandsASTWithConfig(operandNode *parser.Node, su
 				}
 			}
 
-			if config.SubOperandValidation {
+			if config.Enabled(CodeSubOperandValidation) {
 				// Check if parameter is empty when it shouldn't be
 				if subOpDef.Length > 0 && (subOpDef.Type == "string" || subOpDef.Type == "integer") {
 					if !hasParam || paramValue == "" {
-						diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-							child,
-							lsp.SeverityWarning,
+						diagnostics = append(diagnostics, p.newDiagnostic(
+							child, CodeSubOperandValidation, config,
 							"Sub-operand '"+child.Name+"' of "+operandNode.Name+" has empty parameter (expected "+subOpDef.Type+")",
 						))
 					}
@@ -970,9 +840,8 @@ func (p *Provider) validateSubOperandsASTWithConfig(operandNode *parser.Node, su
 
 				// Check length constraints for non-empty values
 				if hasParam && paramValue != "" && subOpDef.Length > 0 && len(paramValue) > subOpDef.Length {
-					diagnostics = append(diagnostics, p.createDiagnosticFromNode(
-						child,
-						lsp.SeverityWarning,
+					diagnostics = append(diagnostics, p.newDiagnostic(
+						child, CodeSubOperandValidation, config,
 						"Sub-operand '"+child.Name+"' of "+operandNode.Name+" exceeds maximum length",
 					))
 				}
@@ -983,8 +852,25 @@ func (p *Provider) validateSubOperandsASTWithConfig(operandNode *parser.Node, su
 	return diagnostics
 }
 
-// createDiagnosticFromNode creates a diagnostic from an AST node
-func (p *Provider) createDiagnosticFromNode(node *parser.Node, severity int, message string) lsp.Diagnostic {
+// newDiagnostic creates a diagnostic of a rule covering an AST node
+func (p *Provider) newDiagnostic(node *parser.Node, code string, config *Config, message string) lsp.Diagnostic {
+	return newRangeDiagnostic(lsp.Range{
+		Start: lsp.Position{
+			Line:      node.Position.Line,
+			Character: node.Position.Character,
+		},
+		End: lsp.Position{
+			Line:      node.Position.Line,
+			Character: node.Position.Character + node.Position.Length,
+		},
+	}, code, config, message)
+}
+
+// newRangeDiagnostic creates a diagnostic of a rule with the severity configured for the
+// rule and a link to its documentation
+func newRangeDiagnostic(r lsp.Range, code string, config *Config, message string) lsp.Diagnostic {
+	severity := config.Severity(code)
+
 	// Add severity prefix with Unicode symbols for better visual distinction
 	var prefix string
 	switch severity {
@@ -998,123 +884,38 @@ func (p *Provider) createDiagnosticFromNode(node *parser.Node, severity int, mes
 		prefix = "💡 "
 	}
 
+	rule, _ := LookupRule(code)
 	return lsp.Diagnostic{
-		Range: lsp.Range{
-			Start: lsp.Position{
-				Line:      node.Position.Line,
-				Character: node.Position.Character,
-			},
-			End: lsp.Position{
-				Line:      node.Position.Line,
-				Character: node.Position.Character + node.Position.Length,
-			},
-		},
-		Severity: severity,
-		Source:   "smpe_ls",
-		Message:  prefix + message,
+		Range:           r,
+		Severity:        severity,
+		Code:            code,
+		CodeDescription: &lsp.CodeDescription{Href: rule.DocsURL()},
+		Source:          "smpe_ls",
+		Message:         prefix + message,
 	}
 }
 
-// getRequiredOperands returns the list of required operands for a statement
-// These requirements are derived from the syntax diagrams in syntax_diagrams/
-func getRequiredOperands(statementType string, presentOperands map[string]*parser.Node) []string {
-	switch statementType {
-	case "++ASSIGN":
-		// From syntax_diagrams/assign.png: SOURCEID and TO are required
-		return []string{"SOURCEID", "TO"}
-	case "++IF":
-		// From syntax_diagrams/if.png: FMID and REQ are required, THEN is optional
-		return []string{"FMID", "REQ"}
-	case "++DELETE":
-		// From syntax_diagrams/delete.png: SYSLIB is required
-		return []string{"SYSLIB"}
-	case "++JAR":
-		// From syntax_diagrams/jar-add.png and jar-delete.png:
-		// No operands are strictly required beyond the name parameter
-		// DISTLIB and SYSLIB are important but not enforced as required here
-		// The statement itself requires either add mode (DISTLIB/SYSLIB) or delete mode (DELETE)
-		return []string{}
-	case "++MOD":
-		// From syntax_diagrams/mod_add_replace.png: DISTLIB is required for ADD/REPLACE mode
-		// From syntax_diagrams/mod_delete.png: DELETE mode has no required operands beyond DELETE itself
-		if _, hasDelete := presentOperands["DELETE"]; hasDelete {
-			return []string{}
-		}
-		return []string{"DISTLIB"}
-	case "++MOVE":
-		// From syntax_diagrams/move-distlib.png and move-syslib.png:
-		// DISTLIB mode: DISTLIB + TODISTLIB + one of (MAC|MOD|SRC) required
-		// SYSLIB mode: SYSLIB + TOSYSLIB + one of (MAC|SRC|LMOD|FMID) required
-		// Complex conditional validation is now handled in validateOperandsAST for ++MOVE
-		return []string{}
-	case "++JARUPD":
-		// From syntax_diagrams/jar-upd.png:
-		// No operands are strictly required beyond the name parameter
-		return []string{}
-	case "++VER":
-		// From syntax_diagrams/ver.png:
-		// No operands are strictly required, all are optional
-		return []string{}
-	case "++ZAP":
-		// From syntax_diagrams/zap.png:
-		// No operands are strictly required beyond the name parameter
-		// DALIAS and TALIAS are mutually exclusive
-		return []string{}
-	case "++MAC":
-		// From syntax_diagrams/mac.png and mac-delete.png:
-		// DISTLIB is required in ADD/UPDATE mode (when DELETE is not specified)
-		// In DELETE mode (when DELETE is specified), DISTLIB is optional
-		// Since this is conditional, we don't enforce it here
-		// The mutually_exclusive validation handles the DELETE operand constraints
-		return []string{}
-	case "++SRC":
-		// From syntax_diagrams/src-add-replace.png and src-delete.png:
-		// DISTLIB is required in ADD/REPLACE mode (when DELETE is not specified)
-		// In DELETE mode, DISTLIB is optional
-		if _, hasDelete := presentOperands["DELETE"]; hasDelete {
-			return []string{}
-		}
-		return []string{"DISTLIB"}
-	case "++RENAME":
-		// From syntax_diagrams/rename.png:
-		// TONAME is required (must follow old_name parameter)
-		return []string{"TONAME"}
-	case "++USERMOD":
-		// From syntax_diagrams/usermod.png:
-		// No operands are strictly required, all are optional
-		// RFDSNPFX has allowed_if dependency on FILES (handled automatically)
-		return []string{}
-	case "++PRODUCT":
-		// From syntax_diagrams/product.png:
-		// DESCRIPTION (or DESC) and SREL are required operands
-		return []string{"DESCRIPTION", "SREL"}
-	case "++PROGRAM":
-		// From syntax_diagrams/program-add-replace.png and program-delete.png:
-		// DISTLIB is required for ADD/REPLACE mode
-		// In DELETE mode, DISTLIB is optional
-		if _, hasDelete := presentOperands["DELETE"]; hasDelete {
-			return []string{}
-		}
-		return []string{"DISTLIB"}
-	case "++PTF":
-		// From syntax_diagrams/ptf.png:
-		// No operands are strictly required, all are optional
-		// RFDSNPFX has allowed_if dependency on FILES (handled automatically)
-		return []string{}
-	case "++FEATURE":
-		// From syntax_diagrams/feature.png:
-		// No operands are strictly required, all are optional
-		// The diagram shows DESCRIPTION, FMID, PRODUCT, and REWORK can all be bypassed
-		return []string{}
-	case "++RELEASE":
-		// From syntax_diagrams/release.png:
-		// FMID and REASON are required
-		// One of ERROR/FIXCAT/SYSTEM/USER is required (mutually_exclusive group)
-		// Note: We don't list ERROR/FIXCAT/SYSTEM/USER here because they are handled
-		// by the mutually_exclusive required group validation in smpe.json
-		return []string{"FMID", "REASON"}
-	default:
-		// No required operands for other statements (based on current syntax diagrams)
-		return []string{}
+// FixData holds the details quick fixes need beyond the code and range of a diagnostic.
+// It is sent as the data of the diagnostic, which clients return in code action requests.
+type FixData struct {
+	Operands []string `json:"operands,omitempty"` // Operands to insert: the missing one, one of a required group or the one required by another
+	Name     string   `json:"name,omitempty"`     // Name of an unknown statement
+}
+
+// withFixData attaches fix data to a diagnostic
+func withFixData(diag lsp.Diagnostic, fixData FixData) lsp.Diagnostic {
+	raw, err := json.Marshal(fixData)
+	if err == nil {
+		diag.Data = raw
+	}
+	return diag
+}
+
+// FixDataOf returns the fix data of a diagnostic; it is empty if the diagnostic has none
+func FixDataOf(diag lsp.Diagnostic) FixData {
+	var fixData FixData
+	if len(diag.Data) > 0 {
+		_ = json.Unmarshal(diag.Data, &fixData)
 	}
+	return fixData
 }
@@ -3,9 +3,11 @@ package diagnostics
 // Tests for diagnostic types not covered by diagnostics_test.go:
 // DuplicateOperand, MissingRequiredOperand, DependencyViolation,
 // MutuallyExclusive, RequiredGroup, ContentBeyondColumn72,
-// StandaloneCommentBetweenMCS, MissingInlineData, UnknownStatement
+// StandaloneCommentBetweenMCS, MissingInlineData, UnknownStatement,
+// InvalidFormat, ReservedSysmodPrefix, UnsupportedRelease and the severity overrides
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cybersorcerer/smpe_ls/internal/data"
@@ -99,6 +101,114 @@ func TestDiagnosticsMissingRequiredOperand_IF(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsMissingRequiredOperand_Modes(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	// ++MOD requires DISTLIB in ADD/REPLACE mode but not in DELETE mode
+	input := "++MOD(MYMOD) .\n++MOD(OLDMOD) DELETE .\n"
+	diags := dp.AnalyzeAST(p.Parse(input))
+	t.Logf("Diagnostics: %v", diags)
+
+	var missing []lsp.Diagnostic
+	for _, d := range diags {
+		if d.Code == CodeMissingRequiredOperand {
+			missing = append(missing, d)
+		}
+	}
+	if len(missing) != 1 || missing[0].Range.Start.Line != 0 || !containsText(missing[0].Message, "in ADD/REPLACE mode: DISTLIB") {
+		t.Errorf("Expected DISTLIB to be required in ADD/REPLACE mode only, got %v", missing)
+	}
+}
+
+func TestDiagnosticsMissingRequiredOperand_Statements(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	// The required operands of each statement when none is specified; data elements
+	// such as ++SAMP require none, ++MOD, ++SRC and ++PROGRAM none in DELETE mode.
+	// ++PROGRAM also requires SYSLIB in ADD/REPLACE mode
+	tests := map[string][]string{
+		"++ASSIGN .":                          {"SOURCEID", "TO"},
+		"++IF .":                              {"FMID", "REQ"},
+		"++DELETE(MYLMOD) .":                  {"SYSLIB"},
+		"++RENAME(MYLMOD) .":                  {"TONAME"},
+		"++PRODUCT(5655,ABC) .":               {"DESCRIPTION", "SREL"},
+		"++RELEASE(UA12345) .":                {"FMID", "REASON"},
+		"++MOD(MYMOD) .":                      {"DISTLIB"},
+		"++SRC(MYSRC) .":                      {"DISTLIB"},
+		"++PROGRAM(MYPGM) .":                  {"DISTLIB", "SYSLIB"},
+		"++PROGRAM(MYPGM) DISTLIB(APGMLIB) .": {"SYSLIB"},
+		"++MOD(MYMOD) DELETE .":               nil,
+		"++SRC(MYSRC) DELETE .":               nil,
+		"++PROGRAM(MYPGM) DELETE .":           nil,
+		"++MAC(MYMAC) .":                      nil,
+		"++JAR(MYJAR) .":                      nil,
+		"++ZAP(MYZAP) .":                      nil,
+		"++PTF(UA12345) .":                    nil,
+		"++SAMP(MYSAMP) .":                    nil,
+		"++MSG(MYMSG) .":                      nil,
+		"++PNL(MYPNL) .":                      nil,
+		"++TEXT(MYTEXT) .":                    nil,
+		"++EXEC(MYEXEC) .":                    nil,
+		"++HELP(MYHELP) .":                    nil,
+		"++SKL(MYSKL) .":                      nil,
+		"++TBL(MYTBL) .":                      nil,
+		"++BOOK(MYBOOK) .":                    nil,
+		"++PROBJ(MYPROBJ) .":                  nil,
+	}
+
+	for input, want := range tests {
+		var got []string
+		for _, d := range dp.AnalyzeAST(p.Parse(input)) {
+			if d.Code == CodeMissingRequiredOperand {
+				got = append(got, FixDataOf(d).Operands...)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: missing required operands %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestDiagnosticsMissingRequiredOperand_MOVE(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "++MOVE(MYMAC) DISTLIB(AMACLIB) MAC .\n++MOVE(MYMOD) SYSLIB(LINKLIB) TOSYSLIB(LPALIB) .\n++MOVE(MYSRC) SRC .\n"
+	diags := dp.AnalyzeAST(p.Parse(input))
+	t.Logf("Diagnostics: %v", diags)
+
+	if !hasDiagnostic(diags, lsp.SeverityWarning, "when DISTLIB is specified: TODISTLIB") {
+		t.Error("Expected TODISTLIB to be required with DISTLIB")
+	}
+	if !hasDiagnostic(diags, lsp.SeverityError, "in SYSLIB mode: MAC, SRC, LMOD, FMID") {
+		t.Error("Expected one of MAC, SRC, LMOD or FMID to be required in SYSLIB mode")
+	}
+	if !hasDiagnostic(diags, lsp.SeverityError, "must be specified: DISTLIB, SYSLIB") {
+		t.Error("Expected DISTLIB or SYSLIB to be required")
+	}
+	if !noDiagnosticWith(diags, "TOSYSLIB") || !noDiagnosticWith(diags, "in DISTLIB mode") {
+		t.Errorf("Unexpected diagnostics for satisfied requirements: %v", diags)
+	}
+}
+
+func TestDiagnosticsMissingRequiredOperand_RequiredUnless(t *testing.T) {
+	statements := map[string]data.MCSStatement{
+		"++TEST": {
+			Name: "++TEST",
+			Type: "MCS",
+			Operands: []data.Operand{
+				{Name: "DISTLIB", Parameter: "ddname", RequiredUnless: "DELETE|NODIST"},
+				{Name: "DELETE"},
+				{Name: "NODIST"},
+			},
+		},
+	}
+	store := &data.Store{Statements: statements, List: []data.MCSStatement{statements["++TEST"]}}
+	p := parser.NewParser(statements)
+	dp := NewProvider(store)
+
+	diags := dp.AnalyzeAST(p.Parse("++TEST .\n++TEST NODIST .\n"))
+	if len(diags) != 1 || diags[0].Code != CodeMissingRequiredOperand || !containsText(diags[0].Message, "unless DELETE or NODIST is specified: DISTLIB") {
+		t.Errorf("Expected DISTLIB to be required in the first statement only, got %v", diags)
+	}
+}
+
 // --- MutuallyExclusive ---
 
 func TestDiagnosticsMutuallyExclusive(t *testing.T) {
@@ -175,7 +285,7 @@ func TestDiagnosticsContentBeyondColumn72(t *testing.T) {
 	input := "++VER(Z038) FMID(HBB7790 HBB7791 HBB7792 HBB7793 HBB7794 HBB7795 HBB7796) .\n"
 	doc := p.Parse(input)
 	text := input
-	diags := dp.AnalyzeASTWithConfigAndText(doc, &Config{ContentBeyondColumn72: true}, text)
+	diags := dp.AnalyzeASTWithConfigAndText(doc, DefaultConfig(), text)
 	t.Logf("Diagnostics: %v", diags)
 
 	if !hasDiagnostic(diags, lsp.SeverityError, "column 72") {
@@ -187,7 +297,7 @@ func TestDiagnosticsNoContentBeyondColumn72_ShortLine(t *testing.T) {
 	_, p, dp := loadRealStore(t)
 	input := "++VER(Z038) FMID(HBB7790) .\n"
 	doc := p.Parse(input)
-	diags := dp.AnalyzeASTWithConfigAndText(doc, &Config{ContentBeyondColumn72: true}, input)
+	diags := dp.AnalyzeASTWithConfigAndText(doc, DefaultConfig(), input)
 
 	if !noDiagnosticWith(diags, "column 72") {
 		t.Errorf("Unexpected column 72 diagnostic for short line: %v", diags)
@@ -200,7 +310,7 @@ func TestDiagnosticsStandaloneCommentBetweenMCS(t *testing.T) {
 	_, p, dp := loadRealStore(t)
 	input := "++VER(Z038) .\n/* standalone comment between statements */\n++VER(Z039) .\n"
 	doc := p.Parse(input)
-	diags := dp.AnalyzeASTWithConfigAndText(doc, &Config{StandaloneCommentBetweenMCS: true}, input)
+	diags := dp.AnalyzeASTWithConfigAndText(doc, DefaultConfig(), input)
 	t.Logf("Diagnostics: %v", diags)
 
 	if !hasDiagnostic(diags, lsp.SeverityError, "comment") {
@@ -213,7 +323,7 @@ func TestDiagnosticsNoStandaloneComment_InlineIsOK(t *testing.T) {
 	// Inline comment on same line as statement — not standalone
 	input := "++VER(Z038) /* inline comment */ .\n"
 	doc := p.Parse(input)
-	diags := dp.AnalyzeASTWithConfigAndText(doc, &Config{StandaloneCommentBetweenMCS: true}, input)
+	diags := dp.AnalyzeASTWithConfigAndText(doc, DefaultConfig(), input)
 
 	if !noDiagnosticWith(diags, "standalone") {
 		t.Errorf("Unexpected standalone comment diagnostic for inline comment: %v", diags)
@@ -260,3 +370,207 @@ func TestDiagnosticsNoUnknownStatement_KnownStmt(t *testing.T) {
 		t.Errorf("Unexpected unknown statement diagnostic for ++PTF: %v", diags)
 	}
 }
+
+// --- InvalidFormat / ReservedSysmodPrefix ---
+
+func TestDiagnosticsInvalidSysmodID(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "++PTF(UA1234-) .\n++USERMOD(LU00001) .\n++APAR(AA1234) .\n"
+	diags := dp.AnalyzeAST(p.Parse(input))
+	t.Logf("Diagnostics: %v", diags)
+
+	if !hasDiagnostic(diags, lsp.SeverityError, "Invalid SYSMOD-ID 'UA1234-'") {
+		t.Error("Expected error for SYSMOD ID with invalid character")
+	}
+	if !hasDiagnostic(diags, lsp.SeverityError, "Invalid SYSMOD-ID 'AA1234'") {
+		t.Error("Expected error for SYSMOD ID with 6 characters")
+	}
+	if !noDiagnosticWith(diags, "LU00001") {
+		t.Errorf("Unexpected diagnostic for valid USERMOD ID: %v", diags)
+	}
+}
+
+func TestDiagnosticsInvalidOperandValues(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "++PTF(UA00001) .\n++VER(Z038) FMID(HBB77C0) PRE(UA00002,UA-0003) SUP(UA0004) .\n"
+	diags := dp.AnalyzeAST(p.Parse(input))
+	t.Logf("Diagnostics: %v", diags)
+
+	var invalid []lsp.Diagnostic
+	for _, d := range diags {
+		if d.Code == CodeInvalidFormat {
+			invalid = append(invalid, d)
+		}
+	}
+	if len(invalid) != 2 {
+		t.Fatalf("Expected 2 invalid values, got %v", invalid)
+	}
+	if !containsText(invalid[0].Message, "'UA-0003' in operand 'PRE'") || invalid[0].Range.Start.Character != 38 {
+		t.Errorf("Expected UA-0003 in PRE to be reported at its position, got %+v", invalid[0])
+	}
+	if !containsText(invalid[1].Message, "'UA0004' in operand 'SUP'") {
+		t.Errorf("Expected UA0004 in SUP to be reported, got %+v", invalid[1])
+	}
+
+	diags = dp.AnalyzeASTWithConfig(p.Parse(input), DefaultConfig().Override(CodeInvalidFormat, SeverityOff))
+	if !noDiagnosticWith(diags, "Invalid value") {
+		t.Errorf("Expected no format diagnostics when disabled, got %v", diags)
+	}
+}
+
+func TestDiagnosticsReservedUsermodPrefix(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "++USERMOD(UZ12345) .\n++PTF(UZ12346) .\n"
+	diags := dp.AnalyzeAST(p.Parse(input))
+	t.Logf("Diagnostics: %v", diags)
+
+	var reserved []lsp.Diagnostic
+	for _, d := range diags {
+		if d.Code == CodeReservedSysmodPrefix {
+			reserved = append(reserved, d)
+		}
+	}
+	if len(reserved) != 1 || reserved[0].Severity != lsp.SeverityWarning || reserved[0].Range.Start.Line != 0 {
+		t.Errorf("Expected one warning for the USERMOD only, got %v", reserved)
+	}
+}
+
+// --- UnsupportedRelease ---
+
+func TestDiagnosticsUnsupportedRelease(t *testing.T) {
+	store, err := data.Load(realSMPEJSON)
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	// Release bounds for a statement, an operand, a keyword value and a sub-operand
+	since := func(name string, update func(stmt *data.MCSStatement)) {
+		stmt := store.Statements[name]
+		stmt.Operands = append([]data.Operand(nil), stmt.Operands...)
+		for i := range stmt.Operands {
+			stmt.Operands[i].Values = append([]data.AllowedValue(nil), stmt.Operands[i].Values...)
+		}
+		update(&stmt)
+		store.Statements[name] = stmt
+	}
+	since("++NULL", func(stmt *data.MCSStatement) { stmt.Since = "V3R7" })
+	since("++USERMOD", func(stmt *data.MCSStatement) { stmt.Operands[findOp(stmt, "DESCRIPTION")].Since = "V3R7" })
+	since("++HOLD", func(stmt *data.MCSStatement) {
+		op := &stmt.Operands[findOp(stmt, "CLASS")]
+		for i := range op.Values {
+			if op.Values[i].Name == "HIPER" {
+				op.Values[i].Since = "V3R7"
+			}
+		}
+	})
+	since("++MAC", func(stmt *data.MCSStatement) {
+		op := &stmt.Operands[findOp(stmt, "FROMDS")]
+		for i := range op.Values {
+			if op.Values[i].Name == "VOL" {
+				op.Values[i].Until = "V3R5"
+			}
+		}
+	})
+	p := parser.NewParser(store.Statements)
+	dp := NewProvider(store)
+
+	input := "++NULL .\n" +
+		"++USERMOD(LU00001) DESCRIPTION(TEST) .\n" +
+		"++HOLD(UA00001) FMID(HXY1100) SYSTEM REASON(ACTION) CLASS(HIPER) .\n" +
+		"++MAC(MYMAC) FROMDS(DSN(MY.DS) VOL(VOL001)) .\n"
+	unsupported := func(target string) []lsp.Diagnostic {
+		config := DefaultConfig()
+		config.TargetRelease = target
+		var result []lsp.Diagnostic
+		for _, d := range dp.AnalyzeASTWithConfig(p.Parse(input), config) {
+			if d.Code == CodeUnsupportedRelease {
+				result = append(result, d)
+			}
+		}
+		return result
+	}
+
+	diags := unsupported("V3R6")
+	t.Logf("Diagnostics: %v", diags)
+	want := []string{
+		"Statement ++NULL is not available in SMP/E V3R6 (since V3R7)",
+		"Operand 'DESCRIPTION' of ++USERMOD is not available in SMP/E V3R6 (since V3R7)",
+		"Value 'HIPER' of operand 'CLASS' is not available in SMP/E V3R6 (since V3R7)",
+		"Value 'VOL' of operand 'FROMDS' is not available in SMP/E V3R6 (until V3R5)",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("Expected %d unsupported release warnings, got %v", len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.HasSuffix(d.Message, want[i]) || d.Severity != lsp.SeverityWarning || d.Range.Start.Line != i {
+			t.Errorf("Expected warning %q on line %d, got %+v", want[i], i, d)
+		}
+	}
+
+	if diags := unsupported("v3r7"); len(diags) != 1 || !containsText(diags[0].Message, "'VOL'") {
+		t.Errorf("Expected only VOL to be unsupported in V3R7, got %v", diags)
+	}
+	if diags := unsupported(""); len(diags) != 0 {
+		t.Errorf("Expected no warnings without a target release, got %v", diags)
+	}
+}
+
+// findOp returns the index of the operand named name in a statement definition
+func findOp(stmt *data.MCSStatement, name string) int {
+	for i, op := range stmt.Operands {
+		if op.PrimaryName() == name {
+			return i
+		}
+	}
+	panic("unknown operand " + name)
+}
+
+// --- Rule registry and severity overrides ---
+
+func TestDiagnosticsCarryRegisteredCodes(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "/* comment */\n++USERMOD(LU00001) REWORK(1) REWORK(2) BOGUS .\n++MAC(TOOLONGNAME) FROMDS(DSN(X) XYZ(1))\n" +
+		"++PTF(UA-1) FMID(HBB7790 HBB7791 HBB7792 HBB7793 HBB7794 HBB7795 HBB7796 HBB7797) .\n++FOO(X) .\n"
+	diags := dp.AnalyzeASTWithConfigAndText(p.Parse(input), DefaultConfig(), input)
+	if len(diags) < 5 {
+		t.Fatalf("Expected several diagnostics, got %v", diags)
+	}
+	for _, d := range diags {
+		rule, ok := LookupRule(d.Code)
+		if !ok {
+			t.Errorf("Diagnostic without registered code: %+v", d)
+			continue
+		}
+		if d.Severity != rule.Severity || d.CodeDescription == nil || d.CodeDescription.Href != rule.DocsURL() {
+			t.Errorf("Expected default severity and docs link of %s, got %+v", rule.Code, d)
+		}
+	}
+}
+
+func TestDiagnosticsSeverityOverride(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	input := "++USERMOD(LU00001) REWORK(1) REWORK(2) BOGUS .\n"
+	config := &Config{Severities: map[string]Severity{
+		CodeDuplicateOperand: SeverityError,
+		CodeUnknownOperand:   SeverityOff,
+	}}
+	diags := dp.AnalyzeASTWithConfig(p.Parse(input), config)
+	t.Logf("Diagnostics: %v", diags)
+
+	if !hasDiagnostic(diags, lsp.SeverityError, "🔴 Duplicate operand 'REWORK'") {
+		t.Errorf("Expected duplicate operand to be raised to an error, got %v", diags)
+	}
+	if !noDiagnosticWith(diags, "BOGUS") {
+		t.Errorf("Expected unknown operand to be turned off, got %v", diags)
+	}
+}
+
+func TestCheckSeverities(t *testing.T) {
+	errs := CheckSeverities(map[string]Severity{
+		CodeUnknownOperand:   "Error",
+		CodeDuplicateOperand: "fatal",
+		"no_such_rule":       SeverityOff,
+	})
+	if len(errs) != 2 || !containsText(errs[0].Error(), "fatal") || !containsText(errs[1].Error(), "no_such_rule") {
+		t.Errorf("Expected an invalid severity and an unknown code, got %v", errs)
+	}
+}
@@ -0,0 +1,208 @@
+package diagnostics
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// updateParameters lists the parameters of each IEBUPDTE operation
+var updateParameters = map[string][]string{
+	"ADD":    {"NAME", "LIST", "SSI", "SEQFLD", "NEW", "MEMBER", "COLUMN", "LEVEL", "SOURCE", "TOTAL"},
+	"CHANGE": {"NAME", "LIST", "UPDATE", "SSI", "SEQFLD", "NEW", "MEMBER", "COLUMN", "LEVEL", "SOURCE", "TOTAL"},
+	"REPL":   {"NAME", "LIST", "SSI", "SEQFLD", "NEW", "MEMBER", "COLUMN", "LEVEL", "SOURCE", "TOTAL"},
+	"REPRO":  {"NAME", "LIST", "SSI", "SEQFLD", "NEW", "MEMBER", "LEVEL", "SOURCE", "TOTAL"},
+	"NUMBER": {"SEQ1", "SEQ2", "NEW1", "INCR", "INSERT"},
+	"DELETE": {"SEQ1", "SEQ2"},
+	"ALIAS":  {"NAME"},
+}
+
+// parseSequence parses a sequence number of 1 to 8 digits
+func parseSequence(value string) (int, bool) {
+	if len(value) == 0 || len(value) > 8 {
+		return 0, false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(value)
+	return n, err == nil
+}
+
+// updateChecker checks the IEBUPDTE input of one ++MACUPD or ++SRCUPD statement
+type updateChecker struct {
+	stmt        *parser.Node
+	config      *Config
+	diagnostics []lsp.Diagnostic
+
+	member    *parser.UpdateControl // ADD, CHANGE, REPL or REPRO being processed
+	last      int                   // Highest sequence number of the member so far, -1 if none
+	inserting bool                  // Records follow ./ NUMBER INSERT=YES and are numbered by it
+}
+
+// report adds a diagnostic if its rule is enabled
+func (c *updateChecker) report(pos parser.Position, code, format string, args ...any) {
+	if c.config.Enabled(code) {
+		c.diagnostics = append(c.diagnostics, newRangeDiagnostic(jclRange(pos), code, c.config, fmt.Sprintf(format, args...)))
+	}
+}
+
+// checkUpdate checks the IEBUPDTE input of ++MACUPD and ++SRCUPD: syntax errors found by
+// the parser, the parameters of the control statements, the member names and the order of
+// the sequence numbers in columns 73-80
+func (p *Provider) checkUpdate(stmt *parser.Node, config *Config) []lsp.Diagnostic {
+	update := stmt.Update
+	if update == nil {
+		return nil
+	}
+	c := &updateChecker{stmt: stmt, config: config, last: -1}
+	for _, e := range update.Errors {
+		c.report(e.Position, CodeUpdateSyntax, "%s", e.Message)
+	}
+	for _, control := range update.Controls {
+		c.checkControl(control)
+		c.checkRecords(control)
+	}
+	return c.diagnostics
+}
+
+// checkControl checks the parameters of a control statement and tracks the member updated
+func (c *updateChecker) checkControl(control *parser.UpdateControl) {
+	allowed, known := updateParameters[control.Operation]
+	if !known && control.Operation != "ENDUP" && control.Operation != "LABEL" {
+		// Unknown operations are reported by the parser
+		return
+	}
+	for _, param := range control.Parameters {
+		if !slices.Contains(allowed, param.Keyword) {
+			c.report(param.KeywordPos, CodeUpdateSyntax, "Parameter %s is not valid on ./ %s", param.Keyword, control.Operation)
+		}
+	}
+	c.inserting = false
+
+	switch {
+	case control.IsMember():
+		c.member, c.last = control, -1
+		name := control.Param("NAME")
+		if name == nil {
+			c.report(control.OpPos, CodeUpdateSyntax, "./ %s requires NAME=member", control.Operation)
+			return
+		}
+		if element := statementParameter(c.stmt); element != nil && element.Value != "" && name.Value != element.Value {
+			c.report(name.ValuePos, CodeUpdateNameMismatch, "./ %s names member %s, but %s updates %s",
+				control.Operation, name.Value, c.stmt.Name, element.Value)
+		}
+	case control.Operation == "DELETE":
+		if c.member == nil || c.member.Operation != "CHANGE" {
+			c.report(control.OpPos, CodeUpdateSyntax, "./ DELETE is only valid after ./ CHANGE")
+		}
+		if first, last, ok := c.sequenceRange(control, false); ok {
+			c.order(control.Param("SEQ1").ValuePos, first)
+			c.last = last
+		}
+	case control.Operation == "NUMBER":
+		if c.member == nil {
+			c.report(control.OpPos, CodeUpdateSyntax, "./ NUMBER is only valid after ./ ADD, ./ CHANGE, ./ REPL or ./ REPRO")
+		}
+		first, last, ok := c.sequenceRange(control, true)
+		new1, incr := c.number(control, "NEW1"), c.number(control, "INCR")
+		if incr == 0 {
+			c.report(control.Param("INCR").ValuePos, CodeUpdateSyntax, "INCR must be greater than 0")
+		}
+		insert := control.Param("INSERT")
+		if insert != nil && insert.Value != "YES" {
+			c.report(insert.ValuePos, CodeUpdateSyntax, "INSERT must be YES")
+		}
+		if insert != nil {
+			// Inserted records are numbered from NEW1 in steps of INCR
+			c.inserting = true
+			if ok && new1 >= 0 && incr > 0 {
+				c.order(control.Param("NEW1").ValuePos, new1)
+				c.last = new1 + incr*max(len(control.Records)-1, 0)
+			}
+		} else if ok && control.Param("SEQ1").Value != "ALL" {
+			c.order(control.Param("SEQ1").ValuePos, first)
+			c.last = last
+		}
+	case control.Operation == "ENDUP":
+		c.member = nil
+	}
+}
+
+// number returns the value of a required numeric parameter, or -1 if it is missing or
+// invalid
+func (c *updateChecker) number(control *parser.UpdateControl, keyword string) int {
+	param := control.Param(keyword)
+	if param == nil {
+		c.report(control.OpPos, CodeUpdateSyntax, "./ %s requires %s", control.Operation, keyword)
+		return -1
+	}
+	n, ok := parseSequence(param.Value)
+	if !ok {
+		c.report(param.ValuePos, CodeUpdateSyntax, "%s must be a number of 1 to 8 digits, got %s", keyword, param.Value)
+		return -1
+	}
+	return n
+}
+
+// sequenceRange returns the records SEQ1 to SEQ2 (SEQ1 if omitted) of DELETE and NUMBER.
+// SEQ1=ALL is accepted for NUMBER.
+func (c *updateChecker) sequenceRange(control *parser.UpdateControl, allowAll bool) (int, int, bool) {
+	seq1 := control.Param("SEQ1")
+	if allowAll && seq1 != nil && seq1.Value == "ALL" {
+		return 0, 0, true
+	}
+	first := c.number(control, "SEQ1")
+	last := first
+	if seq2 := control.Param("SEQ2"); seq2 != nil {
+		last = c.number(control, "SEQ2")
+	}
+	if first < 0 || last < 0 {
+		return 0, 0, false
+	}
+	if first > last {
+		c.report(control.Param("SEQ2").ValuePos, CodeUpdateSequence, "SEQ2=%s is lower than SEQ1=%s", control.Param("SEQ2").Value, seq1.Value)
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// order reports a sequence number that is not higher than the previous one of the member
+func (c *updateChecker) order(pos parser.Position, seq int) {
+	if seq <= c.last {
+		// The message names no line, so baselines and fingerprints survive edits above it
+		c.report(pos, CodeUpdateSequence, "Sequence number %08d is not higher than the previous %08d; IEBUPDTE statements must be in ascending order",
+			seq, c.last)
+		return
+	}
+	c.last = seq
+}
+
+// checkRecords checks the sequence numbers of the data records following a control
+// statement. Records of ./ CHANGE are inserted or replaced by their sequence number and
+// must have one, unless ./ NUMBER INSERT=YES numbers them.
+func (c *updateChecker) checkRecords(control *parser.UpdateControl) {
+	if c.member == nil || c.inserting {
+		return
+	}
+	for _, record := range control.Records {
+		if record.Sequence == "" {
+			if c.member.Operation == "CHANGE" {
+				c.report(parser.Position{Line: record.Line, Character: 0, Length: 1}, CodeUpdateSequence,
+					"Record has no sequence number in columns 73-80; ./ CHANGE inserts and replaces records by sequence number")
+			}
+			continue
+		}
+		seq, ok := parseSequence(record.Sequence)
+		if !ok {
+			c.report(record.SequencePos, CodeUpdateSequence, "Sequence number %s is not numeric", record.Sequence)
+			continue
+		}
+		c.order(record.SequencePos, seq)
+	}
+}
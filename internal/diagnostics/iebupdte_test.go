@@ -0,0 +1,101 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered pads an IEBUPDTE record to column 72 and appends a sequence number
+func numbered(record, number string) string {
+	return record + strings.Repeat(" ", 72-len(record)) + number + "\n"
+}
+
+func TestUpdateDiagnostics(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++MACUPD(MYMAC) DISTLIB(AMACLIB) .\n" +
+		"./ CHANGE NAME=OTHER\n" +
+		numbered("         MVC   A,B", "00000300") +
+		numbered("         MVC   C,D", "00000200") +
+		"         LA    R1,0\n" +
+		"./ DELETE SEQ1=00000500,SEQ2=00000400\n" +
+		"./ NUMBER SEQ1=00000600,NEW1=00000610,INCR=0,INSERT=YES\n" +
+		"         LA    R2,0\n" +
+		"./ ENDUP FOO=BAR\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+
+	codes := make(map[string]bool)
+	for _, d := range diags {
+		codes[fmt.Sprintf("%d:%s", d.Range.Start.Line, d.Code)] = true
+	}
+	for _, want := range []string{
+		"3:" + CodeUpdateNameMismatch, // OTHER instead of MYMAC
+		"5:" + CodeUpdateSequence,     // 00000200 after 00000300
+		"6:" + CodeUpdateSequence,     // No sequence number
+		"7:" + CodeUpdateSequence,     // SEQ2 lower than SEQ1
+		"8:" + CodeUpdateSyntax,       // INCR=0
+		"10:" + CodeUpdateSyntax,      // FOO on ENDUP
+	} {
+		if !codes[want] {
+			t.Errorf("Expected %s, got %v", want, diags)
+		}
+	}
+	if len(diags) != 6 {
+		t.Errorf("Expected 6 diagnostics, got %v", diags)
+	}
+}
+
+func TestUpdateDiagnosticsValid(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++SRCUPD(MYSRC) DISTLIB(ASRCLIB) .\n" +
+		"./ CHANGE NAME=MYSRC\n" +
+		numbered("         MVC   A,B", "00000150") +
+		"./ DELETE SEQ1=00000200,SEQ2=00000300\n" +
+		"./ NUMBER SEQ1=00000400,NEW1=00000410,INCR=10,INSERT=YES\n" +
+		"         LA    R1,0\n" +
+		"         LA    R2,0\n" +
+		numbered("         BR    R14", "00000500")
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestUpdateDiagnosticsOrderAfterOutOfOrderRecord(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++MACUPD(MYMAC) DISTLIB(AMACLIB) .\n" +
+		"./ CHANGE NAME=MYMAC\n" +
+		numbered("         MVC   A,B", "00000100") +
+		numbered("         MVC   C,D", "00000300") +
+		numbered("         MVC   E,F", "00000200") +
+		numbered("         MVC   G,H", "00000250")
+	messages := func(text string) []string {
+		var messages []string
+		for _, d := range dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text) {
+			messages = append(messages, d.Message)
+		}
+		return messages
+	}
+
+	// Both records compare against 00000300
+	got := messages(text)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", got)
+	}
+	for _, m := range got {
+		if !strings.Contains(m, "than the previous 00000300;") {
+			t.Errorf("Expected the message to cite 00000300, got %q", m)
+		}
+	}
+
+	// Lines inserted above do not change the messages
+	if shifted := messages("\n\n" + text); strings.Join(shifted, "\n") != strings.Join(got, "\n") {
+		t.Errorf("Messages changed with the line numbers:\n%v\n%v", got, shifted)
+	}
+}
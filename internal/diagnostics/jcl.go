@@ -0,0 +1,159 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+const (
+	jclNameLength    = 8  // Names, members and data set name qualifiers
+	jclDataSetLength = 44 // Data set names
+)
+
+// jclRange converts a position in inline JCL to a range
+func jclRange(pos parser.Position) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: pos.Line, Character: pos.Character},
+		End:   lsp.Position{Line: pos.Line, Character: pos.Character + pos.Length},
+	}
+}
+
+// checkJCL checks the inline JCL of a ++JCLIN statement: syntax errors found by the
+// parser, DD statements without a data set and names SMP/E cannot process
+func (p *Provider) checkJCL(stmt *parser.Node, config *Config) []lsp.Diagnostic {
+	jcl := stmt.JCL
+	if jcl == nil {
+		return nil
+	}
+	var diagnostics []lsp.Diagnostic
+
+	if config.Enabled(CodeJCLSyntax) {
+		for _, e := range jcl.Errors {
+			diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(e.Position), CodeJCLSyntax, config, e.Message))
+		}
+	}
+
+	for _, s := range jcl.Statements {
+		if config.Enabled(CodeJCLMissingDSN) && s.Operation == "DD" && !jclHasDataSet(s) {
+			pos, subject := s.NamePos, "DD statement "+s.Name
+			if s.Name == "" {
+				pos, subject = s.OpPos, "Concatenated DD statement"
+			}
+			diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(pos), CodeJCLMissingDSN, config,
+				fmt.Sprintf("%s has no DSN; SMP/E cannot tell which library it refers to", subject)))
+		}
+		if config.Enabled(CodeJCLNameTooLong) {
+			diagnostics = append(diagnostics, checkJCLNames(s, config)...)
+		}
+	}
+	return diagnostics
+}
+
+// jclHasDataSet reports whether a DD statement names a data set or needs none: in-stream
+// data, SYSOUT, dummy, referring to another DD, UNIX files, subsystems and temporary data
+// sets allocated with SPACE
+func jclHasDataSet(s *parser.JCLStatement) bool {
+	if first := s.Positional(); first != nil {
+		switch first.Value {
+		case "*", "DATA", "DUMMY":
+			return true
+		}
+	}
+	for _, keyword := range []string{"DSN", "DSNAME", "SYSOUT", "DDNAME", "PATH", "SUBSYS", "SPACE"} {
+		if s.Param(keyword) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkJCLNames reports names of a JCL statement and of its in-stream control statements
+// that are too long
+func checkJCLNames(s *parser.JCLStatement, config *Config) []lsp.Diagnostic {
+	var diagnostics []lsp.Diagnostic
+	tooLong := func(kind, name string, pos parser.Position) {
+		diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(pos), CodeJCLNameTooLong, config,
+			fmt.Sprintf("%s %s is longer than %d characters", kind, name, jclNameLength)))
+	}
+
+	// The name of a DD overriding a procedure step is procstep.ddname
+	for _, part := range strings.Split(s.Name, ".") {
+		if len(part) > jclNameLength {
+			tooLong("Name", part, s.NamePos)
+		}
+	}
+
+	// value returns the value of a parameter unless it refers back to another statement
+	value := func(param *parser.JCLParameter) (string, parser.Position, bool) {
+		if param == nil || len(param.ValueParts) == 0 || strings.HasPrefix(param.Value, "*.") {
+			return "", parser.Position{}, false
+		}
+		return param.Value, param.ValueParts[0], true
+	}
+	switch s.Operation {
+	case "EXEC":
+		if name, pos, ok := value(s.Param("PGM")); ok && len(name) > jclNameLength {
+			tooLong("Program name", name, pos)
+		}
+		proc := s.Positional()
+		if proc == nil {
+			proc = s.Param("PROC")
+		}
+		if name, pos, ok := value(proc); ok && len(name) > jclNameLength {
+			tooLong("Procedure name", name, pos)
+		}
+	case "DD":
+		if name, pos, ok := value(s.Param("DDNAME")); ok && len(name) > jclNameLength {
+			tooLong("DD name", name, pos)
+		}
+		if dsn := s.DSN(); dsn != nil {
+			diagnostics = append(diagnostics, checkJCLDataSetName(dsn, config)...)
+		}
+	}
+
+	for _, control := range s.Data {
+		for _, name := range control.Names {
+			if len(name.Value) <= jclNameLength {
+				continue
+			}
+			switch name.Kind {
+			case parser.JCLNameLoadModule:
+				tooLong("Load module name", name.Value, name.Position)
+			case parser.JCLNameMember:
+				tooLong("Member name", name.Value, name.Position)
+			case parser.JCLNameAlias:
+				tooLong("Alias name", name.Value, name.Position)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkJCLDataSetName reports data set names longer than 44 characters and qualifiers and
+// members longer than 8. Temporary data sets (&&name) and symbols are not checked.
+func checkJCLDataSetName(dsn *parser.JCLParameter, config *Config) []lsp.Diagnostic {
+	name := dsn.DataSetName()
+	if strings.HasPrefix(name, "*.") || strings.HasPrefix(name, "&") || strings.HasPrefix(name, "'") || len(dsn.ValueParts) == 0 {
+		return nil
+	}
+	var diagnostics []lsp.Diagnostic
+	pos := dsn.ValueParts[0]
+	if len(name) > jclDataSetLength {
+		diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(pos), CodeJCLNameTooLong, config,
+			fmt.Sprintf("Data set name %s is longer than %d characters", name, jclDataSetLength)))
+	}
+	for _, qualifier := range strings.Split(name, ".") {
+		if len(qualifier) > jclNameLength && !strings.Contains(qualifier, "&") {
+			diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(pos), CodeJCLNameTooLong, config,
+				fmt.Sprintf("Qualifier %s of data set name %s is longer than %d characters", qualifier, name, jclNameLength)))
+		}
+	}
+	if len(dsn.Member) > jclNameLength {
+		diagnostics = append(diagnostics, newRangeDiagnostic(jclRange(dsn.MemberPos), CodeJCLNameTooLong, config,
+			fmt.Sprintf("Member name %s is longer than %d characters", dsn.Member, jclNameLength)))
+	}
+	return diagnostics
+}
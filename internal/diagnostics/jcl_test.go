@@ -0,0 +1,84 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+func TestJCLDiagnostics(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++JCLIN .\n" +
+		"//LINKEDIT1 EXEC PGM=IEWL\n" +
+		"//SYSLMOD  DD  DSN=SYS1.LINKLIB,\n" +
+		"//SYSUT1   DD  SPACE=(CYL,1)\n" +
+		"//AOBJ     DD  DISP=SHR\n" +
+		"//SYSPRINT DD  SYSOUT=*\n" +
+		"//OBJ      DD  DSN=SYS1.OBJECTLIBRARY(MODULE123)\n" +
+		"//SYSLIN   DD  *\n" +
+		"  INCLUDE AOBJ(MOD1)\n" +
+		"  ALIAS LONGALIAS1,ALIAS2\n" +
+		"  NAME LOADMODULE1(R)\n" +
+		"/*\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+
+	codes := codesByLine(diags)
+	for _, want := range []string{
+		"3:" + CodeJCLNameTooLong, // LINKEDIT1
+		"4:" + CodeJCLSyntax,      // Continuation missing
+		"6:" + CodeJCLMissingDSN,  // AOBJ
+		"8:" + CodeJCLNameTooLong, // OBJECTLIBRARY and MODULE123
+	} {
+		if !codes[want] {
+			t.Errorf("Expected %s, got %v", want, diags)
+		}
+	}
+	for _, unwanted := range []string{"5:" + CodeJCLMissingDSN, "7:" + CodeJCLMissingDSN, "9:" + CodeJCLMissingDSN} {
+		if codes[unwanted] {
+			t.Errorf("Unexpected %s", unwanted)
+		}
+	}
+
+	if !hasDiagnostic(diags, lsp.SeverityError, "Alias name LONGALIAS1 is longer than 8 characters") {
+		t.Errorf("Expected alias LONGALIAS1 to be too long, got %v", diags)
+	}
+
+	// LINKEDIT1, OBJECTLIBRARY, MODULE123, LONGALIAS1 and LOADMODULE1
+	tooLong := 0
+	for _, d := range diags {
+		if d.Code == CodeJCLNameTooLong {
+			tooLong++
+		}
+	}
+	if tooLong != 5 {
+		t.Errorf("Expected 5 names too long, got %d: %v", tooLong, diags)
+	}
+}
+
+func TestJCLDiagnosticsDisabled(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++JCLIN .\n//AOBJ DD DISP=SHR\n"
+	config := &Config{Severities: map[string]Severity{CodeJCLMissingDSN: SeverityOff}}
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, config, text)
+	if !noDiagnosticWith(diags, "has no DSN") {
+		t.Errorf("Expected jcl_missing_dsn to be disabled, got %v", diags)
+	}
+}
+
+func TestJCLMissingDSNInConcatenation(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++JCLIN .\n//SYSLIB DD DSN=SYS1.MACLIB,DISP=SHR\n//       DD DISP=SHR\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+	for _, d := range diags {
+		if d.Code == CodeJCLMissingDSN {
+			if d.Range.Start.Line != 2 || !strings.Contains(d.Message, "Concatenated DD statement has no DSN") {
+				t.Errorf("Unexpected diagnostic %v", d)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected jcl_missing_dsn for the concatenated DD, got %v", diags)
+}
@@ -0,0 +1,83 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// checkPatterns validates the statement parameter and operand values against the
+// patterns defined in smpe.json
+func (p *Provider) checkPatterns(stmt *parser.Node, config *Config) []lsp.Diagnostic {
+	var diagnostics []lsp.Diagnostic
+	def := stmt.StatementDef
+
+	// Statement parameter, e.g. the SYSMOD ID of ++USERMOD(LU00001)
+	if param := statementParameter(stmt); param != nil && (def.Pattern != "" || def.ReservedPattern != "") {
+		value := strings.TrimSpace(param.Value)
+		// Values exceeding the length are already reported by the length check
+		tooLong := def.Length > 0 && len(value) > def.Length
+		if config.Enabled(CodeInvalidFormat) && !tooLong && !data.MatchPattern(def.Pattern, value) {
+			diagnostics = append(diagnostics, p.newDiagnostic(param, CodeInvalidFormat, config,
+				fmt.Sprintf("Invalid %s '%s': expected %s", def.Parameter, value, def.PatternDescription)))
+		} else if config.Enabled(CodeReservedSysmodPrefix) && def.ReservedPattern != "" && data.MatchPattern(def.ReservedPattern, value) {
+			diagnostics = append(diagnostics, p.newDiagnostic(param, CodeReservedSysmodPrefix, config,
+				fmt.Sprintf("%s '%s' of %s is reserved: %s", def.Parameter, value, stmt.Name, def.ReservedDescription)))
+		}
+	}
+
+	if !config.Enabled(CodeInvalidFormat) {
+		return diagnostics
+	}
+
+	// Operand values, e.g. each SYSMOD ID in PRE(UA00001,UA00002)
+	for _, child := range stmt.Children {
+		if child.Type != parser.NodeTypeOperand || child.OperandDef == nil || child.OperandDef.Pattern == "" {
+			continue
+		}
+		op := child.OperandDef
+		for _, item := range operandValues(child) {
+			value := strings.TrimSpace(item.Value)
+			if value == "" || (op.Length > 0 && len(value) > op.Length) || data.MatchPattern(op.Pattern, value) {
+				continue
+			}
+			diagnostics = append(diagnostics, p.newDiagnostic(item, CodeInvalidFormat, config,
+				fmt.Sprintf("Invalid value '%s' in operand '%s': expected %s", value, child.Name, op.PatternDescription)))
+		}
+	}
+
+	return diagnostics
+}
+
+// statementParameter returns the parameter node of a statement, or nil
+func statementParameter(stmt *parser.Node) *parser.Node {
+	for _, child := range stmt.Children {
+		if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
+			return child
+		}
+	}
+	return nil
+}
+
+// operandValues returns the individual values of an operand parameter
+func operandValues(operand *parser.Node) []*parser.Node {
+	var values []*parser.Node
+	for _, param := range operand.Children {
+		if param.Type != parser.NodeTypeParameter {
+			continue
+		}
+		if len(param.Children) == 0 {
+			values = append(values, param)
+			continue
+		}
+		for _, item := range param.Children {
+			if item.Type == parser.NodeTypeParameter {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
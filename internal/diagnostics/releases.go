@@ -0,0 +1,73 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// checkReleases reports statements, operands and operand values that do not exist in
+// the SMP/E release configured as target release
+func (p *Provider) checkReleases(stmt *parser.Node, config *Config) []lsp.Diagnostic {
+	var diagnostics []lsp.Diagnostic
+	target := config.TargetRelease
+
+	unsupported := func(node *parser.Node, what, since, until string) {
+		diagnostics = append(diagnostics, p.newDiagnostic(node, CodeUnsupportedRelease, config,
+			fmt.Sprintf("%s is not available in SMP/E %s (%s)", what, strings.ToUpper(target), data.Availability(since, until))))
+	}
+
+	def := stmt.StatementDef
+	if !def.InRelease(target) {
+		unsupported(stmt, "Statement "+stmt.Name, def.Since, def.Until)
+		return diagnostics
+	}
+
+	for _, child := range stmt.Children {
+		if child.Type != parser.NodeTypeOperand || child.OperandDef == nil {
+			continue
+		}
+		op := child.OperandDef
+		if !op.InRelease(target) {
+			unsupported(child, fmt.Sprintf("Operand '%s' of %s", child.Name, stmt.Name), op.Since, op.Until)
+			continue
+		}
+		if len(op.Values) == 0 {
+			continue
+		}
+
+		// Keyword values, e.g. SYSTEM in REASON(SYSTEM), and sub-operands, e.g. DSN in FROMDS(DSN(...))
+		nodes := operandValues(child)
+		for _, sub := range child.Children {
+			if sub.Type == parser.NodeTypeOperand {
+				nodes = append(nodes, sub)
+			}
+		}
+		for _, node := range nodes {
+			name := strings.TrimSpace(node.Value)
+			if node.Type == parser.NodeTypeOperand {
+				name = node.Name
+			}
+			if value, ok := findValue(op.Values, name); ok && !value.InRelease(target) {
+				unsupported(node, fmt.Sprintf("Value '%s' of operand '%s'", name, child.Name), value.Since, value.Until)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// findValue returns the value definition named name or one of its aliases
+func findValue(values []data.AllowedValue, name string) (data.AllowedValue, bool) {
+	for _, value := range values {
+		for _, alias := range strings.Split(value.Name, "|") {
+			if strings.TrimSpace(alias) == name {
+				return value, true
+			}
+		}
+	}
+	return data.AllowedValue{}, false
+}
@@ -0,0 +1,105 @@
+package diagnostics
+
+import (
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// checkRequirements evaluates the declarative requirement rules of a statement definition:
+// the required, required_if and required_unless attributes of its operands and the
+// required operands of its active modes. Operands in a required_group are checked separately.
+func (p *Provider) checkRequirements(stmt *parser.Node, def *data.MCSStatement, operands map[string]*parser.Node, config *Config) []lsp.Diagnostic {
+	var diagnostics []lsp.Diagnostic
+
+	missing := func(condition, name string) {
+		diagnostics = append(diagnostics, withFixData(p.newDiagnostic(stmt, CodeMissingRequiredOperand, config,
+			"Missing required operand"+condition+": "+name), FixData{Operands: []string{name}}))
+	}
+
+	if config.Enabled(CodeMissingRequiredOperand) {
+		for _, op := range def.Operands {
+			if op.RequiredGroup || anyPresent(def, op.Name, operands) {
+				continue
+			}
+			switch {
+			case op.Required:
+				missing("", op.PrimaryName())
+			case op.RequiredIf != "" && anyPresent(def, op.RequiredIf, operands):
+				missing(" when "+presentName(def, op.RequiredIf, operands)+" is specified", op.PrimaryName())
+			case op.RequiredUnless != "" && !anyPresent(def, op.RequiredUnless, operands):
+				missing(" unless "+strings.ReplaceAll(op.RequiredUnless, "|", " or ")+" is specified", op.PrimaryName())
+			}
+		}
+	}
+
+	for _, mode := range activeModes(def, operands) {
+		if config.Enabled(CodeMissingRequiredOperand) {
+			for _, name := range mode.Required {
+				if !anyPresent(def, name, operands) {
+					missing(" in "+mode.Name+" mode", name)
+				}
+			}
+		}
+		if config.Enabled(CodeRequiredGroup) {
+			for _, group := range mode.RequiredOneOf {
+				if anyPresent(def, strings.Join(group, "|"), operands) {
+					continue
+				}
+				diagnostics = append(diagnostics, withFixData(p.newDiagnostic(stmt, CodeRequiredGroup, config,
+					"One of the following operands must be specified in "+mode.Name+" mode: "+strings.Join(group, ", ")), FixData{Operands: group}))
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// activeModes returns the modes selected by the specified operands, or the default modes
+// (without When) if no mode is selected
+func activeModes(def *data.MCSStatement, operands map[string]*parser.Node) []data.Mode {
+	var selected, defaults []data.Mode
+	for _, mode := range def.Modes {
+		if mode.When == "" {
+			defaults = append(defaults, mode)
+		} else if anyPresent(def, mode.When, operands) {
+			selected = append(selected, mode)
+		}
+	}
+	if len(selected) > 0 {
+		return selected
+	}
+	return defaults
+}
+
+// anyPresent checks if any of the operands (separated by |) is specified, under its
+// name or one of its aliases
+func anyPresent(def *data.MCSStatement, names string, operands map[string]*parser.Node) bool {
+	for _, name := range strings.Split(names, "|") {
+		op, ok := def.Operand(name)
+		if !ok {
+			if operands[name] != nil {
+				return true
+			}
+			continue
+		}
+		for _, alias := range strings.Split(op.Name, "|") {
+			if operands[alias] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// presentName returns the first of the operands (separated by |) that is specified
+func presentName(def *data.MCSStatement, names string, operands map[string]*parser.Node) string {
+	for _, name := range strings.Split(names, "|") {
+		if anyPresent(def, name, operands) {
+			return name
+		}
+	}
+	return names
+}
@@ -0,0 +1,230 @@
+package diagnostics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/index"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// Directives that suppress diagnostics when they appear in an MCS comment. They are followed
+// by the codes to suppress (all if none are given), optionally followed by "--" and a reason:
+//
+//	++USERMOD(LU00001) /* smpe-lint-disable-next-statement unknown_operand -- site operand */ .
+const (
+	// DisableFileDirective suppresses diagnostics in the whole file
+	DisableFileDirective = "smpe-lint-disable-file"
+	// DisableNextStatementDirective suppresses diagnostics in the statement following the comment
+	DisableNextStatementDirective = "smpe-lint-disable-next-statement"
+	// DisableDirective suppresses diagnostics from the comment up to EnableDirective or the end of the file
+	DisableDirective = "smpe-lint-disable"
+	// EnableDirective ends the suppression of the codes it lists, or of all codes
+	EnableDirective = "smpe-lint-enable"
+)
+
+// endOfFile is a position after all content
+var endOfFile = lsp.Position{Line: math.MaxInt32}
+
+// suppression suppresses the diagnostics of one code (all codes if empty) within a range
+type suppression struct {
+	comment    *parser.Node
+	code       string
+	start, end lsp.Position // end is exclusive
+	used       bool
+}
+
+// covers checks if the suppression applies to a diagnostic
+func (s *suppression) covers(d lsp.Diagnostic) bool {
+	return (s.code == "" || s.code == d.Code) && !before(d.Range.Start, s.start) && before(d.Range.Start, s.end)
+}
+
+// suppressions holds the suppressions of a document in comment order
+type suppressions []*suppression
+
+// parseSuppressions collects the suppression directives in the comments of a document
+func parseSuppressions(doc *parser.Document) suppressions {
+	var result suppressions
+	if doc == nil {
+		return result
+	}
+
+	add := func(comment *parser.Node, codes []string, start, end lsp.Position) {
+		if len(codes) == 0 {
+			codes = []string{""}
+		}
+		for _, code := range codes {
+			result = append(result, &suppression{comment: comment, code: code, start: start, end: end})
+		}
+	}
+
+	var open suppressions // DisableDirective suppressions not yet ended
+	for _, comment := range doc.Comments {
+		directive, codes, ok := parseDirective(comment.Value)
+		if !ok {
+			continue
+		}
+		at := lsp.Position{Line: comment.Position.Line, Character: comment.Position.Character}
+
+		switch directive {
+		case DisableFileDirective:
+			add(comment, codes, lsp.Position{}, endOfFile)
+		case DisableNextStatementDirective:
+			start, end := nextStatement(doc, at)
+			add(comment, codes, start, end)
+		case DisableDirective:
+			first := len(result)
+			add(comment, codes, at, endOfFile)
+			open = append(open, result[first:]...)
+		case EnableDirective:
+			remaining := open[:0]
+			for _, s := range open {
+				if len(codes) == 0 || containsCode(codes, s.code) {
+					s.end = at
+				} else {
+					remaining = append(remaining, s)
+				}
+			}
+			open = remaining
+		}
+	}
+	return result
+}
+
+// parseDirective returns the directive in a comment and the codes following it
+func parseDirective(comment string) (string, []string, bool) {
+	i := strings.Index(comment, "smpe-lint-")
+	if i < 0 {
+		return "", nil, false
+	}
+	rest := strings.TrimSuffix(strings.TrimSpace(comment[i:]), "*/")
+	rest, _, _ = strings.Cut(rest, "--")
+
+	fields := strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	switch fields[0] {
+	case DisableFileDirective, DisableNextStatementDirective, DisableDirective, EnableDirective:
+		return fields[0], fields[1:], true
+	}
+	return "", nil, false
+}
+
+// nextStatement returns the range of the first statement starting after a position, up to
+// the start of the statement following it
+func nextStatement(doc *parser.Document, after lsp.Position) (lsp.Position, lsp.Position) {
+	for i, stmt := range doc.Statements {
+		start := lsp.Position{Line: stmt.Position.Line, Character: stmt.Position.Character}
+		if !before(after, start) {
+			continue
+		}
+		end := endOfFile
+		if i+1 < len(doc.Statements) {
+			next := doc.Statements[i+1].Position
+			end = lsp.Position{Line: next.Line, Character: next.Character}
+		}
+		return start, end
+	}
+	return endOfFile, endOfFile
+}
+
+// apply removes the suppressed diagnostics and marks the suppressions that were used
+func (ss suppressions) apply(diagnostics []lsp.Diagnostic) []lsp.Diagnostic {
+	if len(ss) == 0 {
+		return diagnostics
+	}
+	kept := make([]lsp.Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		suppressed := false
+		for _, s := range ss {
+			if s.covers(d) {
+				s.used = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// unused reports the suppressions that did not suppress any diagnostic, one diagnostic per
+// comment. Codes of rules that are turned off are not reported, as they cannot occur.
+func (p *Provider) unused(ss suppressions, config *Config) []lsp.Diagnostic {
+	var diagnostics []lsp.Diagnostic
+	for i := 0; i < len(ss); {
+		comment := ss[i].comment
+		var unused, unknown []string
+		all := false
+		for ; i < len(ss) && ss[i].comment == comment; i++ {
+			s := ss[i]
+			switch {
+			case s.used:
+			case s.code == "":
+				all = true
+			case !knownCode(s.code):
+				unknown = append(unknown, s.code)
+			case config.Enabled(s.code):
+				unused = append(unused, s.code)
+			}
+		}
+
+		var message string
+		switch {
+		case len(unknown) > 0:
+			message = fmt.Sprintf("Unknown diagnostic code %s in suppression comment", strings.Join(unknown, ", "))
+		case all:
+			message = "Suppression comment does not suppress any diagnostic"
+		case len(unused) > 0:
+			message = fmt.Sprintf("Suppression of %s does not suppress any diagnostic", strings.Join(unused, ", "))
+		default:
+			continue
+		}
+		diagnostics = append(diagnostics, p.newDiagnostic(comment, CodeUnusedSuppression, config, message))
+	}
+	return diagnostics
+}
+
+// Analyze returns all diagnostics of a document: those of AnalyzeASTWithConfigAndText and,
+// if ix is not nil, AnalyzeWorkspace, followed by suppression comments that suppress nothing
+func (p *Provider) Analyze(uri string, doc *parser.Document, ix *index.Index, config *Config, text string) []lsp.Diagnostic {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	diagnostics := p.analyzeAST(doc, config, text)
+	if ix != nil {
+		diagnostics = append(diagnostics, p.analyzeWorkspace(uri, doc, ix, config)...)
+	}
+
+	ss := parseSuppressions(doc)
+	diagnostics = ss.apply(diagnostics)
+	if config.Enabled(CodeUnusedSuppression) {
+		diagnostics = append(diagnostics, p.unused(ss, config)...)
+	}
+	return diagnostics
+}
+
+// knownCode checks if a code belongs to a rule
+func knownCode(code string) bool {
+	_, ok := LookupRule(code)
+	return ok
+}
+
+// containsCode checks if codes contains code
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// before checks if position a is before position b
+func before(a, b lsp.Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
@@ -0,0 +1,97 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// codesByLine returns the codes of the diagnostics as "line:code"
+func codesByLine(diags []lsp.Diagnostic) map[string]bool {
+	codes := make(map[string]bool)
+	for _, d := range diags {
+		codes[string(rune('0'+d.Range.Start.Line))+":"+d.Code] = true
+	}
+	return codes
+}
+
+func TestSuppressNextStatement(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++VER(Z038) FMID(HBB77C0) FOO(X) /* smpe-lint-disable-next-statement unknown_operand -- site operand */ .\n" +
+		"++VER(Z038) FMID(HBB77C0) BAR(X) .\n" +
+		"++VER(Z038) FMID(HBB77C0) BAZ(X) .\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+
+	codes := codesByLine(diags)
+	if !codes["0:unknown_operand"] || codes["1:unknown_operand"] || !codes["2:unknown_operand"] {
+		t.Errorf("Expected unknown_operand on lines 0 and 2 only, got %v", diags)
+	}
+	if codes["0:"+CodeUnusedSuppression] {
+		t.Errorf("Expected the suppression to be used, got %v", diags)
+	}
+}
+
+func TestSuppressRange(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++VER(Z038) FMID(HBB77C0) /* smpe-lint-disable unknown_operand */ FOO(X) .\n" +
+		"++VER(Z038) FMID(HBB77C0) BAR(X) /* smpe-lint-enable */ .\n" +
+		"++VER(Z038) FMID(HBB77C0) BAZ(X) .\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+
+	codes := codesByLine(diags)
+	if codes["0:unknown_operand"] || codes["1:unknown_operand"] || !codes["2:unknown_operand"] {
+		t.Errorf("Expected unknown_operand on line 2 only, got %v", diags)
+	}
+
+	// AnalyzeASTWithConfigAndText honours suppressions as well
+	diags = dp.AnalyzeASTWithConfigAndText(p.Parse(text), DefaultConfig(), text)
+	if codes := codesByLine(diags); codes["0:unknown_operand"] || !codes["2:unknown_operand"] {
+		t.Errorf("Expected unknown_operand on line 2 only, got %v", diags)
+	}
+}
+
+func TestSuppressFile(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++VER(Z038) FMID(HBB77C0) FOO(X) .\n" +
+		"++VER(Z038) FMID(HBB77C0) FMID(HBB77C0) /* smpe-lint-disable-file */ BAR(X) .\n"
+	diags := dp.Analyze("file:///a.smpe", p.Parse(text), nil, DefaultConfig(), text)
+	if len(diags) != 0 {
+		t.Errorf("Expected all diagnostics to be suppressed, got %v", diags)
+	}
+}
+
+func TestUnusedSuppression(t *testing.T) {
+	_, p, dp := loadRealStore(t)
+	text := "++VER(Z038) FMID(HBB77C0) /* smpe-lint-disable no_such_rule */ .\n" +
+		"++VER(Z038) FMID(HBB77C0) /* smpe-lint-disable-next-statement unknown_operand, duplicate_operand */ .\n" +
+		"++VER(Z038) FMID(HBB77C0) BAR(X) /* smpe-lint-disable-next-statement */ .\n"
+	doc := p.Parse(text)
+
+	var unused []lsp.Diagnostic
+	for _, d := range dp.Analyze("file:///a.smpe", doc, nil, DefaultConfig(), text) {
+		if d.Code == CodeUnusedSuppression {
+			unused = append(unused, d)
+		}
+	}
+	want := []string{
+		"Unknown diagnostic code no_such_rule",
+		"Suppression of duplicate_operand does not suppress any diagnostic",
+		"Suppression comment does not suppress any diagnostic", // no statement follows
+	}
+	if len(unused) != len(want) {
+		t.Fatalf("Expected %d unused suppressions, got %v", len(want), unused)
+	}
+	for i, message := range want {
+		if unused[i].Range.Start.Line != i || !containsText(unused[i].Message, message) {
+			t.Errorf("Expected %q on line %d, got %+v", message, i, unused[i])
+		}
+	}
+
+	// Suppressions of rules that are turned off are not reported
+	config := DefaultConfig().Override(CodeUnknownOperand, SeverityOff).Override(CodeDuplicateOperand, SeverityOff)
+	for _, d := range dp.Analyze("file:///a.smpe", doc, nil, config, text) {
+		if d.Code == CodeUnusedSuppression && d.Range.Start.Line == 1 {
+			t.Errorf("Expected no unused suppression for rules that are off, got %+v", d)
+		}
+	}
+}
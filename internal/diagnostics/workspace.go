@@ -0,0 +1,129 @@
+package diagnostics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/index"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/references"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// AnalyzeWorkspace returns the diagnostics of a document that depend on the other
+// workspace files. SYSMOD IDs are resolved through ix, which must contain the document under uri.
+func (p *Provider) AnalyzeWorkspace(uri string, doc *parser.Document, ix *index.Index, config *Config) []lsp.Diagnostic {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if doc == nil || ix == nil {
+		return make([]lsp.Diagnostic, 0)
+	}
+	return parseSuppressions(doc).apply(p.analyzeWorkspace(uri, doc, ix, config))
+}
+
+// analyzeWorkspace returns the workspace diagnostics of a document including suppressed ones
+func (p *Provider) analyzeWorkspace(uri string, doc *parser.Document, ix *index.Index, config *Config) []lsp.Diagnostic {
+	diagnostics := make([]lsp.Diagnostic, 0)
+	checkDuplicates := config.Enabled(CodeDuplicateSysmodDefinition)
+	checkUnresolved := config.Enabled(CodeUnresolvedSysmodReference)
+
+	for _, symbol := range references.NewProvider().Symbols(doc) {
+		if symbol.IsDefinition && checkDuplicates {
+			if diag := p.checkDuplicateDefinition(uri, symbol, ix, config); diag != nil {
+				diagnostics = append(diagnostics, *diag)
+			}
+		}
+		if !symbol.IsDefinition && checkUnresolved && isResolvedReference(symbol) {
+			if diag := p.checkUnresolvedReference(symbol, ix, config); diag != nil {
+				diagnostics = append(diagnostics, *diag)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// checkDuplicateDefinition reports a SYSMOD ID that is also defined elsewhere in the workspace
+func (p *Provider) checkDuplicateDefinition(uri string, symbol references.Symbol, ix *index.Index, config *Config) *lsp.Diagnostic {
+	var related []lsp.DiagnosticRelatedInformation
+	for _, def := range ix.Resolve(symbol.Type, symbol.Name) {
+		if def.URI == uri && def.Position == symbol.Position {
+			continue
+		}
+		related = append(related, lsp.DiagnosticRelatedInformation{
+			Location: def.Location(),
+			Message:  "Also defined by " + def.Context,
+		})
+	}
+	if len(related) == 0 {
+		return nil
+	}
+
+	diag := p.symbolDiagnostic(symbol, CodeDuplicateSysmodDefinition, config,
+		fmt.Sprintf("SYSMOD ID %s is defined %d times in the workspace", symbol.Name, len(related)+1))
+	diag.RelatedInformation = related
+	return &diag
+}
+
+// checkUnresolvedReference reports a referenced SYSMOD ID that is neither defined in the
+// workspace nor listed in the known SYSMODs
+func (p *Provider) checkUnresolvedReference(symbol references.Symbol, ix *index.Index, config *Config) *lsp.Diagnostic {
+	if config.KnownSysmods[symbol.Name] || len(ix.Resolve(symbol.Type, symbol.Name)) > 0 {
+		return nil
+	}
+
+	diag := p.symbolDiagnostic(symbol, CodeUnresolvedSysmodReference, config,
+		fmt.Sprintf("SYSMOD %s referenced in %s is not defined in the workspace or the known SYSMODs", symbol.Name, symbol.Context))
+	return &diag
+}
+
+// isResolvedReference checks if a reference must resolve to a SYSMOD: PRE, REQ and SUP
+// (also within ++IF) and the SYSMOD held by ++HOLD
+func isResolvedReference(symbol references.Symbol) bool {
+	if symbol.Type != references.SymbolTypeSYSMOD {
+		return false
+	}
+	switch symbol.Context {
+	case "PRE", "REQ", "SUP", "++HOLD":
+		return true
+	}
+	return false
+}
+
+// LoadKnownSysmods reads a list of SYSMOD IDs that exist outside the workspace, e.g. on the
+// target system. IDs are separated by blanks, commas or newlines; lines starting with
+// '#' or '*' are comments.
+func LoadKnownSysmods(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	known := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "*") {
+			continue
+		}
+		for _, id := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			known[strings.ToUpper(id)] = true
+		}
+	}
+	return known, scanner.Err()
+}
+
+// symbolDiagnostic creates a diagnostic of a rule covering a symbol
+func (p *Provider) symbolDiagnostic(symbol references.Symbol, code string, config *Config, message string) lsp.Diagnostic {
+	return p.newDiagnostic(&parser.Node{
+		Position: parser.Position{
+			Line:      symbol.Position.Line,
+			Character: symbol.Position.Character,
+			Length:    symbol.Length,
+		},
+	}, code, config, message)
+}
@@ -0,0 +1,93 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/index"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+func TestDiagnosticsDuplicateSysmodDefinition(t *testing.T) {
+	store, p, dp := loadRealStore(t)
+	ix := index.New(p, store.Statements)
+
+	files := map[string]string{
+		"file:///a.smpe": "++PTF(UA00001) .\n++FUNCTION(HXY1100) .\n",
+		"file:///b.smpe": "++APAR(UA00001) .\n++PTF(HXY1100) .\n++PTF(UA00002) .\n",
+	}
+	for uri, text := range files {
+		ix.Open(uri, p.Parse(text), text)
+	}
+
+	diags := dp.AnalyzeWorkspace("file:///b.smpe", p.Parse(files["file:///b.smpe"]), ix, DefaultConfig())
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 duplicate definitions, got %v", diags)
+	}
+	for _, d := range diags {
+		if d.Code != CodeDuplicateSysmodDefinition || d.Severity != lsp.SeverityWarning {
+			t.Errorf("Unexpected diagnostic: %+v", d)
+		}
+		if len(d.RelatedInformation) != 1 || d.RelatedInformation[0].Location.URI != "file:///a.smpe" {
+			t.Errorf("Expected related definition in a.smpe, got %+v", d.RelatedInformation)
+		}
+	}
+	if diags[1].Range.Start.Line != 1 || diags[1].RelatedInformation[0].Message != "Also defined by ++FUNCTION" {
+		t.Errorf("Expected ++PTF(HXY1100) to clash with the ++FUNCTION, got %+v", diags[1])
+	}
+
+	diags = dp.AnalyzeWorkspace("file:///b.smpe", p.Parse(files["file:///b.smpe"]), ix, DefaultConfig().Override(CodeDuplicateSysmodDefinition, SeverityOff))
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics when disabled, got %v", diags)
+	}
+}
+
+func TestDiagnosticsUnresolvedSysmodReference(t *testing.T) {
+	store, p, dp := loadRealStore(t)
+	ix := index.New(p, store.Statements)
+
+	base := "++FUNCTION(HXY1100) .\n++PTF(UA00001) FMID(HXY1100) .\n"
+	ix.Open("file:///base.smpe", p.Parse(base), base)
+	fix := "++PTF(UA00002) FMID(HXY1100)\n  PRE(UA00001,UA00003) SUP(UA00004) .\n" +
+		"++IF FMID(HXY1100) THEN REQ(UA00005) .\n++HOLD(UA00006) FMID(HXY1100) ERROR REASON(AA00001) .\n" +
+		"++PTF(UA00007) FMID(HXY1100) REQ(HXY1100,UA00008) .\n"
+	doc := p.Parse(fix)
+	ix.Open("file:///fix.smpe", doc, fix)
+
+	config := &Config{KnownSysmods: map[string]bool{"UA00008": true}}
+	diags := dp.AnalyzeWorkspace("file:///fix.smpe", doc, ix, config)
+
+	if len(diags) != 4 {
+		t.Fatalf("Expected UA00003, UA00004, UA00005 and UA00006 to be unresolved, got %v", diags)
+	}
+	for i, id := range []string{"UA00003", "UA00004", "UA00005", "UA00006"} {
+		if diags[i].Code != CodeUnresolvedSysmodReference || !containsText(diags[i].Message, id) {
+			t.Errorf("Expected %s to be unresolved, got %q", id, diags[i].Message)
+		}
+	}
+
+	// The check can be disabled for the whole file
+	disabled := "++PTF(UA00009) /* smpe-lint-disable-file unresolved_sysmod_reference */\n  PRE(UA00003) .\n"
+	doc = p.Parse(disabled)
+	ix.Open("file:///disabled.smpe", doc, disabled)
+	if diags := dp.AnalyzeWorkspace("file:///disabled.smpe", doc, ix, config); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics in disabled file, got %v", diags)
+	}
+}
+
+func TestLoadKnownSysmods(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known.txt")
+	content := "# installed maintenance\nUA00001 UA00002,ua00003\n* HBB77C0\n\nHBB77D0\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	known, err := LoadKnownSysmods(path)
+	if err != nil {
+		t.Fatalf("LoadKnownSysmods failed: %v", err)
+	}
+	if len(known) != 4 || !known["UA00003"] || !known["HBB77D0"] || known["HBB77C0"] {
+		t.Errorf("Unexpected known SYSMODs: %v", known)
+	}
+}
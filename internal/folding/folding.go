@@ -39,6 +39,10 @@ func (p *Provider) GetFoldingRanges(doc *parser.Document, lines []string) []lsp.
 				Kind:      "region",
 			})
 		}
+
+		if stmt.Update != nil {
+			ranges = append(ranges, p.getUpdateRanges(stmt.Update)...)
+		}
 	}
 
 	// Multi-line comments are foldable too
@@ -121,3 +125,32 @@ func (p *Provider) getStatementEndLine(stmt *parser.Node, lines []string) int {
 
 	return endLine
 }
+
+// getUpdateRanges returns the IEBUPDTE update blocks of ++MACUPD and ++SRCUPD: each
+// member update from ./ CHANGE, ./ ADD, ./ REPL or ./ REPRO up to the next one, and the
+// ./ NUMBER and ./ DELETE statements within it with their records
+func (p *Provider) getUpdateRanges(update *parser.Update) []lsp.FoldingRange {
+	var ranges []lsp.FoldingRange
+	add := func(start, end int) {
+		if start >= 0 && end > start {
+			ranges = append(ranges, lsp.FoldingRange{StartLine: start, EndLine: end, Kind: "region"})
+		}
+	}
+
+	member := -1 // First line of the current member update
+	end := -1    // Last line of the current member update
+	for _, control := range update.Controls {
+		if control.IsMember() || control.Operation == "ENDUP" {
+			add(member, end)
+			member = -1
+			if control.IsMember() {
+				member = control.Line
+			}
+		} else {
+			add(control.Line, control.LastLine())
+		}
+		end = control.LastLine()
+	}
+	add(member, end)
+	return ranges
+}
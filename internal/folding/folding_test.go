@@ -0,0 +1,59 @@
+package folding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// numbered pads an IEBUPDTE record to column 72 and appends a sequence number
+func numbered(record, number string) string {
+	return record + strings.Repeat(" ", 72-len(record)) + number + "\n"
+}
+
+func TestUpdateFoldingRanges(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++MACUPD(MYMAC) DISTLIB(AMACLIB) .\n" +
+		"./ CHANGE NAME=MYMAC\n" +
+		numbered("         MVC   A,B", "00000100") +
+		"./ DELETE SEQ1=00000200,SEQ2=00000300\n" +
+		"./ NUMBER SEQ1=00000400,NEW1=00000410,INCR=10,INSERT=YES\n" +
+		"         LA    R1,0\n" +
+		"         LA    R2,0\n" +
+		"./ ADD NAME=MYMAC\n" +
+		"         MVC   C,D\n" +
+		"         BR    R14\n" +
+		"./ ENDUP\n"
+	doc := parser.NewParser(store.Statements).Parse(text)
+
+	var got []lsp.FoldingRange
+	for _, r := range NewProvider().GetFoldingRanges(doc, strings.Split(text, "\n")) {
+		if r.StartLine > 2 {
+			got = append(got, r)
+		}
+	}
+
+	// The ./ NUMBER records, the ./ CHANGE member with its ./ DELETE and ./ NUMBER, and the
+	// ./ ADD member up to ./ ENDUP; the one-line ./ DELETE does not fold
+	want := []lsp.FoldingRange{
+		{StartLine: 6, EndLine: 8, Kind: "region"},
+		{StartLine: 3, EndLine: 8, Kind: "region"},
+		{StartLine: 9, EndLine: 11, Kind: "region"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Got folding ranges %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Range %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
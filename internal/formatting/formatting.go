@@ -961,6 +961,13 @@ func (p *Provider) buildFormattedStatementWithLeadingComments(stmt *parser.Node,
 	return strings.Join(outputLines, "\n")
 }
 
+// WrapLine wraps a single line at column 72, starting continuation lines with continuationIndent.
+// Used by providers that rewrite individual lines outside of full formatting (e.g. rename).
+func WrapLine(line string, continuationIndent string) string {
+	var p Provider
+	return p.wrapLineAt72(line, continuationIndent)
+}
+
 // wrapLineAt72 wraps a line if it exceeds column 72
 // Returns the wrapped line(s) as a single string with newlines
 func (p *Provider) wrapLineAt72(line string, continuationIndent string) string {
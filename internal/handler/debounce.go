@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"time"
+)
+
+// DefaultDiagnosticsDelay is the default debounce delay before diagnostics are published
+const DefaultDiagnosticsDelay = 300 * time.Millisecond
+
+// scheduleDiagnostics publishes diagnostics for a document after the configured delay.
+// Scheduling again before the delay has passed restarts it, so a burst of changes
+// is analyzed only once.
+func (h *Handler) scheduleDiagnostics(uri string) {
+	if h.pullDiagnostics {
+		return
+	}
+
+	h.configMutex.RLock()
+	delay := h.diagnosticsDelay
+	h.configMutex.RUnlock()
+
+	h.timersMutex.Lock()
+	defer h.timersMutex.Unlock()
+
+	if timer, ok := h.diagnosticsTimers[uri]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		h.timersMutex.Lock()
+		if h.diagnosticsTimers[uri] == timer {
+			delete(h.diagnosticsTimers, uri)
+		}
+		h.timersMutex.Unlock()
+
+		h.publishDiagnostics(uri)
+	})
+	h.diagnosticsTimers[uri] = timer
+}
+
+// cancelScheduledDiagnostics stops a pending publish for a document
+func (h *Handler) cancelScheduledDiagnostics(uri string) {
+	h.timersMutex.Lock()
+	defer h.timersMutex.Unlock()
+
+	if timer, ok := h.diagnosticsTimers[uri]; ok {
+		timer.Stop()
+		delete(h.diagnosticsTimers, uri)
+	}
+}
@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/cybersorcerer/smpe_ls/internal/codeactions"
 	"github.com/cybersorcerer/smpe_ls/internal/codelens"
 	"github.com/cybersorcerer/smpe_ls/internal/completion"
 	"github.com/cybersorcerer/smpe_ls/internal/data"
@@ -11,121 +18,122 @@ import (
 	"github.com/cybersorcerer/smpe_ls/internal/folding"
 	"github.com/cybersorcerer/smpe_ls/internal/formatting"
 	"github.com/cybersorcerer/smpe_ls/internal/hover"
+	"github.com/cybersorcerer/smpe_ls/internal/index"
 	"github.com/cybersorcerer/smpe_ls/internal/logger"
 	"github.com/cybersorcerer/smpe_ls/internal/parser"
 	"github.com/cybersorcerer/smpe_ls/internal/references"
 	"github.com/cybersorcerer/smpe_ls/internal/semantic"
 	"github.com/cybersorcerer/smpe_ls/internal/symbols"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
 	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
 )
 
-// DiagnosticsConfig holds the configuration for which diagnostics to enable/disable
-type DiagnosticsConfig struct {
-	UnknownStatement            bool `json:"unknownStatement"`
-	InvalidLanguageId           bool `json:"invalidLanguageId"`
-	UnbalancedParentheses       bool `json:"unbalancedParentheses"`
-	MissingTerminator           bool `json:"missingTerminator"`
-	MissingParameter            bool `json:"missingParameter"`
-	UnknownOperand              bool `json:"unknownOperand"`
-	DuplicateOperand            bool `json:"duplicateOperand"`
-	EmptyOperandParameter       bool `json:"emptyOperandParameter"`
-	MissingRequiredOperand      bool `json:"missingRequiredOperand"`
-	DependencyViolation         bool `json:"dependencyViolation"`
-	MutuallyExclusive           bool `json:"mutuallyExclusive"`
-	RequiredGroup               bool `json:"requiredGroup"`
-	MissingInlineData           bool `json:"missingInlineData"`
-	UnknownSubOperand           bool `json:"unknownSubOperand"`
-	SubOperandValidation        bool `json:"subOperandValidation"`
-	ContentBeyondColumn72       bool `json:"contentBeyondColumn72"`
-	StandaloneCommentBetweenMCS bool `json:"standaloneCommentBetweenMCS"`
-}
-
-// DefaultDiagnosticsConfig returns a config with all diagnostics enabled
-func DefaultDiagnosticsConfig() *DiagnosticsConfig {
-	return &DiagnosticsConfig{
-		UnknownStatement:            true,
-		InvalidLanguageId:           true,
-		UnbalancedParentheses:       true,
-		MissingTerminator:           true,
-		MissingParameter:            true,
-		UnknownOperand:              true,
-		DuplicateOperand:            true,
-		EmptyOperandParameter:       true,
-		MissingRequiredOperand:      true,
-		DependencyViolation:         true,
-		MutuallyExclusive:           true,
-		RequiredGroup:               true,
-		MissingInlineData:           true,
-		UnknownSubOperand:           true,
-		SubOperandValidation:        true,
-		ContentBeyondColumn72:       true,
-		StandaloneCommentBetweenMCS: true,
-	}
-}
-
 // Handler implements the LSP handler interface
 type Handler struct {
-	version             string
-	commit              string
-	documents           map[string]string
-	parsedDocuments     map[string]*parser.Document // AST cache
-	documentsMutex      sync.RWMutex
-	parser              *parser.Parser
-	completionProvider  *completion.Provider
-	hoverProvider       *hover.Provider
-	diagnosticsProvider *diagnostics.Provider
-	semanticProvider    *semantic.Provider
-	formattingProvider  *formatting.Provider
-	symbolProvider      *symbols.Provider
-	referencesProvider  *references.Provider
-	codeLensProvider    *codelens.Provider
-	foldingProvider     *folding.Provider
-	server              *lsp.Server
-	rootURI             string
-	diagnosticsConfig   *DiagnosticsConfig
+	version            string
+	commit             string
+	documents          map[string]string
+	parsedDocuments    map[string]*parser.Document // AST cache
+	documentVersions   map[string]int              // client version of each open document
+	documentsMutex     sync.RWMutex
+	current            atomic.Pointer[dataProviders] // providers for the current MCS data, swapped on reload
+	formattingProvider *formatting.Provider
+	symbolProvider     *symbols.Provider
+	referencesProvider *references.Provider
+	codeLensProvider   *codelens.Provider
+	foldingProvider    *folding.Provider
+	index              *index.Index // SYSMOD IDs, FMIDs, elements and DDDEFs of all workspace files
+	indexBuilt         atomic.Bool  // the workspace index contains all files below rootURI
+	server             *lsp.Server
+	rootURI            string
+	dataPath           string       // base data file, empty for the built-in smpe.json
+	dataOverlays       []string     // overlay files configured by the client, guarded by configMutex
+	targetRelease      string       // SMP/E release the MCS are written for, guarded by configMutex
+	reloadMutex        sync.Mutex   // serializes data reloads
+	configMutex        sync.RWMutex // guards diagnosticsConfig, knownSysmods, diagnosticsDelay, diagnosticsVersion, snippetCommand, dataOverlays, targetRelease and the formatting config
+	diagnosticsConfig  *diagnostics.Config // rule severities; known SYSMODs and the target release are added per analysis
+	knownSysmods       map[string]bool        // SYSMOD IDs from the known SYSMODs file
+	diagnosticsDelay   time.Duration          // debounce delay before publishing diagnostics after a change
+	snippetCommand     bool                   // client implements smpe.insertSnippet for code actions
+	diagnosticsVersion int                    // incremented on diagnostics config changes, part of pull result IDs
+	pullDiagnostics    bool                   // client pulls diagnostics, so none are pushed
+	diagnosticsRefresh bool                   // client supports workspace/diagnostic/refresh
+	diagnosticsTimers  map[string]*time.Timer // pending debounced publishes by URI
+	timersMutex        sync.Mutex
+	publishMutex       sync.Mutex    // serializes the stale check and sending of diagnostics
+	changes            chan struct{} // closed on the next document or configuration change, see workspaceChanges
+	changesMutex       sync.Mutex
 }
 
 // New creates a new handler
 func New(version string, commit string, dataPath string) (*Handler, error) {
 	// Load MCS data once and share it among all providers
-	logger.Info("Loading MCS data from %s", dataPath)
+	if dataPath == "" {
+		logger.Info("Loading built-in MCS data")
+	} else {
+		logger.Info("Loading MCS data from %s", dataPath)
+	}
 	store, err := data.Load(dataPath)
 	if err != nil {
 		return nil, err
 	}
 	logger.Info("Loaded %d MCS statements", len(store.List))
 
-	// Create parser
-	parserInstance := parser.NewParser(store.Statements)
-
-	// Create providers with shared data
-	hoverProvider := hover.NewProvider(store)
-	completionProvider := completion.NewProvider(store)
-	diagnosticsProvider := diagnostics.NewProvider(store)
-	semanticProvider := semantic.NewProvider(store.Statements)
-	formattingProvider := formatting.NewProvider()
-	symbolProvider := symbols.NewProvider()
-	referencesProvider := references.NewProvider()
-	codeLensProvider := codelens.NewProvider()
-	foldingProvider := folding.NewProvider()
-
-	return &Handler{
-		version:             version,
-		commit:              commit,
-		documents:           make(map[string]string),
-		parsedDocuments:     make(map[string]*parser.Document),
-		parser:              parserInstance,
-		completionProvider:  completionProvider,
-		hoverProvider:       hoverProvider,
-		diagnosticsProvider: diagnosticsProvider,
-		semanticProvider:    semanticProvider,
-		formattingProvider:  formattingProvider,
-		symbolProvider:      symbolProvider,
-		referencesProvider:  referencesProvider,
-		codeLensProvider:    codeLensProvider,
-		foldingProvider:     foldingProvider,
-		diagnosticsConfig:   DefaultDiagnosticsConfig(),
-	}, nil
+	h := &Handler{
+		version:            version,
+		commit:             commit,
+		dataPath:           dataPath,
+		documents:          make(map[string]string),
+		parsedDocuments:    make(map[string]*parser.Document),
+		documentVersions:   make(map[string]int),
+		formattingProvider: formatting.NewProvider(),
+		symbolProvider:     symbols.NewProvider(),
+		referencesProvider: references.NewProvider(),
+		codeLensProvider:   codelens.NewProvider(),
+		foldingProvider:    folding.NewProvider(),
+		index:              index.New(nil, nil), // the parser is set by setStore
+		diagnosticsConfig:  diagnostics.DefaultConfig(),
+		diagnosticsDelay:   DefaultDiagnosticsDelay,
+		diagnosticsTimers:  make(map[string]*time.Timer),
+	}
+	h.setStore(store, "")
+	return h, nil
+}
+
+// dataProviders holds the parser and the providers that depend on the MCS data
+type dataProviders struct {
+	store       *data.Store
+	parser      *parser.Parser
+	hover       *hover.Provider
+	completion  *completion.Provider
+	diagnostics *diagnostics.Provider
+	semantic    *semantic.Provider
+	codeActions *codeactions.Provider
+}
+
+// providers returns the providers for the current MCS data
+func (h *Handler) providers() *dataProviders {
+	return h.current.Load()
+}
+
+// setStore creates the parser and the providers for the MCS data and the target release
+// and swaps them in. The workspace index parses files with the new parser from then on.
+// It takes no lock of the handler, so reloadData can call it while holding documentsMutex.
+func (h *Handler) setStore(store *data.Store, targetRelease string) {
+	p := parser.NewParser(store.Statements)
+	providers := &dataProviders{
+		store:       store,
+		parser:      p,
+		hover:       hover.NewProvider(store),
+		completion:  completion.NewProvider(store),
+		diagnostics: diagnostics.NewProvider(store),
+		semantic:    semantic.NewProvider(store.Statements),
+		codeActions: codeactions.NewProvider(store),
+	}
+	providers.hover.SetTargetRelease(targetRelease)
+	providers.completion.SetTargetRelease(targetRelease)
+	h.current.Store(providers)
+	h.index.SetParser(p, store.Statements)
 }
 
 // SetServer sets the LSP server (for sending notifications)
@@ -134,37 +142,39 @@ func (h *Handler) SetServer(server *lsp.Server) {
 }
 
 // Initialize handles the initialize request
-func (h *Handler) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult, error) {
+func (h *Handler) Initialize(ctx context.Context, params lsp.InitializeParams) (*lsp.InitializeResult, error) {
 	logger.Info("smpe_ls %s (commit: %s) initializing", h.version, h.commit)
 
 	// Store workspace root URI for workspace/symbol
 	h.rootURI = params.RootURI
 	logger.Info("Workspace root: %s", h.rootURI)
 
+	// Clients supporting pull diagnostics request them, so they are not pushed
+	if params.Capabilities.TextDocument != nil && params.Capabilities.TextDocument.Diagnostic != nil {
+		h.pullDiagnostics = true
+		h.diagnosticsRefresh = params.Capabilities.Workspace != nil && params.Capabilities.Workspace.Diagnostics != nil &&
+			params.Capabilities.Workspace.Diagnostics.RefreshSupport
+		logger.Info("Client uses pull diagnostics (refresh support: %v)", h.diagnosticsRefresh)
+	}
+
+	h.configMutex.Lock()
+
+	// Data overlays and target release, applied to the MCS data below
+	if params.InitializationOptions != nil {
+		h.dataOverlays = params.InitializationOptions.DataOverlays
+		h.targetRelease = params.InitializationOptions.TargetRelease
+	}
+	targetRelease := h.targetRelease
+
 	// Process initialization options for diagnostics configuration
 	if params.InitializationOptions != nil && params.InitializationOptions.Diagnostics != nil {
 		opts := params.InitializationOptions.Diagnostics
-		h.diagnosticsConfig = &DiagnosticsConfig{
-			UnknownStatement:       opts.UnknownStatement,
-			InvalidLanguageId:      opts.InvalidLanguageId,
-			UnbalancedParentheses:  opts.UnbalancedParentheses,
-			MissingTerminator:      opts.MissingTerminator,
-			MissingParameter:       opts.MissingParameter,
-			UnknownOperand:         opts.UnknownOperand,
-			DuplicateOperand:       opts.DuplicateOperand,
-			EmptyOperandParameter:  opts.EmptyOperandParameter,
-			MissingRequiredOperand: opts.MissingRequiredOperand,
-			DependencyViolation:    opts.DependencyViolation,
-			MutuallyExclusive:      opts.MutuallyExclusive,
-			RequiredGroup:          opts.RequiredGroup,
-			MissingInlineData:      opts.MissingInlineData,
-			UnknownSubOperand:      opts.UnknownSubOperand,
-			SubOperandValidation:        opts.SubOperandValidation,
-			ContentBeyondColumn72:       opts.ContentBeyondColumn72,
-			StandaloneCommentBetweenMCS: opts.StandaloneCommentBetweenMCS,
+		h.diagnosticsConfig = rulesConfig(opts.Rules)
+		if opts.Delay != nil {
+			h.diagnosticsDelay = time.Duration(*opts.Delay) * time.Millisecond
 		}
-		logger.Info("Diagnostics config received from client: MissingRequiredOperand=%v, UnknownOperand=%v, ContentBeyondColumn72=%v",
-			opts.MissingRequiredOperand, opts.UnknownOperand, opts.ContentBeyondColumn72)
+		h.knownSysmods = h.loadKnownSysmods(opts.KnownSysmodsFile)
+		logger.Info("Diagnostics config received from client: rules=%v", opts.Rules)
 	} else {
 		logger.Info("Using default diagnostics config (all enabled)")
 	}
@@ -185,6 +195,20 @@ func (h *Handler) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult
 		logger.Info("Using default formatting config")
 	}
 
+	// Process initialization options for code actions
+	if params.InitializationOptions != nil && params.InitializationOptions.CodeActions != nil {
+		h.snippetCommand = params.InitializationOptions.CodeActions.SnippetCommand
+		logger.Info("Code actions config received from client: SnippetCommand=%v", h.snippetCommand)
+	}
+
+	h.configMutex.Unlock()
+
+	// Merge site-specific data overlays before any document is parsed
+	if !h.loadOverlays(targetRelease) && targetRelease != "" {
+		// The providers created by New do not know the target release yet
+		h.setStore(h.providers().store, targetRelease)
+	}
+
 	// Add all uppercase letters as trigger characters so completion triggers automatically when typing operand names
 	triggerChars := []string{"+", "(", " "}
 	for ch := 'A'; ch <= 'Z'; ch++ {
@@ -193,7 +217,7 @@ func (h *Handler) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult
 
 	return &lsp.InitializeResult{
 		Capabilities: lsp.ServerCapabilities{
-			TextDocumentSync: lsp.TextDocumentSyncFull,
+			TextDocumentSync: lsp.TextDocumentSyncIncremental,
 			CompletionProvider: &lsp.CompletionOptions{
 				TriggerCharacters: triggerChars,
 			},
@@ -206,6 +230,17 @@ func (h *Handler) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult
 			CodeLensProvider:                &lsp.CodeLensOptions{},
 			FoldingRangeProvider:            true,
 			WorkspaceSymbolProvider:         true,
+			RenameProvider:                  &lsp.RenameOptions{PrepareProvider: true},
+			ExecuteCommandProvider: &lsp.ExecuteCommandOptions{
+				Commands: []string{ReloadDataCommand},
+			},
+			CodeActionProvider: &lsp.CodeActionOptions{
+				CodeActionKinds: []string{lsp.CodeActionKindQuickFix},
+			},
+			DiagnosticProvider: &lsp.DiagnosticOptions{
+				Identifier:           "smpe",
+				WorkspaceDiagnostics: true,
+			},
 			SemanticTokensProvider: &lsp.SemanticTokensOptions{
 				Legend: lsp.SemanticTokensLegend{
 					TokenTypes: []string{
@@ -215,6 +250,7 @@ func (h *Handler) Initialize(params lsp.InitializeParams) (*lsp.InitializeResult
 						"comment",   // Comments
 						"string",    // Quoted strings
 						"number",    // Numbers
+						"variable",  // Names in inline JCL
 					},
 					TokenModifiers: []string{},
 				},
@@ -235,11 +271,17 @@ func (h *Handler) TextDocumentDidOpen(params lsp.DidOpenTextDocumentParams) erro
 
 	h.documentsMutex.Lock()
 	h.documents[params.TextDocument.URI] = params.TextDocument.Text
+	h.documentVersions[params.TextDocument.URI] = params.TextDocument.Version
 
 	// Parse document and cache AST
-	doc := h.parser.Parse(params.TextDocument.Text)
+	doc := h.providers().parser.Parse(params.TextDocument.Text)
 	h.parsedDocuments[params.TextDocument.URI] = doc
 	h.documentsMutex.Unlock()
+	h.notifyWorkspaceChange()
+
+	if h.index.Open(params.TextDocument.URI, doc, params.TextDocument.Text) {
+		h.definitionsChanged(params.TextDocument.URI)
+	}
 
 	// Send diagnostics
 	h.publishDiagnostics(params.TextDocument.URI)
@@ -249,22 +291,38 @@ func (h *Handler) TextDocumentDidOpen(params lsp.DidOpenTextDocumentParams) erro
 
 // TextDocumentDidChange handles document change notification
 func (h *Handler) TextDocumentDidChange(params lsp.DidChangeTextDocumentParams) error {
-	logger.Debug("Document changed: %s", params.TextDocument.URI)
+	logger.Debug("Document changed: %s (%d changes)", params.TextDocument.URI, len(params.ContentChanges))
 
 	h.documentsMutex.Lock()
-	// Full sync mode - replace entire document
-	if len(params.ContentChanges) > 0 {
-		h.documents[params.TextDocument.URI] = params.ContentChanges[0].Text
-	}
 	text := h.documents[params.TextDocument.URI]
+	doc := h.parsedDocuments[params.TextDocument.URI]
+
+	for _, change := range params.ContentChanges {
+		if change.Range == nil {
+			// Full content replacement
+			text = change.Text
+			doc = h.providers().parser.Parse(text)
+			continue
+		}
 
-	// Re-parse document and update cache
-	doc := h.parser.Parse(text)
+		// Incremental change - apply the range edit and reparse only the touched statements
+		text = applyContentChange(text, *change.Range, change.Text)
+		newEndLine := change.Range.Start.Line + strings.Count(change.Text, "\n")
+		doc = h.providers().parser.Reparse(doc, text, change.Range.Start.Line, change.Range.End.Line, newEndLine)
+	}
+
+	h.documents[params.TextDocument.URI] = text
 	h.parsedDocuments[params.TextDocument.URI] = doc
+	h.documentVersions[params.TextDocument.URI] = params.TextDocument.Version
 	h.documentsMutex.Unlock()
+	h.notifyWorkspaceChange()
 
-	// Send diagnostics
-	h.publishDiagnostics(params.TextDocument.URI)
+	if h.index.Open(params.TextDocument.URI, doc, text) {
+		h.definitionsChanged(params.TextDocument.URI)
+	}
+
+	// Send diagnostics once the user pauses typing
+	h.scheduleDiagnostics(params.TextDocument.URI)
 
 	return nil
 }
@@ -276,13 +334,65 @@ func (h *Handler) TextDocumentDidClose(params lsp.DidCloseTextDocumentParams) er
 	h.documentsMutex.Lock()
 	delete(h.documents, params.TextDocument.URI)
 	delete(h.parsedDocuments, params.TextDocument.URI) // Also clear cached AST
+	delete(h.documentVersions, params.TextDocument.URI)
 	h.documentsMutex.Unlock()
+	h.notifyWorkspaceChange()
 
+	h.cancelScheduledDiagnostics(params.TextDocument.URI)
+	if h.index.Close(params.TextDocument.URI) {
+		h.definitionsChanged(params.TextDocument.URI)
+	}
+
+	return nil
+}
+
+// Initialized builds the workspace index in the background once the client is ready
+func (h *Handler) Initialized(params lsp.InitializedParams) error {
+	go func() {
+		h.index.Build(context.Background(), h.rootURI)
+		h.indexBuilt.Store(true)
+
+		// Unresolved references are checked from now on
+		h.configMutex.Lock()
+		h.diagnosticsVersion++
+		h.configMutex.Unlock()
+		h.republishAllDiagnostics()
+	}()
+	return nil
+}
+
+// WorkspaceDidChangeWatchedFiles keeps the workspace index in sync with files changed on disk
+// and reloads the MCS data when smpe.json or one of the overlays changed
+func (h *Handler) WorkspaceDidChangeWatchedFiles(params lsp.DidChangeWatchedFilesParams) error {
+	reload := false
+	for _, change := range params.Changes {
+		logger.Debug("Watched file changed: %s (type %d)", change.URI, change.Type)
+		if h.isDataFile(change.URI) {
+			reload = true
+			continue
+		}
+		var changed bool
+		if change.Type == lsp.FileChangeDeleted {
+			changed = h.index.FileDeleted(change.URI)
+		} else {
+			changed = h.index.FileChanged(change.URI)
+		}
+		if changed {
+			h.definitionsChanged(change.URI)
+		}
+	}
+
+	// smpe.json or an overlay was edited
+	if reload {
+		if _, err := h.reloadData(); err != nil {
+			h.showMessage(lsp.MessageTypeError, "smpe_ls: "+err.Error())
+		}
+	}
 	return nil
 }
 
 // TextDocumentCompletion handles completion request
-func (h *Handler) TextDocumentCompletion(params lsp.CompletionParams) ([]lsp.CompletionItem, error) {
+func (h *Handler) TextDocumentCompletion(ctx context.Context, params lsp.CompletionParams) ([]lsp.CompletionItem, error) {
 	logger.Debug("Completion requested at %s:%d:%d",
 		params.TextDocument.URI, params.Position.Line, params.Position.Character)
 
@@ -300,20 +410,20 @@ func (h *Handler) TextDocumentCompletion(params lsp.CompletionParams) ([]lsp.Com
 	if !hasDoc {
 		logger.Debug("No parsed document found, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
 	// Always use AST-based completion
-	items := h.completionProvider.GetCompletionsAST(doc, text, params.Position.Line, params.Position.Character)
+	items := h.providers().completion.GetCompletionsAST(doc, text, params.Position.Line, params.Position.Character)
 	logger.Debug("Using AST-based completion, returning %d items", len(items))
 
 	return items, nil
 }
 
 // TextDocumentHover handles hover request
-func (h *Handler) TextDocumentHover(params lsp.HoverParams) (*lsp.Hover, error) {
+func (h *Handler) TextDocumentHover(ctx context.Context, params lsp.HoverParams) (*lsp.Hover, error) {
 	logger.Debug("Hover requested at %s:%d:%d",
 		params.TextDocument.URI, params.Position.Line, params.Position.Character)
 
@@ -331,20 +441,20 @@ func (h *Handler) TextDocumentHover(params lsp.HoverParams) (*lsp.Hover, error)
 	if !hasDoc {
 		logger.Debug("No parsed document found for hover, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
 	// Always use AST-based hover
-	hover := h.hoverProvider.GetHoverAST(doc, params.Position.Line, params.Position.Character)
+	hover := h.providers().hover.GetHoverAST(doc, params.Position.Line, params.Position.Character)
 	logger.Debug("Using AST-based hover")
 
 	return hover, nil
 }
 
 // TextDocumentSemanticTokensFull handles semantic tokens request
-func (h *Handler) TextDocumentSemanticTokensFull(params lsp.SemanticTokensParams) (*lsp.SemanticTokens, error) {
+func (h *Handler) TextDocumentSemanticTokensFull(ctx context.Context, params lsp.SemanticTokensParams) (*lsp.SemanticTokens, error) {
 	logger.Debug("Semantic tokens request for: %s", params.TextDocument.URI)
 
 	h.documentsMutex.RLock()
@@ -363,16 +473,17 @@ func (h *Handler) TextDocumentSemanticTokensFull(params lsp.SemanticTokensParams
 	}
 
 	// Generate semantic tokens from AST
-	data := h.semanticProvider.BuildTokensFromAST(doc, text)
+	data := h.providers().semantic.BuildTokensFromAST(doc, text)
 
 	return &lsp.SemanticTokens{
 		Data: data,
 	}, nil
 }
 
-// publishDiagnostics publishes diagnostics for a document
+// publishDiagnostics analyzes a document and publishes its diagnostics.
+// The result is discarded if the document changed or was closed during the analysis.
 func (h *Handler) publishDiagnostics(uri string) {
-	if h.server == nil {
+	if h.server == nil || h.pullDiagnostics {
 		return
 	}
 
@@ -380,6 +491,7 @@ func (h *Handler) publishDiagnostics(uri string) {
 	h.documentsMutex.RLock()
 	doc, exists := h.parsedDocuments[uri]
 	text, textExists := h.documents[uri]
+	version := h.documentVersions[uri]
 	h.documentsMutex.RUnlock()
 
 	if !exists {
@@ -392,40 +504,64 @@ func (h *Handler) publishDiagnostics(uri string) {
 		return
 	}
 
-	// Convert handler config to diagnostics config
-	diagConfig := &diagnostics.Config{
-		UnknownStatement:            h.diagnosticsConfig.UnknownStatement,
-		InvalidLanguageId:           h.diagnosticsConfig.InvalidLanguageId,
-		UnbalancedParentheses:       h.diagnosticsConfig.UnbalancedParentheses,
-		MissingTerminator:           h.diagnosticsConfig.MissingTerminator,
-		MissingParameter:            h.diagnosticsConfig.MissingParameter,
-		UnknownOperand:              h.diagnosticsConfig.UnknownOperand,
-		DuplicateOperand:            h.diagnosticsConfig.DuplicateOperand,
-		EmptyOperandParameter:       h.diagnosticsConfig.EmptyOperandParameter,
-		MissingRequiredOperand:      h.diagnosticsConfig.MissingRequiredOperand,
-		DependencyViolation:         h.diagnosticsConfig.DependencyViolation,
-		MutuallyExclusive:           h.diagnosticsConfig.MutuallyExclusive,
-		RequiredGroup:               h.diagnosticsConfig.RequiredGroup,
-		MissingInlineData:           h.diagnosticsConfig.MissingInlineData,
-		UnknownSubOperand:           h.diagnosticsConfig.UnknownSubOperand,
-		SubOperandValidation:        h.diagnosticsConfig.SubOperandValidation,
-		ContentBeyondColumn72:       h.diagnosticsConfig.ContentBeyondColumn72,
-		StandaloneCommentBetweenMCS: h.diagnosticsConfig.StandaloneCommentBetweenMCS,
-	}
-
-	// Generate diagnostics from AST with config and text (for column 72 checking)
-	diags := h.diagnosticsProvider.AnalyzeASTWithConfigAndText(doc, diagConfig, text)
+	diags := h.analyzeDocument(uri, doc, text)
 
 	params := map[string]interface{}{
 		"uri":         uri,
+		"version":     version,
 		"diagnostics": diags,
 	}
 
+	h.publishMutex.Lock()
+	defer h.publishMutex.Unlock()
+
+	h.documentsMutex.RLock()
+	current, open := h.documentVersions[uri]
+	h.documentsMutex.RUnlock()
+	if !open || current != version {
+		logger.Debug("Discarding stale diagnostics for %s (version %d)", uri, version)
+		return
+	}
+
 	if err := h.server.SendNotification("textDocument/publishDiagnostics", params); err != nil {
 		logger.Error("Failed to publish diagnostics: %v", err)
 	}
 }
 
+// analyzeDocument returns the diagnostics for a document using the current configuration
+func (h *Handler) analyzeDocument(uri string, doc *parser.Document, text string) []lsp.Diagnostic {
+	h.configMutex.RLock()
+	config := h.diagnosticsConfig
+	knownSysmods := h.knownSysmods
+	targetRelease := h.targetRelease
+	h.configMutex.RUnlock()
+
+	diagConfig := *config
+	diagConfig.TargetRelease = targetRelease
+	diagConfig.KnownSysmods = knownSysmods
+	if !h.indexBuilt.Load() {
+		// References cannot be resolved before the whole workspace is indexed
+		diagConfig = *diagConfig.Override(diagnostics.CodeUnresolvedSysmodReference, diagnostics.SeverityOff)
+	}
+
+	// Diagnostics from the AST, the text (for column 72 checking) and the other workspace files
+	return h.providers().diagnostics.Analyze(uri, doc, h.index, &diagConfig, text)
+}
+
+
+// rulesConfig converts the rule severities configured by the client into a diagnostics
+// config. Unknown codes and invalid severities are logged; such rules keep their default.
+func rulesConfig(rules map[string]string) *diagnostics.Config {
+	severities := make(map[string]diagnostics.Severity, len(rules))
+	for code, severity := range rules {
+		severities[code] = diagnostics.Severity(severity)
+	}
+	for _, err := range diagnostics.CheckSeverities(severities) {
+		logger.Error("Diagnostics config: %v", err)
+	}
+	return &diagnostics.Config{Severities: severities}
+}
+
 // WorkspaceDidChangeConfiguration handles configuration changes from the client
 func (h *Handler) WorkspaceDidChangeConfiguration(params lsp.DidChangeConfigurationParams) error {
 	logger.Info("Configuration changed")
@@ -433,27 +569,15 @@ func (h *Handler) WorkspaceDidChangeConfiguration(params lsp.DidChangeConfigurat
 	// Update diagnostics config if provided
 	if params.Settings != nil && params.Settings.Smpe != nil && params.Settings.Smpe.Diagnostics != nil {
 		opts := params.Settings.Smpe.Diagnostics
-		h.diagnosticsConfig = &DiagnosticsConfig{
-			UnknownStatement:       opts.UnknownStatement,
-			InvalidLanguageId:      opts.InvalidLanguageId,
-			UnbalancedParentheses:  opts.UnbalancedParentheses,
-			MissingTerminator:      opts.MissingTerminator,
-			MissingParameter:       opts.MissingParameter,
-			UnknownOperand:         opts.UnknownOperand,
-			DuplicateOperand:       opts.DuplicateOperand,
-			EmptyOperandParameter:  opts.EmptyOperandParameter,
-			MissingRequiredOperand: opts.MissingRequiredOperand,
-			DependencyViolation:    opts.DependencyViolation,
-			MutuallyExclusive:      opts.MutuallyExclusive,
-			RequiredGroup:          opts.RequiredGroup,
-			MissingInlineData:      opts.MissingInlineData,
-			UnknownSubOperand:      opts.UnknownSubOperand,
-			SubOperandValidation:        opts.SubOperandValidation,
-			ContentBeyondColumn72:       opts.ContentBeyondColumn72,
-			StandaloneCommentBetweenMCS: opts.StandaloneCommentBetweenMCS,
+		h.configMutex.Lock()
+		h.diagnosticsConfig = rulesConfig(opts.Rules)
+		if opts.Delay != nil {
+			h.diagnosticsDelay = time.Duration(*opts.Delay) * time.Millisecond
 		}
-		logger.Info("Updated diagnostics config: MissingRequiredOperand=%v, ContentBeyondColumn72=%v",
-			opts.MissingRequiredOperand, opts.ContentBeyondColumn72)
+		h.knownSysmods = h.loadKnownSysmods(opts.KnownSysmodsFile)
+		h.diagnosticsVersion++
+		h.configMutex.Unlock()
+		logger.Info("Updated diagnostics config: rules=%v", opts.Rules)
 
 		// Republish diagnostics for all open documents
 		h.republishAllDiagnostics()
@@ -462,6 +586,7 @@ func (h *Handler) WorkspaceDidChangeConfiguration(params lsp.DidChangeConfigurat
 	// Update formatting config if provided
 	if params.Settings != nil && params.Settings.Smpe != nil && params.Settings.Smpe.Formatting != nil {
 		opts := params.Settings.Smpe.Formatting
+		h.configMutex.Lock()
 		h.formattingProvider.SetConfig(&formatting.Config{
 			Enabled:             opts.Enabled,
 			IndentContinuation:  opts.IndentContinuation,
@@ -469,15 +594,69 @@ func (h *Handler) WorkspaceDidChangeConfiguration(params lsp.DidChangeConfigurat
 			WrapListsAfterN:     opts.WrapListsAfterN,
 			MoveLeadingComments: opts.MoveLeadingComments,
 		})
+		h.configMutex.Unlock()
 		logger.Info("Updated formatting config: Enabled=%v, IndentContinuation=%d, OneOperandPerLine=%v, WrapListsAfterN=%d, MoveLeadingComments=%v",
 			opts.Enabled, opts.IndentContinuation, opts.OneOperandPerLine, opts.WrapListsAfterN, opts.MoveLeadingComments)
 	}
 
+	// Reload the MCS data if the overlays changed
+	if params.Settings != nil && params.Settings.Smpe != nil && params.Settings.Smpe.DataOverlays != nil {
+		overlays := *params.Settings.Smpe.DataOverlays
+		h.configMutex.Lock()
+		changed := strings.Join(overlays, "\n") != strings.Join(h.dataOverlays, "\n")
+		h.dataOverlays = overlays
+		h.configMutex.Unlock()
+		if changed {
+			if _, err := h.reloadData(); err != nil {
+				h.showMessage(lsp.MessageTypeError, "smpe_ls: "+err.Error())
+			}
+		}
+	}
+
+	// Filter and check against the new target release
+	if params.Settings != nil && params.Settings.Smpe != nil && params.Settings.Smpe.TargetRelease != nil {
+		h.setTargetRelease(*params.Settings.Smpe.TargetRelease)
+	}
+
 	return nil
 }
 
-// republishAllDiagnostics republishes diagnostics for all open documents
+// setTargetRelease changes the SMP/E release that completion, hover and diagnostics
+// check the MCS against
+func (h *Handler) setTargetRelease(release string) {
+	h.configMutex.Lock()
+	changed := release != h.targetRelease
+	h.targetRelease = release
+	h.configMutex.Unlock()
+	if !changed {
+		return
+	}
+	logger.Info("Target SMP/E release: %q", release)
+
+	h.reloadMutex.Lock()
+	h.setStore(h.providers().store, release)
+	h.reloadMutex.Unlock()
+
+	h.configMutex.Lock()
+	h.diagnosticsVersion++
+	h.configMutex.Unlock()
+	h.republishAllDiagnostics()
+}
+
+// republishAllDiagnostics schedules diagnostics for all open documents.
+// Clients using pull diagnostics are asked to pull again instead.
+// Waiting workspace/diagnostic requests are woken in both cases.
 func (h *Handler) republishAllDiagnostics() {
+	h.notifyWorkspaceChange()
+	if h.pullDiagnostics {
+		if h.server != nil && h.diagnosticsRefresh {
+			if err := h.server.SendRequest("workspace/diagnostic/refresh", nil); err != nil {
+				logger.Error("Failed to request diagnostics refresh: %v", err)
+			}
+		}
+		return
+	}
+
 	h.documentsMutex.RLock()
 	uris := make([]string, 0, len(h.documents))
 	for uri := range h.documents {
@@ -487,16 +666,39 @@ func (h *Handler) republishAllDiagnostics() {
 
 	logger.Info("Republishing diagnostics for %d open documents", len(uris))
 	for _, uri := range uris {
-		h.publishDiagnostics(uri)
+		h.scheduleDiagnostics(uri)
+	}
+}
+
+// definitionsChanged updates the diagnostics of the open documents other than uri after
+// the SYSMOD definitions in the workspace changed, since they are checked across files
+func (h *Handler) definitionsChanged(uri string) {
+	if h.pullDiagnostics {
+		h.republishAllDiagnostics()
+		return
+	}
+
+	h.documentsMutex.RLock()
+	uris := make([]string, 0, len(h.documents))
+	for other := range h.documents {
+		if other != uri {
+			uris = append(uris, other)
+		}
+	}
+	h.documentsMutex.RUnlock()
+
+	for _, other := range uris {
+		h.scheduleDiagnostics(other)
 	}
 }
 
 // TextDocumentFormatting handles document formatting request
-func (h *Handler) TextDocumentFormatting(params lsp.DocumentFormattingParams) ([]lsp.TextEdit, error) {
+func (h *Handler) TextDocumentFormatting(ctx context.Context, params lsp.DocumentFormattingParams) ([]lsp.TextEdit, error) {
 	logger.Debug("Formatting requested for: %s", params.TextDocument.URI)
 
-	// Check if formatting is enabled
-	if !h.formattingProvider.GetConfig().Enabled {
+	// Format with a snapshot of the config, so configMutex is not held while documentsMutex is taken
+	formatter := h.formatter()
+	if !formatter.GetConfig().Enabled {
 		logger.Debug("Formatting is disabled")
 		return nil, nil
 	}
@@ -515,24 +717,25 @@ func (h *Handler) TextDocumentFormatting(params lsp.DocumentFormattingParams) ([
 	if !hasDoc {
 		logger.Debug("No parsed document found for formatting, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	edits := h.formattingProvider.FormatDocument(doc, text)
+	edits := formatter.FormatDocument(doc, text)
 	logger.Debug("Formatting returned %d edits", len(edits))
 
 	return edits, nil
 }
 
 // TextDocumentRangeFormatting handles range formatting request
-func (h *Handler) TextDocumentRangeFormatting(params lsp.DocumentRangeFormattingParams) ([]lsp.TextEdit, error) {
+func (h *Handler) TextDocumentRangeFormatting(ctx context.Context, params lsp.DocumentRangeFormattingParams) ([]lsp.TextEdit, error) {
 	logger.Debug("Range formatting requested for: %s (lines %d-%d)",
 		params.TextDocument.URI, params.Range.Start.Line, params.Range.End.Line)
 
-	// Check if formatting is enabled
-	if !h.formattingProvider.GetConfig().Enabled {
+	// Format with a snapshot of the config, so configMutex is not held while documentsMutex is taken
+	formatter := h.formatter()
+	if !formatter.GetConfig().Enabled {
 		logger.Debug("Formatting is disabled")
 		return nil, nil
 	}
@@ -551,24 +754,37 @@ func (h *Handler) TextDocumentRangeFormatting(params lsp.DocumentRangeFormatting
 	if !hasDoc {
 		logger.Debug("No parsed document found for range formatting, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	edits := h.formattingProvider.FormatRange(doc, text, params.Range.Start.Line, params.Range.End.Line)
+	edits := formatter.FormatRange(doc, text, params.Range.Start.Line, params.Range.End.Line)
 	logger.Debug("Range formatting returned %d edits", len(edits))
 
 	return edits, nil
 }
 
+// formatter returns a formatting provider with a copy of the current formatting config
+func (h *Handler) formatter() *formatting.Provider {
+	h.configMutex.RLock()
+	config := *h.formattingProvider.GetConfig()
+	h.configMutex.RUnlock()
+
+	formatter := formatting.NewProvider()
+	formatter.SetConfig(&config)
+	return formatter
+}
+
 // UpdateFormattingConfig updates the formatting configuration
 func (h *Handler) UpdateFormattingConfig(config *formatting.Config) {
+	h.configMutex.Lock()
+	defer h.configMutex.Unlock()
 	h.formattingProvider.SetConfig(config)
 }
 
 // TextDocumentDocumentSymbol handles document symbol request
-func (h *Handler) TextDocumentDocumentSymbol(params lsp.DocumentSymbolParams) ([]lsp.DocumentSymbol, error) {
+func (h *Handler) TextDocumentDocumentSymbol(ctx context.Context, params lsp.DocumentSymbolParams) ([]lsp.DocumentSymbol, error) {
 	logger.Debug("Document symbols requested for: %s", params.TextDocument.URI)
 
 	h.documentsMutex.RLock()
@@ -585,7 +801,7 @@ func (h *Handler) TextDocumentDocumentSymbol(params lsp.DocumentSymbolParams) ([
 	if !hasDoc {
 		logger.Debug("No parsed document found for symbols, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
@@ -597,11 +813,58 @@ func (h *Handler) TextDocumentDocumentSymbol(params lsp.DocumentSymbolParams) ([
 	return symbols, nil
 }
 
-// TextDocumentDefinition handles go-to-definition request
-func (h *Handler) TextDocumentDefinition(params lsp.DefinitionParams) (*lsp.Location, error) {
+// TextDocumentDefinition handles go-to-definition request.
+// Returns every definition in the workspace if a SYSMOD ID is defined more than once.
+func (h *Handler) TextDocumentDefinition(ctx context.Context, params lsp.DefinitionParams) ([]lsp.Location, error) {
 	logger.Debug("Definition requested at %s:%d:%d",
 		params.TextDocument.URI, params.Position.Line, params.Position.Character)
 
+	entry := h.index.At(params.TextDocument.URI, params.Position.Line, params.Position.Character)
+	if entry == nil {
+		return nil, nil // No symbol found
+	}
+
+	definitions := h.index.Resolve(entry.Type, entry.Name)
+	if entry.IsDefinition && len(definitions) < 2 {
+		return nil, nil // Already at the only definition
+	}
+
+	var locations []lsp.Location
+	for _, d := range definitions {
+		locations = append(locations, d.Location())
+	}
+	logger.Debug("Found %d definitions of %s", len(locations), entry.Name)
+	return locations, nil
+}
+
+// TextDocumentReferences handles find-references request
+func (h *Handler) TextDocumentReferences(ctx context.Context, params lsp.ReferenceParams) ([]lsp.Location, error) {
+	logger.Debug("References requested at %s:%d:%d (includeDeclaration=%v)",
+		params.TextDocument.URI, params.Position.Line, params.Position.Character, params.Context.IncludeDeclaration)
+
+	entry := h.index.At(params.TextDocument.URI, params.Position.Line, params.Position.Character)
+	if entry == nil {
+		return nil, nil
+	}
+
+	var locations []lsp.Location
+	for _, e := range h.index.References(entry.Type, entry.Name) {
+		// Skip definitions if not requested
+		if !params.Context.IncludeDeclaration && e.IsDefinition {
+			continue
+		}
+		locations = append(locations, e.Location())
+	}
+
+	logger.Debug("Found %d references", len(locations))
+
+	return locations, nil
+}
+
+// TextDocumentCodeLens handles code lens request
+func (h *Handler) TextDocumentCodeLens(ctx context.Context, params lsp.CodeLensParams) ([]lsp.CodeLens, error) {
+	logger.Debug("CodeLens requested for: %s", params.TextDocument.URI)
+
 	h.documentsMutex.RLock()
 	text, textExists := h.documents[params.TextDocument.URI]
 	doc, hasDoc := h.parsedDocuments[params.TextDocument.URI]
@@ -614,28 +877,22 @@ func (h *Handler) TextDocumentDefinition(params lsp.DefinitionParams) (*lsp.Loca
 
 	// Ensure we have a parsed document
 	if !hasDoc {
-		logger.Debug("No parsed document found for definition, parsing now: %s", params.TextDocument.URI)
+		logger.Debug("No parsed document found for code lens, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	location := h.referencesProvider.GetDefinition(doc, text, params.Position.Line, params.Position.Character)
-	if location != nil {
-		location.URI = params.TextDocument.URI
-		logger.Debug("Definition found at line %d", location.Range.Start.Line)
-	} else {
-		logger.Debug("No definition found")
-	}
+	lenses := h.codeLensProvider.GetCodeLenses(doc, h.index)
+	logger.Debug("CodeLens returned %d lenses", len(lenses))
 
-	return location, nil
+	return lenses, nil
 }
 
-// TextDocumentReferences handles find-references request
-func (h *Handler) TextDocumentReferences(params lsp.ReferenceParams) ([]lsp.Location, error) {
-	logger.Debug("References requested at %s:%d:%d (includeDeclaration=%v)",
-		params.TextDocument.URI, params.Position.Line, params.Position.Character, params.Context.IncludeDeclaration)
+// TextDocumentFoldingRange handles folding range request
+func (h *Handler) TextDocumentFoldingRange(ctx context.Context, params lsp.FoldingRangeParams) ([]lsp.FoldingRange, error) {
+	logger.Debug("Folding ranges requested for: %s", params.TextDocument.URI)
 
 	h.documentsMutex.RLock()
 	text, textExists := h.documents[params.TextDocument.URI]
@@ -649,28 +906,37 @@ func (h *Handler) TextDocumentReferences(params lsp.ReferenceParams) ([]lsp.Loca
 
 	// Ensure we have a parsed document
 	if !hasDoc {
-		logger.Debug("No parsed document found for references, parsing now: %s", params.TextDocument.URI)
+		logger.Debug("No parsed document found for folding, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	locations := h.referencesProvider.GetReferences(doc, text, params.Position.Line, params.Position.Character, params.Context.IncludeDeclaration)
+	lines := strings.Split(text, "\n")
+	ranges := h.foldingProvider.GetFoldingRanges(doc, lines)
+	logger.Debug("Folding ranges returned %d ranges", len(ranges))
 
-	// Set URI for all locations
-	for i := range locations {
-		locations[i].URI = params.TextDocument.URI
-	}
+	return ranges, nil
+}
 
-	logger.Debug("Found %d references", len(locations))
+// WorkspaceSymbol handles workspace/symbol requests
+func (h *Handler) WorkspaceSymbol(ctx context.Context, params lsp.WorkspaceSymbolParams) ([]lsp.SymbolInformation, error) {
+	logger.Debug("Workspace symbol query: %q", params.Query)
 
-	return locations, nil
+	results, err := h.index.Symbols(ctx, params.Query)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Workspace symbol returned %d results", len(results))
+
+	return results, nil
 }
 
-// TextDocumentCodeLens handles code lens request
-func (h *Handler) TextDocumentCodeLens(params lsp.CodeLensParams) ([]lsp.CodeLens, error) {
-	logger.Debug("CodeLens requested for: %s", params.TextDocument.URI)
+// TextDocumentPrepareRename handles prepare-rename request
+func (h *Handler) TextDocumentPrepareRename(ctx context.Context, params lsp.PrepareRenameParams) (*lsp.PrepareRenameResult, error) {
+	logger.Debug("Prepare rename requested at %s:%d:%d",
+		params.TextDocument.URI, params.Position.Line, params.Position.Character)
 
 	h.documentsMutex.RLock()
 	text, textExists := h.documents[params.TextDocument.URI]
@@ -684,22 +950,20 @@ func (h *Handler) TextDocumentCodeLens(params lsp.CodeLensParams) ([]lsp.CodeLen
 
 	// Ensure we have a parsed document
 	if !hasDoc {
-		logger.Debug("No parsed document found for code lens, parsing now: %s", params.TextDocument.URI)
+		logger.Debug("No parsed document found for prepare rename, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	lenses := h.codeLensProvider.GetCodeLenses(doc)
-	logger.Debug("CodeLens returned %d lenses", len(lenses))
-
-	return lenses, nil
+	return h.referencesProvider.PrepareRename(doc, params.Position.Line, params.Position.Character), nil
 }
 
-// TextDocumentFoldingRange handles folding range request
-func (h *Handler) TextDocumentFoldingRange(params lsp.FoldingRangeParams) ([]lsp.FoldingRange, error) {
-	logger.Debug("Folding ranges requested for: %s", params.TextDocument.URI)
+// TextDocumentRename handles rename request for SYSMOD IDs and FMIDs across the workspace
+func (h *Handler) TextDocumentRename(ctx context.Context, params lsp.RenameParams) (*lsp.WorkspaceEdit, error) {
+	logger.Debug("Rename requested at %s:%d:%d to %q",
+		params.TextDocument.URI, params.Position.Line, params.Position.Character, params.NewName)
 
 	h.documentsMutex.RLock()
 	text, textExists := h.documents[params.TextDocument.URI]
@@ -713,38 +977,123 @@ func (h *Handler) TextDocumentFoldingRange(params lsp.FoldingRangeParams) ([]lsp
 
 	// Ensure we have a parsed document
 	if !hasDoc {
-		logger.Debug("No parsed document found for folding, parsing now: %s", params.TextDocument.URI)
+		logger.Debug("No parsed document found for rename, parsing now: %s", params.TextDocument.URI)
 		h.documentsMutex.Lock()
-		doc = h.parser.Parse(text)
+		doc = h.providers().parser.Parse(text)
 		h.parsedDocuments[params.TextDocument.URI] = doc
 		h.documentsMutex.Unlock()
 	}
 
-	lines := strings.Split(text, "\n")
-	ranges := h.foldingProvider.GetFoldingRanges(doc, lines)
-	logger.Debug("Folding ranges returned %d ranges", len(ranges))
+	// Files not yet indexed would keep the old name
+	if !h.indexBuilt.Load() {
+		return nil, errors.New("the workspace is still being indexed, try again in a moment")
+	}
 
-	return ranges, nil
+	edit, err := h.referencesProvider.Rename(doc, params.Position.Line, params.Position.Character, params.NewName, renameWorkspace{h})
+	if err != nil {
+		logger.Debug("Rename refused: %v", err)
+		return nil, err
+	}
+
+	logger.Debug("Rename touches %d files", len(edit.Changes))
+	return edit, nil
 }
 
-// WorkspaceSymbol handles workspace/symbol requests
-func (h *Handler) WorkspaceSymbol(params lsp.WorkspaceSymbolParams) ([]lsp.SymbolInformation, error) {
-	logger.Debug("Workspace symbol query: %q", params.Query)
+// TextDocumentCodeAction handles code action request, returning quick fixes for the diagnostics in context
+func (h *Handler) TextDocumentCodeAction(ctx context.Context, params lsp.CodeActionParams) ([]lsp.CodeAction, error) {
+	logger.Debug("Code actions requested for: %s (%d diagnostics)",
+		params.TextDocument.URI, len(params.Context.Diagnostics))
+
+	if len(params.Context.Only) > 0 {
+		wantsQuickFix := false
+		for _, kind := range params.Context.Only {
+			if kind == lsp.CodeActionKindQuickFix {
+				wantsQuickFix = true
+				break
+			}
+		}
+		if !wantsQuickFix {
+			return []lsp.CodeAction{}, nil
+		}
+	}
 
 	h.documentsMutex.RLock()
-	// Copy maps under lock so the provider can iterate safely
-	docs := make(map[string]*parser.Document, len(h.parsedDocuments))
-	texts := make(map[string]string, len(h.documents))
-	for k, v := range h.parsedDocuments {
-		docs[k] = v
+	text, textExists := h.documents[params.TextDocument.URI]
+	doc, hasDoc := h.parsedDocuments[params.TextDocument.URI]
+	h.documentsMutex.RUnlock()
+
+	if !textExists {
+		logger.Debug("Document not found: %s", params.TextDocument.URI)
+		return nil, nil
 	}
-	for k, v := range h.documents {
-		texts[k] = v
+
+	// Ensure we have a parsed document
+	if !hasDoc {
+		logger.Debug("No parsed document found for code actions, parsing now: %s", params.TextDocument.URI)
+		h.documentsMutex.Lock()
+		doc = h.providers().parser.Parse(text)
+		h.parsedDocuments[params.TextDocument.URI] = doc
+		h.documentsMutex.Unlock()
 	}
-	h.documentsMutex.RUnlock()
 
-	results := h.symbolProvider.GetWorkspaceSymbols(params.Query, docs, texts, h.rootURI, h.parser)
-	logger.Debug("Workspace symbol returned %d results", len(results))
+	h.configMutex.RLock()
+	snippetCommand := h.snippetCommand
+	h.configMutex.RUnlock()
 
-	return results, nil
+	return h.providers().codeActions.CodeActions(params.TextDocument.URI, doc, text, params.Context.Diagnostics, snippetCommand), nil
+}
+
+// loadKnownSysmods reads the known SYSMODs file, resolving relative paths against the workspace root.
+// Returns nil if no file is configured or it cannot be read.
+func (h *Handler) loadKnownSysmods(path string) map[string]bool {
+	if path == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) && h.rootURI != "" {
+		path = filepath.Join(workspace.URIToPath(h.rootURI), path)
+	}
+	known, err := diagnostics.LoadKnownSysmods(path)
+	if err != nil {
+		logger.Error("Cannot read known SYSMODs file %s: %v", path, err)
+		return nil
+	}
+	logger.Info("Loaded %d known SYSMODs from %s", len(known), path)
+	return known
+}
+
+// renameWorkspace looks up the SYSMOD IDs and FMIDs to rename in the workspace index
+type renameWorkspace struct {
+	h *Handler
+}
+
+// Occurrences returns the definitions and references of a SYSMOD ID or FMID in the index
+func (ws renameWorkspace) Occurrences(name string) []references.Occurrence {
+	var occurrences []references.Occurrence
+	for _, e := range ws.h.index.References(references.SymbolTypeSYSMOD, name) {
+		occurrences = append(occurrences, references.Occurrence{Symbol: e.Symbol, URI: e.URI})
+	}
+	return occurrences
+}
+
+// Statement returns the MCS definition of a statement in the current data
+func (ws renameWorkspace) Statement(name string) *data.MCSStatement {
+	if def, ok := ws.h.providers().store.Statements[name]; ok {
+		return &def
+	}
+	return nil
+}
+
+// Text returns the content of an open document, or of the file on disk
+func (ws renameWorkspace) Text(uri string) (string, error) {
+	ws.h.documentsMutex.RLock()
+	text, ok := ws.h.documents[uri]
+	ws.h.documentsMutex.RUnlock()
+	if ok {
+		return text, nil
+	}
+	content, err := os.ReadFile(workspace.URIToPath(uri))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
 }
@@ -0,0 +1,486 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// syncBuffer collects server output written from several goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// published is a decoded textDocument/publishDiagnostics notification
+type published struct {
+	URI         string           `json:"uri"`
+	Version     int              `json:"version"`
+	Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+}
+
+// notifications decodes the publishDiagnostics notifications written so far
+func (b *syncBuffer) notifications(t *testing.T) []published {
+	t.Helper()
+	b.mu.Lock()
+	data := b.buf.String()
+	b.mu.Unlock()
+
+	var result []published
+	for data != "" {
+		header, rest, ok := strings.Cut(data, "\r\n\r\n")
+		if !ok {
+			t.Fatalf("Malformed output: %q", data)
+		}
+		length, err := strconv.Atoi(strings.TrimPrefix(header, "Content-Length: "))
+		if err != nil {
+			t.Fatalf("Bad header %q", header)
+		}
+		var msg struct {
+			Method string    `json:"method"`
+			Params published `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(rest[:length]), &msg); err != nil {
+			t.Fatalf("Bad message %q: %v", rest[:length], err)
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			result = append(result, msg.Params)
+		}
+		data = rest[length:]
+	}
+	return result
+}
+
+// newTestHandler returns a handler with the given diagnostics delay, writing to out
+func newTestHandler(t *testing.T, delayMs int) (*Handler, *syncBuffer) {
+	t.Helper()
+	h, err := New("test", "test", "../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	out := &syncBuffer{}
+	h.SetServer(lsp.NewServer(strings.NewReader(""), out, h))
+
+	_, err = h.Initialize(context.Background(), lsp.InitializeParams{
+		InitializationOptions: &lsp.InitializationOptions{
+			Diagnostics: &lsp.DiagnosticsOptions{Delay: &delayMs},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return h, out
+}
+
+func change(uri string, version int, text string) lsp.DidChangeTextDocumentParams {
+	return lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: uri}, Version: version},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: text}},
+	}
+}
+
+func TestDiagnosticsDebounced(t *testing.T) {
+	h, out := newTestHandler(t, 50)
+	uri := "file:///test.smpe"
+
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++PTF(UA12345)."},
+	})
+	for v := 2; v <= 6; v++ {
+		h.TextDocumentDidChange(change(uri, v, "++PTF(UA12345)"))
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	got := out.notifications(t)
+	if len(got) != 2 {
+		t.Fatalf("Expected publish on open and one debounced publish, got %+v", got)
+	}
+	if got[0].Version != 1 || len(got[0].Diagnostics) != 0 {
+		t.Errorf("Unexpected diagnostics on open: %+v", got[0])
+	}
+	if got[1].Version != 6 || len(got[1].Diagnostics) == 0 {
+		t.Errorf("Expected diagnostics for version 6, got %+v", got[1])
+	}
+}
+
+func TestDiagnosticsDiscardedAfterClose(t *testing.T) {
+	h, out := newTestHandler(t, 20)
+	uri := "file:///test.smpe"
+
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++PTF(UA12345)."},
+	})
+	h.TextDocumentDidChange(change(uri, 2, "++PTF(UA12345)"))
+	h.TextDocumentDidClose(lsp.DidCloseTextDocumentParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}})
+	time.Sleep(100 * time.Millisecond)
+
+	if got := out.notifications(t); len(got) != 1 {
+		t.Fatalf("Expected only the publish on open, got %+v", got)
+	}
+}
+
+func TestPullDiagnostics(t *testing.T) {
+	h, err := New("test", "test", "../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	out := &syncBuffer{}
+	h.SetServer(lsp.NewServer(strings.NewReader(""), out, h))
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "disk.smpe"), []byte("++PTF(UA11111)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = h.Initialize(context.Background(), lsp.InitializeParams{
+		RootURI: workspace.PathToURI(root),
+		Capabilities: lsp.ClientCapabilities{
+			TextDocument: &lsp.TextDocumentClientCapabilities{Diagnostic: &lsp.DiagnosticClientCapabilities{}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	uri := workspace.PathToURI(filepath.Join(root, "open.smpe"))
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++PTF(UA12345)"},
+	})
+	if got := out.notifications(t); len(got) != 0 {
+		t.Fatalf("Diagnostics must not be pushed to pull clients, got %+v", got)
+	}
+
+	ctx := context.Background()
+	params := lsp.DocumentDiagnosticParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}}
+	report, err := h.TextDocumentDiagnostic(ctx, params)
+	if err != nil || report.Kind != lsp.DiagnosticReportFull || len(report.Items) == 0 || report.ResultID == "" {
+		t.Fatalf("Expected full report with diagnostics, got %+v (%v)", report, err)
+	}
+
+	params.PreviousResultID = report.ResultID
+	if again, _ := h.TextDocumentDiagnostic(ctx, params); again.Kind != lsp.DiagnosticReportUnchanged {
+		t.Errorf("Expected unchanged report, got %+v", again)
+	}
+
+	h.TextDocumentDidChange(change(uri, 2, "++PTF(UA12345)."))
+	if changed, _ := h.TextDocumentDiagnostic(ctx, params); changed.Kind != lsp.DiagnosticReportFull || len(changed.Items) != 0 {
+		t.Errorf("Expected full report without diagnostics after change, got %+v", changed)
+	}
+
+	h.index.Build(ctx, h.rootURI)
+	ws, err := h.WorkspaceDiagnostic(ctx, lsp.WorkspaceDiagnosticParams{
+		PreviousResultIDs: []lsp.PreviousResultID{{URI: uri, Value: report.ResultID}},
+	})
+	if err != nil {
+		t.Fatalf("WorkspaceDiagnostic failed: %v", err)
+	}
+	reports := make(map[string]lsp.WorkspaceDocumentDiagnosticReport)
+	for _, item := range ws.Items {
+		reports[item.URI] = item
+	}
+	if r, ok := reports[uri]; !ok || r.Version == nil || *r.Version != 2 || r.Kind != lsp.DiagnosticReportFull {
+		t.Errorf("Unexpected report for open document: %+v", r)
+	}
+	if r, ok := reports[workspace.PathToURI(filepath.Join(root, "disk.smpe"))]; !ok || r.Version != nil || len(r.Items) == 0 {
+		t.Errorf("Expected diagnostics for file on disk, got %+v", r)
+	}
+}
+
+func TestWorkspaceDiagnosticWaitsForChanges(t *testing.T) {
+	h, err := New("test", "test", "../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	h.SetServer(lsp.NewServer(strings.NewReader(""), &syncBuffer{}, h))
+
+	root := t.TempDir()
+	path := filepath.Join(root, "disk.smpe")
+	if err := os.WriteFile(path, []byte("++PTF(UA11111)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = h.Initialize(context.Background(), lsp.InitializeParams{
+		RootURI: workspace.PathToURI(root),
+		Capabilities: lsp.ClientCapabilities{
+			TextDocument: &lsp.TextDocumentClientCapabilities{Diagnostic: &lsp.DiagnosticClientCapabilities{}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	h.index.Build(context.Background(), h.rootURI)
+
+	first, err := h.WorkspaceDiagnostic(context.Background(), lsp.WorkspaceDiagnosticParams{})
+	if err != nil || len(first.Items) != 1 || first.Items[0].Kind != lsp.DiagnosticReportFull {
+		t.Fatalf("Expected a full report for the file on disk, got %+v (%v)", first, err)
+	}
+	previous := lsp.WorkspaceDiagnosticParams{
+		PreviousResultIDs: []lsp.PreviousResultID{{URI: first.Items[0].URI, Value: first.Items[0].ResultID}},
+	}
+
+	// Closed files are served from the index, not read again
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing changed: the request waits until it is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if result, err := h.WorkspaceDiagnostic(ctx, previous); err == nil {
+		t.Fatalf("Expected the request to wait for a change, got %+v", result)
+	}
+
+	// A change on disk ends the wait with a new report
+	done := make(chan *lsp.WorkspaceDiagnosticReport)
+	go func() {
+		result, err := h.WorkspaceDiagnostic(context.Background(), previous)
+		if err != nil {
+			t.Errorf("WorkspaceDiagnostic failed: %v", err)
+		}
+		done <- result
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("++PTF(UA11111).\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h.WorkspaceDidChangeWatchedFiles(lsp.DidChangeWatchedFilesParams{
+		Changes: []lsp.FileEvent{{URI: first.Items[0].URI, Type: lsp.FileChangeChanged}},
+	})
+
+	select {
+	case result := <-done:
+		if result == nil || len(result.Items) != 1 || result.Items[0].Kind != lsp.DiagnosticReportFull || len(result.Items[0].Items) != 0 {
+			t.Errorf("Expected a full report without diagnostics, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WorkspaceDiagnostic did not return after the file changed")
+	}
+}
+
+func TestDefinitionAcrossFiles(t *testing.T) {
+	h, out := newTestHandler(t, 10)
+	open := func(uri, text string) {
+		h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: text},
+		})
+	}
+	open("file:///fix.smpe", "++PTF(UA00002) PRE(UA00001).")
+	open("file:///base.smpe", "++PTF(UA00001).")
+	open("file:///copy.smpe", "++PTF(UA00001).")
+
+	locations, err := h.TextDocumentDefinition(context.Background(), lsp.DefinitionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: "file:///fix.smpe"},
+		Position:     lsp.Position{Line: 0, Character: 20},
+	})
+	if err != nil || len(locations) != 2 || locations[0].URI != "file:///base.smpe" || locations[1].URI != "file:///copy.smpe" {
+		t.Fatalf("Expected definitions in base.smpe and copy.smpe, got %+v (%v)", locations, err)
+	}
+
+	// The duplicate is reported in the document opened before it once diagnostics are refreshed
+	time.Sleep(100 * time.Millisecond)
+	var latest []lsp.Diagnostic
+	for _, n := range out.notifications(t) {
+		if n.URI == "file:///base.smpe" {
+			latest = n.Diagnostics
+		}
+	}
+	if len(latest) != 1 || latest[0].Code != "duplicate_sysmod_definition" {
+		t.Errorf("Expected duplicate definition in base.smpe, got %+v", latest)
+	}
+}
+
+func TestRenameUsesIndex(t *testing.T) {
+	h, _ := newTestHandler(t, 10)
+	root := t.TempDir()
+	disk := filepath.Join(root, "disk.smpe")
+	if err := os.WriteFile(disk, []byte("++USERMOD(LJS0002).\n++VER(Z038) FMID(HBB7790) PRE(LJS0001).\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h.rootURI = workspace.PathToURI(root)
+
+	uri := workspace.PathToURI(filepath.Join(root, "open.smpe"))
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++USERMOD(LJS0001)."},
+	})
+	params := lsp.RenameParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: 0, Character: 12},
+		NewName:      "LJS0003",
+	}
+	if _, err := h.TextDocumentRename(context.Background(), params); err == nil {
+		t.Error("Expected rename to be refused before the workspace is indexed")
+	}
+
+	h.index.Build(context.Background(), h.rootURI)
+	h.indexBuilt.Store(true)
+	edit, err := h.TextDocumentRename(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if edits := edit.Changes[uri]; len(edits) != 1 || edits[0].NewText != "LJS0003" {
+		t.Errorf("Unexpected edits in the open document: %+v", edits)
+	}
+	if edits := edit.Changes[workspace.PathToURI(disk)]; len(edits) != 1 || edits[0].Range.Start.Line != 1 {
+		t.Errorf("Expected the PRE reference on disk to be renamed, got %+v", edits)
+	}
+
+	params.NewName = "LJS0002"
+	if _, err := h.TextDocumentRename(context.Background(), params); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("Expected rename to a SYSMOD defined on disk to be refused, got %v", err)
+	}
+}
+
+func TestReloadData(t *testing.T) {
+	h, err := New("test", "test", "../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	out := &syncBuffer{}
+	h.SetServer(lsp.NewServer(strings.NewReader(""), out, h))
+
+	root := t.TempDir()
+	delay := 10
+	_, err = h.Initialize(context.Background(), lsp.InitializeParams{
+		RootURI: workspace.PathToURI(root),
+		InitializationOptions: &lsp.InitializationOptions{
+			Diagnostics: &lsp.DiagnosticsOptions{Delay: &delay},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	uri := workspace.PathToURI(filepath.Join(root, "site.smpe"))
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++USERMOD(LU00001) SITEID(X) ."},
+	})
+	latest := func() []lsp.Diagnostic {
+		time.Sleep(100 * time.Millisecond)
+		got := out.notifications(t)
+		return got[len(got)-1].Diagnostics
+	}
+	if diags := latest(); len(diags) != 1 || diags[0].Code != "unknown_operand" {
+		t.Fatalf("Expected SITEID to be unknown, got %+v", diags)
+	}
+
+	// Creating the workspace overlay reloads the data
+	overlay := filepath.Join(root, ".smpe_ls", "overlay.json")
+	if err := os.MkdirAll(filepath.Dir(overlay), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"statements": [{"name": "++USERMOD", "operands": [{"name": "SITEID", "type": "string"}]}]}`
+	if err := os.WriteFile(overlay, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h.WorkspaceDidChangeWatchedFiles(lsp.DidChangeWatchedFilesParams{
+		Changes: []lsp.FileEvent{{URI: workspace.PathToURI(overlay), Type: lsp.FileChangeCreated}},
+	})
+	if diags := latest(); len(diags) != 0 {
+		t.Fatalf("Expected SITEID to be known after reload, got %+v", diags)
+	}
+
+	hover, _ := h.TextDocumentHover(context.Background(), lsp.HoverParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: 0, Character: 21},
+	})
+	if hover == nil || !strings.Contains(hover.Contents.Value, ".smpe_ls/overlay.json") {
+		t.Errorf("Expected SITEID hover from the overlay, got %+v", hover)
+	}
+
+	// Problems in the overlay are shown, the data is still loaded
+	content = `{"statements": [{"name": "++USERMOD", "operands": [{"name": "SITEID", "typ": "string"}]}]}`
+	if err := os.WriteFile(overlay, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WorkspaceExecuteCommand(context.Background(), lsp.ExecuteCommandParams{Command: ReloadDataCommand}); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	out.mu.Lock()
+	shown := strings.Contains(out.buf.String(), `"method":"window/showMessage","params":{"type":2,"message":"smpe_ls: 1 problems in .smpe_ls/overlay.json`)
+	out.mu.Unlock()
+	if !shown {
+		t.Error("Expected a warning about the unknown field in the overlay")
+	}
+
+	// A broken overlay keeps the current data
+	if err := os.WriteFile(overlay, []byte(`{"statements": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WorkspaceExecuteCommand(context.Background(), lsp.ExecuteCommandParams{Command: ReloadDataCommand}); err == nil {
+		t.Fatal("Expected reload to fail")
+	}
+	if _, ok := h.providers().parser.Parse("++USERMOD(LU00001) SITEID(X) .").Statements[0].StatementDef.Operand("SITEID"); !ok {
+		t.Error("Expected the data with SITEID to be kept")
+	}
+
+	if _, err := h.WorkspaceExecuteCommand(context.Background(), lsp.ExecuteCommandParams{Command: "smpe.unknown"}); err == nil {
+		t.Error("Expected unknown command to fail")
+	}
+}
+
+func TestTargetRelease(t *testing.T) {
+	h, err := New("test", "test", "../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	out := &syncBuffer{}
+	h.SetServer(lsp.NewServer(strings.NewReader(""), out, h))
+
+	root := t.TempDir()
+	overlay := filepath.Join(root, ".smpe_ls", "overlay.json")
+	if err := os.MkdirAll(filepath.Dir(overlay), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"statements": [{"name": "++USERMOD", "operands": [{"name": "SITEID", "type": "string", "since": "V3R7"}]}]}`
+	if err := os.WriteFile(overlay, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	delay := 10
+	_, err = h.Initialize(context.Background(), lsp.InitializeParams{
+		RootURI: workspace.PathToURI(root),
+		InitializationOptions: &lsp.InitializationOptions{
+			Diagnostics:   &lsp.DiagnosticsOptions{Delay: &delay},
+			TargetRelease: "V3R6",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	uri := workspace.PathToURI(filepath.Join(root, "site.smpe"))
+	h.TextDocumentDidOpen(lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "smpe", Version: 1, Text: "++USERMOD(LU00001) SITEID(X) ."},
+	})
+	latest := func() []lsp.Diagnostic {
+		time.Sleep(100 * time.Millisecond)
+		got := out.notifications(t)
+		return got[len(got)-1].Diagnostics
+	}
+	if diags := latest(); len(diags) != 1 || diags[0].Code != "unsupported_release" {
+		t.Fatalf("Expected SITEID to be unsupported in V3R6, got %+v", diags)
+	}
+
+	// Clearing the target release allows all releases
+	release := ""
+	h.WorkspaceDidChangeConfiguration(lsp.DidChangeConfigurationParams{
+		Settings: &lsp.SettingsPayload{Smpe: &lsp.SmpeSettings{TargetRelease: &release}},
+	})
+	if diags := latest(); len(diags) != 0 {
+		t.Errorf("Expected no diagnostics without target release, got %+v", diags)
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/cybersorcerer/smpe_ls/internal/index"
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// diagnosticsResultID identifies the diagnostics of a text, given by its index.HashText,
+// under the current configuration and workspace definitions. Equal IDs mean the
+// diagnostics are unchanged.
+func (h *Handler) diagnosticsResultID(hash uint64) string {
+	h.configMutex.RLock()
+	version := h.diagnosticsVersion
+	h.configMutex.RUnlock()
+
+	return fmt.Sprintf("%d-%d-%016x", version, h.index.Generation(), hash)
+}
+
+// diagnosticReport returns an unchanged report if previousResultID still matches the text
+// with the given hash, otherwise a full report with the diagnostics returned by analyze
+func (h *Handler) diagnosticReport(hash uint64, previousResultID string, analyze func() []lsp.Diagnostic) lsp.DocumentDiagnosticReport {
+	resultID := h.diagnosticsResultID(hash)
+	if previousResultID == resultID {
+		return lsp.DocumentDiagnosticReport{Kind: lsp.DiagnosticReportUnchanged, ResultID: resultID}
+	}
+	return lsp.DocumentDiagnosticReport{Kind: lsp.DiagnosticReportFull, ResultID: resultID, Items: analyze()}
+}
+
+// TextDocumentDiagnostic handles textDocument/diagnostic pull requests
+func (h *Handler) TextDocumentDiagnostic(ctx context.Context, params lsp.DocumentDiagnosticParams) (*lsp.DocumentDiagnosticReport, error) {
+	uri := params.TextDocument.URI
+	logger.Debug("Diagnostics pulled for: %s", uri)
+
+	h.documentsMutex.RLock()
+	text, textExists := h.documents[uri]
+	doc := h.parsedDocuments[uri]
+	h.documentsMutex.RUnlock()
+
+	if !textExists {
+		// Not open - analyze the file on disk
+		content, err := os.ReadFile(workspace.URIToPath(uri))
+		if err != nil {
+			logger.Debug("Document not found: %s", uri)
+			return &lsp.DocumentDiagnosticReport{Kind: lsp.DiagnosticReportFull}, nil
+		}
+		text = string(content)
+	}
+
+	report := h.diagnosticReport(index.HashText(text), params.PreviousResultID, func() []lsp.Diagnostic {
+		if doc == nil {
+			doc = h.providers().parser.Parse(text)
+		}
+		return h.analyzeDocument(uri, doc, text)
+	})
+	return &report, nil
+}
+
+// WorkspaceDiagnostic handles workspace/diagnostic pull requests, reporting all open
+// documents and every indexed .smpe file below the workspace root. If nothing changed
+// since the client's previous results, the request waits until a document, the
+// diagnostics configuration or an indexed file changes, so clients that poll again right
+// away do not keep the server busy.
+func (h *Handler) WorkspaceDiagnostic(ctx context.Context, params lsp.WorkspaceDiagnosticParams) (*lsp.WorkspaceDiagnosticReport, error) {
+	previous := make(map[string]string, len(params.PreviousResultIDs))
+	for _, p := range params.PreviousResultIDs {
+		previous[p.URI] = p.Value
+	}
+
+	for {
+		// Taken before the reports are made, so no change in between is missed
+		documentsChanged := h.workspaceChanges()
+		indexChanged := h.index.Changed()
+
+		result, err := h.workspaceDiagnostics(ctx, previous)
+		if err != nil {
+			return nil, err
+		}
+		if len(previous) == 0 || hasFullReport(result) {
+			logger.Debug("Workspace diagnostics: %d documents", len(result.Items))
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-documentsChanged:
+		case <-indexChanged:
+		}
+	}
+}
+
+// workspaceDiagnostics reports the open documents and the indexed files on disk. Closed
+// files are served from the index, so unchanged files are neither read nor hashed again.
+func (h *Handler) workspaceDiagnostics(ctx context.Context, previous map[string]string) (*lsp.WorkspaceDiagnosticReport, error) {
+	result := &lsp.WorkspaceDiagnosticReport{Items: []lsp.WorkspaceDocumentDiagnosticReport{}}
+
+	// Open documents take precedence over their on-disk content
+	type openDocument struct {
+		uri     string
+		text    string
+		version int
+		doc     *parser.Document
+	}
+	h.documentsMutex.RLock()
+	opened := make([]openDocument, 0, len(h.documents))
+	isOpen := make(map[string]bool, len(h.documents))
+	for uri, text := range h.documents {
+		opened = append(opened, openDocument{uri: uri, text: text, version: h.documentVersions[uri], doc: h.parsedDocuments[uri]})
+		isOpen[uri] = true
+	}
+	h.documentsMutex.RUnlock()
+
+	for _, od := range opened {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report := h.diagnosticReport(index.HashText(od.text), previous[od.uri], func() []lsp.Diagnostic {
+			doc := od.doc
+			if doc == nil {
+				doc = h.providers().parser.Parse(od.text)
+			}
+			return h.analyzeDocument(od.uri, doc, od.text)
+		})
+		version := od.version
+		result.Items = append(result.Items, lsp.WorkspaceDocumentDiagnosticReport{
+			URI:                      od.uri,
+			Version:                  &version,
+			DocumentDiagnosticReport: report,
+		})
+	}
+
+	for _, content := range h.index.ClosedFiles() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if isOpen[content.URI] {
+			continue
+		}
+		report := h.diagnosticReport(content.Hash, previous[content.URI], func() []lsp.Diagnostic {
+			return h.analyzeDocument(content.URI, h.providers().parser.Parse(content.Text), content.Text)
+		})
+		result.Items = append(result.Items, lsp.WorkspaceDocumentDiagnosticReport{
+			URI:                      content.URI,
+			DocumentDiagnosticReport: report,
+		})
+	}
+	return result, nil
+}
+
+// hasFullReport checks if a workspace report contains new diagnostics for a document
+func hasFullReport(result *lsp.WorkspaceDiagnosticReport) bool {
+	for _, item := range result.Items {
+		if item.Kind == lsp.DiagnosticReportFull {
+			return true
+		}
+	}
+	return false
+}
+
+// workspaceChanges returns a channel that is closed on the next change to an open
+// document or to the diagnostics configuration
+func (h *Handler) workspaceChanges() <-chan struct{} {
+	h.changesMutex.Lock()
+	defer h.changesMutex.Unlock()
+	if h.changes == nil {
+		h.changes = make(chan struct{})
+	}
+	return h.changes
+}
+
+// notifyWorkspaceChange wakes the workspace/diagnostic requests waiting for a change
+func (h *Handler) notifyWorkspaceChange() {
+	h.changesMutex.Lock()
+	defer h.changesMutex.Unlock()
+	if h.changes != nil {
+		close(h.changes)
+		h.changes = nil
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// ReloadDataCommand reloads smpe.json and the data overlays without restarting the server
+const ReloadDataCommand = "smpe.reloadData"
+
+// WorkspaceExecuteCommand handles workspace/executeCommand requests
+func (h *Handler) WorkspaceExecuteCommand(ctx context.Context, params lsp.ExecuteCommandParams) (interface{}, error) {
+	switch params.Command {
+	case ReloadDataCommand:
+		store, err := h.reloadData()
+		if err != nil {
+			return nil, err
+		}
+		h.showMessage(lsp.MessageTypeInfo, fmt.Sprintf("smpe_ls: Reloaded %d MCS statements", len(store.List)))
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q", params.Command)
+	}
+}
+
+// dataLayers returns the overlays merged into smpe.json: the workspace overlay
+// (data.OverlayFile) if it exists, followed by the configured overlays, relative to
+// the workspace root
+func (h *Handler) dataLayers() []data.Layer {
+	root := ""
+	if h.rootURI != "" {
+		root = workspace.URIToPath(h.rootURI)
+	}
+
+	var layers []data.Layer
+	if root != "" {
+		path := filepath.Join(root, data.OverlayFile)
+		if _, err := os.Stat(path); err == nil {
+			layers = append(layers, data.Layer{Name: data.OverlayFile, Path: path})
+		}
+	}
+	h.configMutex.RLock()
+	overlays := h.dataOverlays
+	h.configMutex.RUnlock()
+	for _, name := range overlays {
+		path := name
+		if !filepath.IsAbs(path) && root != "" {
+			path = filepath.Join(root, path)
+		}
+		layers = append(layers, data.Layer{Name: name, Path: path})
+	}
+	return layers
+}
+
+// isDataFile checks if uri is the base data file or one of the overlays
+func (h *Handler) isDataFile(uri string) bool {
+	path := filepath.Clean(workspace.URIToPath(uri))
+	if h.dataPath != "" && path == filepath.Clean(h.dataPath) {
+		return true
+	}
+	for _, layer := range h.dataLayers() {
+		if path == filepath.Clean(layer.Path) {
+			return true
+		}
+	}
+	// A workspace overlay that was just created or deleted
+	return h.rootURI != "" && path == filepath.Join(workspace.URIToPath(h.rootURI), data.OverlayFile)
+}
+
+// loadOverlays merges the data overlays into the MCS data loaded by New and reports
+// whether new data was swapped in. Errors are logged and leave the base data in place.
+func (h *Handler) loadOverlays(targetRelease string) bool {
+	layers := h.dataLayers()
+	if len(layers) == 0 {
+		return false
+	}
+
+	store, err := data.LoadLayers(h.dataPath, layers)
+	if err != nil {
+		logger.Error("Failed to load data overlays: %v", err)
+		return false
+	}
+	logger.Info("Loaded %d MCS statements with overlays %v", len(store.List), store.Layers)
+	h.setStore(store, targetRelease)
+	return true
+}
+
+// reloadData loads smpe.json and the overlays again and swaps in the new data. Open
+// documents are reparsed, the workspace is re-indexed and diagnostics are republished.
+// If the data cannot be loaded, the current data is kept and the error returned.
+func (h *Handler) reloadData() (*data.Store, error) {
+	h.reloadMutex.Lock()
+	defer h.reloadMutex.Unlock()
+
+	layers := h.dataLayers()
+	store, err := data.LoadLayers(h.dataPath, layers)
+	if err != nil {
+		logger.Error("Failed to reload MCS data, keeping the current data: %v", err)
+		return nil, fmt.Errorf("cannot reload MCS data, keeping the current data: %w", err)
+	}
+	h.validateData(layers)
+	logger.Info("Reloaded %d MCS statements (overlays %v)", len(store.List), store.Layers)
+
+	// configMutex must not be taken while documentsMutex is held
+	h.configMutex.RLock()
+	targetRelease := h.targetRelease
+	h.configMutex.RUnlock()
+
+	// Swap the data and reparse the open documents while no change can be applied
+	h.documentsMutex.Lock()
+	h.setStore(store, targetRelease)
+	type openDocument struct {
+		uri  string
+		text string
+		doc  *parser.Document
+	}
+	var open []openDocument
+	for uri, text := range h.documents {
+		doc := h.providers().parser.Parse(text)
+		h.parsedDocuments[uri] = doc
+		open = append(open, openDocument{uri, text, doc})
+	}
+	h.documentsMutex.Unlock()
+
+	for _, od := range open {
+		h.index.Open(od.uri, od.doc, od.text)
+	}
+
+	h.configMutex.Lock()
+	h.diagnosticsVersion++
+	h.configMutex.Unlock()
+	h.republishAllDiagnostics()
+
+	// Files on disk are indexed again with the new data in the background
+	if h.indexBuilt.Load() {
+		go func() {
+			h.index.Build(context.Background(), h.rootURI)
+			h.republishAllDiagnostics()
+		}()
+	}
+
+	return store, nil
+}
+
+// validateData reports problems in the data file and the overlays, e.g. typos in field
+// names, which load fine but silently lose rules. The embedded smpe.json is not checked.
+func (h *Handler) validateData(layers []data.Layer) {
+	var files []string
+	count := 0
+	report := func(name string, problems []data.Problem, err error) {
+		if err != nil || len(problems) == 0 {
+			return
+		}
+		for _, problem := range problems {
+			logger.Info("Data file problem in %s: %s", name, problem)
+		}
+		files = append(files, name)
+		count += len(problems)
+	}
+
+	if h.dataPath != "" {
+		problems, err := data.Validate(h.dataPath)
+		report(filepath.Base(h.dataPath), problems, err)
+	}
+	for _, layer := range layers {
+		problems, err := data.ValidateLayer(layer)
+		report(layer.Name, problems, err)
+	}
+
+	if count > 0 {
+		h.showMessage(lsp.MessageTypeWarning,
+			fmt.Sprintf("smpe_ls: %d problems in %s, see the server log for details", count, strings.Join(files, ", ")))
+	}
+}
+
+// showMessage shows a message in the client
+func (h *Handler) showMessage(messageType int, message string) {
+	if h.server == nil {
+		return
+	}
+	if err := h.server.SendNotification("window/showMessage", lsp.ShowMessageParams{Type: messageType, Message: message}); err != nil {
+		logger.Error("Failed to show message: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// applyContentChange applies an incremental content change to text
+func applyContentChange(text string, r lsp.Range, newText string) string {
+	start := offsetAt(text, r.Start)
+	end := offsetAt(text, r.End)
+	if end < start {
+		end = start
+	}
+	return text[:start] + newText + text[end:]
+}
+
+// offsetAt converts an LSP position (UTF-16 code units) to a byte offset in text.
+// Positions beyond the end of a line or of the text are clamped.
+func offsetAt(text string, pos lsp.Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		idx := strings.IndexByte(text[offset:], '\n')
+		if idx < 0 {
+			return len(text)
+		}
+		offset += idx + 1
+	}
+
+	units := 0
+	for i, r := range text[offset:] {
+		if units >= pos.Character || r == '\n' {
+			return offset + i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(text)
+}
@@ -2,6 +2,7 @@ package hover
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cybersorcerer/smpe_ls/internal/data"
 	"github.com/cybersorcerer/smpe_ls/internal/logger"
@@ -11,7 +12,8 @@ import (
 
 // Provider provides hover information
 type Provider struct {
-	statements map[string]data.MCSStatement
+	statements    map[string]data.MCSStatement
+	targetRelease string
 }
 
 // NewProvider creates a new hover provider with shared data
@@ -21,6 +23,12 @@ func NewProvider(store *data.Store) *Provider {
 	}
 }
 
+// SetTargetRelease sets the SMP/E release (e.g. V3R6) that definitions are checked against.
+// An empty release disables the check.
+func (p *Provider) SetTargetRelease(release string) {
+	p.targetRelease = release
+}
+
 // GetHoverAST returns hover information using AST-based lookup
 func (p *Provider) GetHoverAST(doc *parser.Document, line, character int) *lsp.Hover {
 	if doc == nil {
@@ -30,7 +38,11 @@ func (p *Provider) GetHoverAST(doc *parser.Document, line, character int) *lsp.H
 	// Find the node at the cursor position
 	node := p.findNodeAtPosition(doc, line, character)
 	if node == nil {
-		return nil
+		// Inline JCL and IEBUPDTE control statements are not part of the node tree
+		if hover := p.createJCLHover(doc, line, character); hover != nil {
+			return hover
+		}
+		return p.createUpdateHover(doc, line, character)
 	}
 
 	logger.Debug("Hover node type: %v, name: %s", node.Type, node.Name)
@@ -50,8 +62,8 @@ func (p *Provider) GetHoverAST(doc *parser.Document, line, character int) *lsp.H
 			return p.createOperandHover(*node.OperandDef)
 		}
 	case parser.NodeTypeParameter:
-		// No hover for parameter values
-		return nil
+		// Parameter values only get a hover explaining their format when they are invalid
+		return p.createFormatHover(node)
 	}
 
 	return nil
@@ -113,6 +125,7 @@ func (p *Provider) createStatementHover(stmt data.MCSStatement) *lsp.Hover {
 
 	// Description (may contain markdown from smpe.json)
 	content += stmt.Description + "\n\n"
+	content += p.formatAvailability(stmt.Since, stmt.Until)
 
 	// Collect required and optional operands
 	var requiredOps []data.Operand
@@ -136,9 +149,21 @@ func (p *Provider) createStatementHover(stmt data.MCSStatement) *lsp.Hover {
 		content += "\n"
 	}
 
+	// Modes with their required operands
+	if len(stmt.Modes) > 0 {
+		if len(requiredOps) == 0 {
+			content += "---\n\n"
+		}
+		content += "**Modes:**\n"
+		for _, mode := range stmt.Modes {
+			content += p.formatModeListItem(mode)
+		}
+		content += "\n"
+	}
+
 	// Optional operands section (limited to first 10)
 	if len(optionalOps) > 0 {
-		if len(requiredOps) == 0 {
+		if len(requiredOps) == 0 && len(stmt.Modes) == 0 {
 			content += "---\n\n"
 		}
 		content += "**Optional Operands:**\n"
@@ -154,6 +179,11 @@ func (p *Provider) createStatementHover(stmt data.MCSStatement) *lsp.Hover {
 		}
 	}
 
+	// Overlay that added or changed the statement definition
+	if stmt.Layer != "" {
+		content += fmt.Sprintf("\n*Defined in overlay `%s`*\n", stmt.Layer)
+	}
+
 	return &lsp.Hover{
 		Contents: lsp.MarkupContent{
 			Kind:  lsp.MarkupKindMarkdown,
@@ -162,6 +192,40 @@ func (p *Provider) createStatementHover(stmt data.MCSStatement) *lsp.Hover {
 	}
 }
 
+// formatAvailability describes the SMP/E releases a definition exists in and warns if
+// it does not exist in the target release. It is empty for definitions without bounds.
+func (p *Provider) formatAvailability(since, until string) string {
+	availability := data.Availability(since, until)
+	if availability == "" {
+		return ""
+	}
+	content := fmt.Sprintf("**Availability:** SMP/E %s\n\n", availability)
+	if !data.InRelease(since, until, p.targetRelease) {
+		content += fmt.Sprintf("⚠️ Not available in the target release SMP/E %s\n\n", strings.ToUpper(p.targetRelease))
+	}
+	return content
+}
+
+// formatModeListItem formats a single mode with its requirements for the mode list
+func (p *Provider) formatModeListItem(mode data.Mode) string {
+	item := fmt.Sprintf("- *%s*", mode.Name)
+	if mode.When != "" {
+		item += fmt.Sprintf(" (with `%s`)", strings.ReplaceAll(mode.When, "|", "` or `"))
+	}
+
+	var requires []string
+	for _, name := range mode.Required {
+		requires = append(requires, fmt.Sprintf("`%s`", name))
+	}
+	for _, group := range mode.RequiredOneOf {
+		requires = append(requires, "one of `"+strings.Join(group, "`, `")+"`")
+	}
+	if len(requires) > 0 {
+		item += " — requires " + strings.Join(requires, ", ")
+	}
+	return item + "\n"
+}
+
 // formatOperandListItem formats a single operand for the operand list
 func (p *Provider) formatOperandListItem(op data.Operand) string {
 	// Use primary name (before any | alias separator)
@@ -193,6 +257,10 @@ func (p *Provider) createOperandHover(operand data.Operand) *lsp.Hover {
 	}
 	if operand.Required {
 		content += " — *required*"
+	} else if operand.RequiredIf != "" {
+		content += fmt.Sprintf(" — *required with %s*", strings.ReplaceAll(operand.RequiredIf, "|", " or "))
+	} else if operand.RequiredUnless != "" {
+		content += fmt.Sprintf(" — *required unless %s*", strings.ReplaceAll(operand.RequiredUnless, "|", " or "))
 	}
 	content += "\n\n"
 
@@ -200,6 +268,7 @@ func (p *Provider) createOperandHover(operand data.Operand) *lsp.Hover {
 	if operand.Description != "" {
 		content += operand.Description + "\n\n"
 	}
+	content += p.formatAvailability(operand.Since, operand.Until)
 
 	// Type and length info on one line
 	if operand.Type != "" || operand.Length > 0 {
@@ -232,15 +301,19 @@ func (p *Provider) createOperandHover(operand data.Operand) *lsp.Hover {
 		}
 		for i := 0; i < displayCount; i++ {
 			val := operand.Values[i]
+			name := fmt.Sprintf("`%s`", val.Name)
+			if availability := data.Availability(val.Since, val.Until); availability != "" {
+				name += fmt.Sprintf(" *(%s)*", availability)
+			}
 			if val.Description != "" {
 				// Truncate long descriptions
 				desc := val.Description
 				if len(desc) > 50 {
 					desc = desc[:47] + "..."
 				}
-				content += fmt.Sprintf("- `%s` — %s\n", val.Name, desc)
+				content += fmt.Sprintf("- %s — %s\n", name, desc)
 			} else {
-				content += fmt.Sprintf("- `%s`\n", val.Name)
+				content += fmt.Sprintf("- %s\n", name)
 			}
 		}
 		if len(operand.Values) > 8 {
@@ -248,6 +321,46 @@ func (p *Provider) createOperandHover(operand data.Operand) *lsp.Hover {
 		}
 	}
 
+	// Overlay that added or changed the operand definition
+	if operand.Layer != "" {
+		content += fmt.Sprintf("\n*Defined in overlay `%s`*\n", operand.Layer)
+	}
+
+	return &lsp.Hover{
+		Contents: lsp.MarkupContent{
+			Kind:  lsp.MarkupKindMarkdown,
+			Value: content,
+		},
+	}
+}
+
+// createFormatHover explains the expected format of a parameter value that does not
+// match its pattern or uses a reserved prefix
+func (p *Provider) createFormatHover(node *parser.Node) *lsp.Hover {
+	value := strings.TrimSpace(node.Value)
+	if value == "" || node.Parent == nil {
+		return nil
+	}
+
+	var content string
+	switch parent := node.Parent; {
+	case parent.Type == parser.NodeTypeStatement && parent.StatementDef != nil:
+		def := parent.StatementDef
+		if !data.MatchPattern(def.Pattern, value) {
+			content = fmt.Sprintf("**Invalid %s** `%s`\n\nExpected %s\n\n**Pattern:** `%s`", def.Parameter, value, def.PatternDescription, def.Pattern)
+		} else if def.ReservedPattern != "" && data.MatchPattern(def.ReservedPattern, value) {
+			content = fmt.Sprintf("**Reserved %s** `%s`\n\n%s", def.Parameter, value, def.ReservedDescription)
+		}
+	case parent.Type == parser.NodeTypeParameter && parent.Parent != nil && parent.Parent.OperandDef != nil:
+		op := parent.Parent.OperandDef
+		if !data.MatchPattern(op.Pattern, value) {
+			content = fmt.Sprintf("**Invalid value in %s** `%s`\n\nExpected %s\n\n**Pattern:** `%s`", op.Name, value, op.PatternDescription, op.Pattern)
+		}
+	}
+	if content == "" {
+		return nil
+	}
+
 	return &lsp.Hover{
 		Contents: lsp.MarkupContent{
 			Kind:  lsp.MarkupKindMarkdown,
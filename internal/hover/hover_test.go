@@ -391,3 +391,191 @@ func TestHoverOnOutOfBoundsPosition(t *testing.T) {
 
 	t.Log("Correctly handles out-of-bounds position")
 }
+
+// Test: Hover explains the expected format of invalid values
+func TestHoverOnInvalidParameterValue(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	text := "++USERMOD(UZ12345) .\n++PTF(UA00001) .\n++VER(Z038) FMID(HBB7-C0) PRE(UA00002) ."
+	doc := p.Parse(text)
+
+	hover := hp.GetHoverAST(doc, 0, 11)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Reserved") {
+		t.Errorf("Expected hover on reserved USERMOD ID, got: %v", hover)
+	}
+
+	if hover := hp.GetHoverAST(doc, 1, 8); hover != nil {
+		t.Errorf("Expected no hover on valid SYSMOD ID, got: %v", hover.Contents.Value)
+	}
+
+	hover = hp.GetHoverAST(doc, 2, 19)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Expected 7 uppercase alphanumeric") {
+		t.Fatalf("Expected hover explaining the FMID format, got: %v", hover)
+	}
+	t.Logf("Hover content:\n%s", hover.Contents.Value)
+
+	if hover := hp.GetHoverAST(doc, 2, 32); hover != nil {
+		t.Errorf("Expected no hover on valid PRE value, got: %v", hover.Contents.Value)
+	}
+}
+
+// Test: Statement hover lists modes with their required operands
+func TestHoverOnStatementWithModes(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	doc := p.Parse("++MOD(MYMOD) DISTLIB(AOSBN1) .\n++MOVE(MYMAC) TODISTLIB(AMACLIB) .")
+
+	hover := hp.GetHoverAST(doc, 0, 2)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "*ADD/REPLACE* — requires `DISTLIB`") ||
+		!strings.Contains(hover.Contents.Value, "*DELETE* (with `DELETE`)") {
+		t.Errorf("Expected modes in ++MOD hover, got: %v", hover)
+	}
+
+	hover = hp.GetHoverAST(doc, 1, 16)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "required with DISTLIB") {
+		t.Errorf("Expected conditional requirement in TODISTLIB hover, got: %v", hover)
+	}
+}
+
+// Test: Hover shows the overlay that defined a statement or operand
+func TestHoverShowsOverlayLayer(t *testing.T) {
+	store, _, _ := createTestProviders()
+	stmt := store.Statements["++USERMOD"]
+	stmt.Layer = ".smpe_ls/overlay.json"
+	stmt.Operands = append([]data.Operand(nil), stmt.Operands...)
+	stmt.Operands[1].Layer = ".smpe_ls/overlay.json"
+	store.Statements["++USERMOD"] = stmt
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	doc := p.Parse("++USERMOD(LU00001) REWORK(2024001) DESC(TEST) .")
+
+	hover := hp.GetHoverAST(doc, 0, 2)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Defined in overlay `.smpe_ls/overlay.json`") {
+		t.Errorf("Expected overlay in statement hover, got: %v", hover)
+	}
+
+	if hover := hp.GetHoverAST(doc, 0, 20); hover == nil || strings.Contains(hover.Contents.Value, "overlay") {
+		t.Errorf("Expected base operand REWORK without overlay, got: %v", hover)
+	}
+
+	hover = hp.GetHoverAST(doc, 0, 36)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Defined in overlay `.smpe_ls/overlay.json`") {
+		t.Errorf("Expected overlay in DESC hover, got: %v", hover)
+	}
+}
+
+// Test: Hover shows the releases a definition is available in
+func TestHoverShowsAvailability(t *testing.T) {
+	store, _, _ := createTestProviders()
+	stmt := store.Statements["++USERMOD"]
+	stmt.Operands = append([]data.Operand(nil), stmt.Operands...)
+	stmt.Operands[1].Since = "V3R7"
+	store.Statements["++USERMOD"] = stmt
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	doc := p.Parse("++USERMOD(LU00001) REWORK(2024001) DESC(TEST) .")
+
+	hover := hp.GetHoverAST(doc, 0, 36)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "**Availability:** SMP/E since V3R7") ||
+		strings.Contains(hover.Contents.Value, "Not available") {
+		t.Errorf("Expected availability without warning in DESC hover, got: %v", hover)
+	}
+
+	if hover := hp.GetHoverAST(doc, 0, 20); hover == nil || strings.Contains(hover.Contents.Value, "Availability") {
+		t.Errorf("Expected REWORK without availability, got: %v", hover)
+	}
+
+	hp.SetTargetRelease("v3r6")
+	hover = hp.GetHoverAST(doc, 0, 36)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Not available in the target release SMP/E V3R6") {
+		t.Errorf("Expected target release warning in DESC hover, got: %v", hover)
+	}
+}
+
+// Test: Hover in the inline JCL of ++JCLIN
+func TestHoverOnInlineJCL(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	doc := p.Parse("++JCLIN .\n" +
+		"//LKED     EXEC PGM=IEWL\n" +
+		"//SYSLMOD  DD   DSN=SYS1.LINKLIB,DISP=SHR\n" +
+		"//AOBJ     DD   DSN=SYS1.AOBJ(MOD1),DISP=SHR\n" +
+		"//SYSLIN   DD   *\n" +
+		"  INCLUDE AOBJ(MOD1)\n" +
+		"  NAME MYLMOD(R)\n")
+
+	tests := []struct {
+		line, character int
+		want            string
+	}{
+		{1, 3, "Runs program `IEWL`"},
+		{1, 12, "**EXEC** statement"},
+		{2, 4, "**DD** `SYSLMOD` in step `LKED`\n\n**Data set:** `SYS1.LINKLIB`"},
+		{2, 16, "**DSN** parameter"},
+		{3, 30, "**Member** `MOD1`\n\nOf data set `SYS1.AOBJ`"},
+		{5, 16, "Included from DD `AOBJ` (`SYS1.AOBJ`)"},
+		{6, 8, "Link-edited in step `LKED` into DD `SYSLMOD` (`SYS1.LINKLIB`)"},
+	}
+	for _, tt := range tests {
+		hover := hp.GetHoverAST(doc, tt.line, tt.character)
+		if hover == nil || !strings.Contains(hover.Contents.Value, tt.want) {
+			t.Errorf("Hover at %d:%d = %v, want %q", tt.line, tt.character, hover, tt.want)
+		}
+	}
+	if hover := hp.GetHoverAST(doc, 2, 39); hover != nil {
+		t.Errorf("Expected no hover on DISP value, got %v", hover)
+	}
+}
+
+// Test: Hover on IEBUPDTE control statements of ++MACUPD
+func TestHoverOnUpdateControlStatement(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	p := parser.NewParser(store.Statements)
+	hp := NewProvider(store)
+
+	doc := p.Parse("++MACUPD(MYMAC) DISTLIB(AMACLIB) .\n" +
+		"./ CHANGE NAME=MYMAC\n" +
+		"./ DELETE SEQ1=00000200,SEQ2=00000300\n" +
+		"./ NUMBER SEQ1=00000400,NEW1=00000410,INCR=10,INSERT=YES\n" +
+		"         LA    R1,0\n")
+
+	tests := []struct {
+		line, character int
+		want            string
+	}{
+		{1, 4, "**./ CHANGE**"},
+		{1, 4, "**Member:** `MYMAC`"},
+		{2, 4, "Deletes records `00000200` to `00000300`"},
+		{3, 40, "**INCR** parameter"},
+		{3, 4, "Inserts 1 record after `00000400`, numbered from `00000410` in steps of `10`"},
+	}
+	for _, tt := range tests {
+		hover := hp.GetHoverAST(doc, tt.line, tt.character)
+		if hover == nil || !strings.Contains(hover.Contents.Value, tt.want) {
+			t.Errorf("Hover at %d:%d = %v, want %q", tt.line, tt.character, hover, tt.want)
+		}
+	}
+	if hover := hp.GetHoverAST(doc, 4, 10); hover != nil {
+		t.Errorf("Expected no hover on data records, got %v", hover)
+	}
+}
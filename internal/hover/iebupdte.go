@@ -0,0 +1,119 @@
+package hover
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// updateOperations describes the operations of IEBUPDTE control statements
+var updateOperations = map[string]string{
+	"ADD":    "Adds a new member. The records following the statement are its content",
+	"CHANGE": "Updates an existing member. Records following the statement replace the records with the same sequence number or are inserted by their sequence number",
+	"REPL":   "Replaces a member with the records following the statement",
+	"REPRO":  "Copies a member unchanged",
+	"NUMBER": "Renumbers existing records, or numbers the records inserted after it (INSERT=YES)",
+	"DELETE": "Deletes the records with sequence numbers from SEQ1 to SEQ2",
+	"ALIAS":  "Creates an alias of the member",
+	"ENDUP":  "Ends the IEBUPDTE input",
+	"LABEL":  "Supplies user labels",
+}
+
+// updateParameters describes the parameters of IEBUPDTE control statements
+var updateParameters = map[string]string{
+	"NAME":   "Member updated; must be the element of the ++MACUPD or ++SRCUPD statement",
+	"LIST":   "LIST=ALL lists the whole member in the output",
+	"SSI":    "System status information stored in the directory entry",
+	"SEQFLD": "Columns and length of the sequence number, by default 738 (columns 73-80)",
+	"NEW":    "Organization of the new master data set",
+	"MEMBER": "Name of the member in the new master data set",
+	"UPDATE": "UPDATE=INPLACE updates the member in place",
+	"LEVEL":  "Update level in the member's header record",
+	"SOURCE": "Source of the update: 0 for user, 1 for IBM",
+	"TOTAL":  "Exit routine and buffer for totaling",
+	"COLUMN": "First column of the sequence number field",
+	"SEQ1":   "First record to renumber or delete, or the record after which records are inserted (INSERT=YES)",
+	"SEQ2":   "Last record to renumber or delete; SEQ1 if omitted",
+	"NEW1":   "Sequence number of the first renumbered or inserted record",
+	"INCR":   "Increment between the sequence numbers of renumbered or inserted records",
+	"INSERT": "INSERT=YES numbers the records following the statement and inserts them after SEQ1",
+}
+
+// createUpdateHover returns the hover for the IEBUPDTE control statements of ++MACUPD and
+// ++SRCUPD at a position: parameters are described, the rest of a control statement
+// explains what it does with its parameter values
+func (p *Provider) createUpdateHover(doc *parser.Document, line, character int) *lsp.Hover {
+	for _, stmt := range doc.Statements {
+		if stmt.Update == nil {
+			continue
+		}
+		for _, control := range stmt.Update.Controls {
+			if line < control.Line || line > control.EndLine {
+				continue
+			}
+			for _, param := range control.Parameters {
+				if jclContains(param.KeywordPos, line, character) {
+					if description, ok := updateParameters[param.Keyword]; ok {
+						return markdownHover(fmt.Sprintf("**%s** parameter\n\n%s", param.Keyword, description))
+					}
+					return nil
+				}
+			}
+			description, ok := updateOperations[control.Operation]
+			if !ok {
+				return nil
+			}
+			content := fmt.Sprintf("**./ %s**\n\n%s", control.Operation, description)
+			if summary := updateSummary(control); summary != "" {
+				content += "\n\n" + summary
+			}
+			return markdownHover(content)
+		}
+	}
+	return nil
+}
+
+// records returns the number of records in words
+func records(n int) string {
+	if n == 1 {
+		return "1 record"
+	}
+	return fmt.Sprintf("%d records", n)
+}
+
+// updateSummary describes what a control statement does with its parameter values
+func updateSummary(control *parser.UpdateControl) string {
+	value := func(keyword string) string {
+		if param := control.Param(keyword); param != nil {
+			return param.Value
+		}
+		return ""
+	}
+	seq1, seq2 := value("SEQ1"), value("SEQ2")
+	if seq2 == "" {
+		seq2 = seq1
+	}
+
+	switch {
+	case control.IsMember() && value("NAME") != "":
+		return fmt.Sprintf("**Member:** `%s` (%s)", value("NAME"), records(len(control.Records)))
+	case control.Operation == "DELETE" && seq1 != "":
+		return fmt.Sprintf("Deletes records `%s` to `%s`", seq1, seq2)
+	case control.Operation == "NUMBER" && value("INSERT") != "":
+		return fmt.Sprintf("Inserts %s after `%s`, numbered from `%s` in steps of `%s`", records(len(control.Records)), seq1, value("NEW1"), value("INCR"))
+	case control.Operation == "NUMBER" && seq1 != "":
+		var b strings.Builder
+		if seq1 == "ALL" {
+			b.WriteString("Renumbers all records")
+		} else {
+			fmt.Fprintf(&b, "Renumbers records `%s` to `%s`", seq1, seq2)
+		}
+		fmt.Fprintf(&b, " from `%s` in steps of `%s`", value("NEW1"), value("INCR"))
+		return b.String()
+	case control.Operation == "ALIAS" && value("NAME") != "":
+		return fmt.Sprintf("**Alias:** `%s`", value("NAME"))
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package hover
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// jclOperations describes the operations of JCL statements
+var jclOperations = map[string]string{
+	"JOB":     "Marks the beginning of a job",
+	"EXEC":    "Marks the beginning of a job step and names the program or procedure it runs",
+	"DD":      "Describes a data set and its processing by the step. SMP/E records the libraries of ++JCLIN steps from their DD statements",
+	"PROC":    "Marks the beginning of a procedure",
+	"PEND":    "Marks the end of an in-stream procedure",
+	"SET":     "Assigns values to symbols",
+	"INCLUDE": "Includes JCL from a member of a JCL library",
+	"JCLLIB":  "Names the libraries searched for procedures and INCLUDE groups",
+	"OUTPUT":  "Specifies processing options for SYSOUT data sets",
+	"IF":      "Marks the beginning of a conditionally run group of steps",
+	"ELSE":    "Marks the steps run when the IF condition is false",
+	"ENDIF":   "Marks the end of an IF group",
+}
+
+// jclParameters describes keyword parameters of JCL statements that ++JCLIN processing uses
+var jclParameters = map[string]string{
+	"PGM":    "Program the step runs. SMP/E recognizes assembler, link-edit, copy and update steps by their program",
+	"PARM":   "Parameters passed to the program; link-edit steps record their attributes, e.g. RENT or REUS",
+	"PROC":   "Procedure the step runs",
+	"DSN":    "Data set name. SMP/E takes the libraries of the target and distribution zone from the DSN of DD statements",
+	"DSNAME": "Data set name. SMP/E takes the libraries of the target and distribution zone from the DSN of DD statements",
+	"DISP":   "Status of the data set and what happens to it at the end of the step",
+	"DDNAME": "Postpones the definition of the data set to a later DD statement of that name",
+	"SYSOUT": "Writes the data set to a SYSOUT class",
+	"UNIT":   "Device the data set resides on",
+	"VOL":    "Volume the data set resides on",
+	"SPACE":  "Space allocated for a new data set",
+	"DCB":    "Data control block attributes such as RECFM and LRECL",
+	"DLM":    "Delimiter that ends the in-stream data instead of /*",
+	"PATH":   "UNIX file the DD statement refers to",
+	"REGION": "Storage available to the step",
+	"COND":   "Return codes for which the step is bypassed",
+}
+
+// jclContains reports whether a position covers line and character
+func jclContains(pos parser.Position, line, character int) bool {
+	return pos.Length > 0 && pos.Line == line && character >= pos.Character && character < pos.Character+pos.Length
+}
+
+// markdownHover creates a hover with markdown content
+func markdownHover(content string) *lsp.Hover {
+	return &lsp.Hover{
+		Contents: lsp.MarkupContent{
+			Kind:  lsp.MarkupKindMarkdown,
+			Value: content,
+		},
+	}
+}
+
+// createJCLHover returns the hover for the inline JCL of ++JCLIN statements at a position:
+// operations and keyword parameters are described, while names of steps, DDs, load modules
+// and members show the statements and data sets they belong to
+func (p *Provider) createJCLHover(doc *parser.Document, line, character int) *lsp.Hover {
+	for _, stmt := range doc.Statements {
+		jcl := stmt.JCL
+		if jcl == nil {
+			continue
+		}
+		for _, s := range jcl.Statements {
+			if line < s.Line || line > s.EndLine {
+				continue
+			}
+			switch {
+			case jclContains(s.NamePos, line, character):
+				return markdownHover(jclStatementContent(jcl, s))
+			case jclContains(s.OpPos, line, character):
+				if description, ok := jclOperations[s.Operation]; ok {
+					return markdownHover(fmt.Sprintf("**%s** statement\n\n%s", s.Operation, description))
+				}
+				return nil
+			}
+			for _, param := range s.Parameters {
+				if jclContains(param.MemberPos, line, character) {
+					return markdownHover(fmt.Sprintf("**Member** `%s`\n\nOf data set `%s`", param.Member, param.DataSetName()))
+				}
+				if jclContains(param.KeywordPos, line, character) {
+					if description, ok := jclParameters[param.Keyword]; ok {
+						return markdownHover(fmt.Sprintf("**%s** parameter\n\n%s", param.Keyword, description))
+					}
+					return nil
+				}
+			}
+			for _, control := range s.Data {
+				for _, name := range control.Names {
+					if jclContains(name.Position, line, character) {
+						return markdownHover(jclNameContent(jcl, s, name))
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jclStatementContent describes a named JCL statement: the program of a step, or the data
+// set and step of a DD statement
+func jclStatementContent(jcl *parser.JCL, s *parser.JCLStatement) string {
+	var b strings.Builder
+	switch s.Operation {
+	case "EXEC":
+		fmt.Fprintf(&b, "**Step** `%s`", s.Name)
+		if pgm := s.Param("PGM"); pgm != nil {
+			fmt.Fprintf(&b, "\n\nRuns program `%s`", pgm.Value)
+		} else if proc := s.Positional(); proc != nil {
+			fmt.Fprintf(&b, "\n\nRuns procedure `%s`", proc.Value)
+		}
+	case "DD":
+		fmt.Fprintf(&b, "**DD** `%s`", s.Name)
+		if step := jcl.Step(s); step != nil {
+			fmt.Fprintf(&b, " in step `%s`", step.Name)
+		}
+		if dsn := s.DSN(); dsn != nil {
+			fmt.Fprintf(&b, "\n\n**Data set:** `%s`", dsn.Value)
+		}
+	default:
+		fmt.Fprintf(&b, "**%s** `%s`", s.Operation, s.Name)
+	}
+	return b.String()
+}
+
+// jclNameContent describes a name in in-stream data: the library a load module is
+// link-edited into or the data set a member is included from
+func jclNameContent(jcl *parser.JCL, dd *parser.JCLStatement, name parser.JCLName) string {
+	var b strings.Builder
+	step := jcl.Step(dd)
+	dataSet := func(ddName string) {
+		if lib := jcl.StepDD(dd, ddName); lib != nil && lib.DSN() != nil {
+			fmt.Fprintf(&b, " (`%s`)", lib.DSN().DataSetName())
+		}
+	}
+	switch name.Kind {
+	case parser.JCLNameLoadModule:
+		fmt.Fprintf(&b, "**Load module** `%s`\n\nLink-edited", name.Value)
+		if step != nil {
+			fmt.Fprintf(&b, " in step `%s`", step.Name)
+		}
+		b.WriteString(" into DD `SYSLMOD`")
+		dataSet("SYSLMOD")
+	case parser.JCLNameAlias:
+		fmt.Fprintf(&b, "**Alias** `%s`\n\nAlias of the load module link-edited", name.Value)
+		if step != nil {
+			fmt.Fprintf(&b, " in step `%s`", step.Name)
+		}
+	default:
+		fmt.Fprintf(&b, "**Member** `%s`", name.Value)
+		if name.DD != "" {
+			fmt.Fprintf(&b, "\n\nIncluded from DD `%s`", name.DD)
+			dataSet(name.DD)
+		} else if step != nil {
+			fmt.Fprintf(&b, "\n\nSelected in step `%s`", step.Name)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,549 @@
+package index
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/references"
+	"github.com/cybersorcerer/smpe_ls/internal/symbols"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// MaxSymbols limits the number of workspace symbols returned for a query
+const MaxSymbols = 500
+
+// Entry is an occurrence of a SYSMOD ID, FMID, element name or DDDEF name in a workspace file
+type Entry struct {
+	references.Symbol
+	URI string
+}
+
+// Location returns the location of the entry
+func (e Entry) Location() lsp.Location {
+	return lsp.Location{
+		URI: e.URI,
+		Range: lsp.Range{
+			Start: e.Position,
+			End:   lsp.Position{Line: e.Position.Line, Character: e.Position.Character + e.Length},
+		},
+	}
+}
+
+// key identifies an indexed name
+type key struct {
+	symbolType references.SymbolType
+	name       string
+}
+
+// file holds the indexed content of one workspace file
+type file struct {
+	entries []Entry
+	symbols []lsp.SymbolInformation
+	text    string
+	hash    uint64 // HashText of text
+	open    bool   // content comes from the editor, not from disk
+}
+
+// Content is the text of an indexed file
+type Content struct {
+	URI  string
+	Text string
+	Hash uint64 // HashText of Text
+}
+
+// HashText returns the FNV-1a hash of a text, which identifies the text in result IDs
+func HashText(text string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(text))
+	return hash.Sum64()
+}
+
+// Index maps SYSMOD IDs, FMIDs, element names and DDDEF names to their locations
+// in all workspace files. Open documents take precedence over their on-disk content.
+// It is safe for concurrent use.
+type Index struct {
+	references *references.Provider
+	symbols    *symbols.Provider
+
+	mu         sync.RWMutex
+	parser     *parser.Parser
+	statements map[string]data.MCSStatement
+	files      map[string]*file
+	names      map[key]map[string]bool // name -> URIs of files containing it
+	generation int                     // incremented whenever the definitions change
+	changed    chan struct{}           // closed and replaced whenever a file changes
+}
+
+// New creates an empty index
+func New(p *parser.Parser, statements map[string]data.MCSStatement) *Index {
+	return &Index{
+		parser:     p,
+		statements: statements,
+		references: references.NewProvider(),
+		symbols:    symbols.NewProvider(),
+		files:      make(map[string]*file),
+		names:      make(map[key]map[string]bool),
+		changed:    make(chan struct{}),
+	}
+}
+
+// SetParser replaces the parser and statement definitions used to index files, e.g. after
+// the MCS data was reloaded. Indexed files keep their entries until they are indexed again.
+func (ix *Index) SetParser(p *parser.Parser, statements map[string]data.MCSStatement) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.parser = p
+	ix.statements = statements
+}
+
+// Build indexes every .smpe file below the workspace root. Files opened in the
+// editor meanwhile are not overwritten. Stops early if ctx is cancelled.
+func (ix *Index) Build(ctx context.Context, rootURI string) {
+	count := 0
+	workspace.WalkFiles(rootURI, func(path string, uri string) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if _, ok := ix.loadFile(path, uri); ok {
+			count++
+		}
+		return nil
+	})
+	logger.Info("Workspace index built: %d files", count)
+}
+
+// Open indexes the editor content of a document.
+// Reports whether the definitions in the workspace changed.
+func (ix *Index) Open(uri string, doc *parser.Document, text string) bool {
+	return ix.update(uri, doc, text, true)
+}
+
+// Close reverts a closed document to its on-disk content, or removes it if it is not on disk.
+// Reports whether the definitions in the workspace changed.
+func (ix *Index) Close(uri string) bool {
+	ix.mu.Lock()
+	if f, ok := ix.files[uri]; ok {
+		f.open = false
+	}
+	ix.mu.Unlock()
+
+	return ix.FileChanged(uri)
+}
+
+// FileChanged re-indexes a file after it was created or changed on disk.
+// Files that are not .smpe files or cannot be read are removed.
+// Reports whether the definitions in the workspace changed.
+func (ix *Index) FileChanged(uri string) bool {
+	if !workspace.IsSMPEFile(uri) {
+		return ix.FileDeleted(uri)
+	}
+	changed, ok := ix.loadFile(workspace.URIToPath(uri), uri)
+	if !ok {
+		return ix.FileDeleted(uri)
+	}
+	return changed
+}
+
+// FileDeleted removes a file deleted on disk, unless it is open in the editor.
+// Reports whether the definitions in the workspace changed.
+func (ix *Index) FileDeleted(uri string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if f, ok := ix.files[uri]; ok && !f.open {
+		ix.remove(uri)
+		ix.notify()
+		if len(f.definitions()) > 0 {
+			ix.generation++
+			return true
+		}
+	}
+	return false
+}
+
+// Generation returns a number that changes whenever the definitions in the index change
+func (ix *Index) Generation() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.generation
+}
+
+// Changed returns a channel that is closed on the next change to an indexed file
+func (ix *Index) Changed() <-chan struct{} {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.changed
+}
+
+// ClosedFiles returns the on-disk content of the indexed files that are not open in the
+// editor, ordered by URI
+func (ix *Index) ClosedFiles() []Content {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var contents []Content
+	for uri, f := range ix.files {
+		if !f.open {
+			contents = append(contents, Content{URI: uri, Text: f.text, Hash: f.hash})
+		}
+	}
+	sort.Slice(contents, func(i, j int) bool { return contents[i].URI < contents[j].URI })
+	return contents
+}
+
+// Lookup returns all occurrences of a name, ordered by URI and position
+func (ix *Index) Lookup(symbolType references.SymbolType, name string) []Entry {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	k := key{symbolType, name}
+	var result []Entry
+	for uri := range ix.names[k] {
+		for _, e := range ix.files[uri].entries {
+			if e.Type == symbolType && e.Name == name {
+				result = append(result, e)
+			}
+		}
+	}
+	sortEntries(result)
+	return result
+}
+
+// At returns the entry at the given position of a file, or nil
+func (ix *Index) At(uri string, line, character int) *Entry {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	f, ok := ix.files[uri]
+	if !ok {
+		return nil
+	}
+	for _, e := range f.entries {
+		if e.Position.Line == line && character >= e.Position.Character && character < e.Position.Character+e.Length {
+			entry := e
+			return &entry
+		}
+	}
+	return nil
+}
+
+// Definitions returns the definitions of a name, ordered by URI and position
+func (ix *Index) Definitions(symbolType references.SymbolType, name string) []Entry {
+	var result []Entry
+	for _, e := range ix.Lookup(symbolType, name) {
+		if e.IsDefinition {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// References returns all occurrences of a name, ordered by URI and position.
+// SYSMOD IDs and FMIDs share one namespace: a ++FUNCTION is referenced both as
+// FMID and in PRE, REQ, SUP, ...
+func (ix *Index) References(symbolType references.SymbolType, name string) []Entry {
+	if symbolType != references.SymbolTypeSYSMOD && symbolType != references.SymbolTypeFMID {
+		return ix.Lookup(symbolType, name)
+	}
+	result := append(ix.Lookup(references.SymbolTypeSYSMOD, name), ix.Lookup(references.SymbolTypeFMID, name)...)
+	sortEntries(result)
+	return result
+}
+
+// Resolve returns the definitions a reference resolves to, ordered by URI and position
+func (ix *Index) Resolve(symbolType references.SymbolType, name string) []Entry {
+	var result []Entry
+	for _, e := range ix.References(symbolType, name) {
+		if e.IsDefinition {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Count returns the number of references (not definitions) to a name
+func (ix *Index) Count(symbolType references.SymbolType, name string) int {
+	count := 0
+	for _, e := range ix.References(symbolType, name) {
+		if !e.IsDefinition {
+			count++
+		}
+	}
+	return count
+}
+
+// Symbols returns the statements of all files whose name contains query (case-insensitive),
+// ordered by URI and limited to MaxSymbols
+func (ix *Index) Symbols(ctx context.Context, query string) ([]lsp.SymbolInformation, error) {
+	query = strings.ToUpper(query)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	uris := make([]string, 0, len(ix.files))
+	for uri := range ix.files {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	results := []lsp.SymbolInformation{}
+	for _, uri := range uris {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, sym := range ix.files[uri].symbols {
+			if query == "" || strings.Contains(strings.ToUpper(sym.Name), query) {
+				results = append(results, sym)
+				if len(results) >= MaxSymbols {
+					return results, nil
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// loadFile indexes a file from disk unless it is open in the editor.
+// Reports whether the definitions changed, and false for ok if the file cannot be read.
+func (ix *Index) loadFile(path string, uri string) (changed bool, ok bool) {
+	ix.mu.RLock()
+	f, exists := ix.files[uri]
+	open := exists && f.open
+	p := ix.parser
+	ix.mu.RUnlock()
+	if open {
+		return false, true
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Debug("index: cannot read %s: %v", path, err)
+		return false, false
+	}
+	text := string(content)
+	return ix.update(uri, p.Parse(text), text, false), true
+}
+
+// update replaces the indexed content of a file.
+// Reports whether the definitions of the file changed.
+func (ix *Index) update(uri string, doc *parser.Document, text string, open bool) bool {
+	f := &file{
+		entries: ix.extract(uri, doc),
+		symbols: ix.symbols.GetSymbolInformation(doc, uri, strings.Split(text, "\n")),
+		text:    text,
+		hash:    HashText(text),
+		open:    open,
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	old, exists := ix.files[uri]
+	if exists && old.open && !open {
+		// The editor content was indexed while the file was being read from disk
+		return false
+	}
+	var before []key
+	if exists {
+		before = old.definitions()
+	}
+	changed := !equalKeys(before, f.definitions())
+	if changed {
+		ix.generation++
+	}
+	ix.remove(uri)
+	ix.files[uri] = f
+	if !exists || old.hash != f.hash || old.open != open {
+		ix.notify()
+	}
+	for _, e := range f.entries {
+		k := key{e.Type, e.Name}
+		if ix.names[k] == nil {
+			ix.names[k] = make(map[string]bool)
+		}
+		ix.names[k][uri] = true
+	}
+	return changed
+}
+
+// notify wakes the callers waiting on Changed. The caller must hold the write lock.
+func (ix *Index) notify() {
+	close(ix.changed)
+	ix.changed = make(chan struct{})
+}
+
+// remove drops a file from the index. The caller must hold the write lock.
+func (ix *Index) remove(uri string) {
+	f, ok := ix.files[uri]
+	if !ok {
+		return
+	}
+	for _, e := range f.entries {
+		k := key{e.Type, e.Name}
+		delete(ix.names[k], uri)
+		if len(ix.names[k]) == 0 {
+			delete(ix.names, k)
+		}
+	}
+	delete(ix.files, uri)
+}
+
+// definitions returns the keys of the names defined in the file, in order of appearance
+func (f *file) definitions() []key {
+	var keys []key
+	for _, e := range f.entries {
+		if e.IsDefinition {
+			keys = append(keys, key{e.Type, e.Name})
+		}
+	}
+	return keys
+}
+
+// equalKeys compares two key lists
+func equalKeys(a, b []key) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// extract collects the indexed names of a document
+func (ix *Index) extract(uri string, doc *parser.Document) []Entry {
+	if doc == nil {
+		return nil
+	}
+
+	var entries []Entry
+	for _, s := range ix.references.Symbols(doc) {
+		entries = append(entries, Entry{Symbol: s, URI: uri})
+	}
+
+	for _, stmt := range doc.Statements {
+		// Element definitions and updates: ++MAC(name), ++SRCUPD(name), ...
+		if ix.isElementStatement(stmt.Name) {
+			for _, child := range stmt.Children {
+				if child.Type == parser.NodeTypeParameter && child.Parent == stmt && child.Value != "" {
+					entries = append(entries, newEntry(uri, child, references.SymbolTypeElement, !isElementUpdate(stmt.Name), stmt.Name))
+				}
+			}
+		}
+
+		// DDDEF references
+		for _, child := range stmt.Children {
+			if child.Type != parser.NodeTypeOperand || !isDDDEFOperand(child.Name) {
+				continue
+			}
+			for _, param := range child.Children {
+				if param.Type != parser.NodeTypeParameter {
+					continue
+				}
+				for _, item := range listItems(param) {
+					entries = append(entries, newEntry(uri, item, references.SymbolTypeDDDEF, false, child.Name))
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+// isElementStatement checks if the statement defines or updates an element
+func (ix *Index) isElementStatement(name string) bool {
+	switch name {
+	case "++MAC", "++MACUPD", "++MOD", "++SRC", "++SRCUPD", "++ZAP", "++JAR", "++JARUPD", "++PROGRAM":
+		return true
+	}
+	ix.mu.RLock()
+	stmt, ok := ix.statements[name]
+	ix.mu.RUnlock()
+	return ok && (stmt.Type == "Data Element MCS" || stmt.Type == "HFS")
+}
+
+// isElementUpdate checks if the statement updates an existing element instead of defining it
+func isElementUpdate(name string) bool {
+	switch name {
+	case "++MACUPD", "++SRCUPD", "++ZAP", "++JARUPD":
+		return true
+	}
+	return false
+}
+
+// isDDDEFOperand checks if the operand names a DDDEF
+func isDDDEFOperand(name string) bool {
+	switch name {
+	case "DISTLIB", "SYSLIB", "TXLIB":
+		return true
+	}
+	return false
+}
+
+// listItems returns the individual list items of an operand parameter
+func listItems(param *parser.Node) []*parser.Node {
+	if len(param.Children) > 0 {
+		var items []*parser.Node
+		for _, item := range param.Children {
+			if item.Type == parser.NodeTypeParameter && item.Value != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	var items []*parser.Node
+	offset := 0
+	for _, part := range strings.Split(param.Value, ",") {
+		name := strings.TrimSpace(part)
+		if name != "" {
+			items = append(items, &parser.Node{
+				Type:     parser.NodeTypeParameter,
+				Value:    name,
+				Position: parser.Position{Line: param.Position.Line, Character: param.Position.Character + offset + strings.Index(part, name)},
+			})
+		}
+		offset += len(part) + 1
+	}
+	return items
+}
+
+// newEntry creates an entry for a parameter node
+func newEntry(uri string, node *parser.Node, symbolType references.SymbolType, definition bool, context string) Entry {
+	return Entry{
+		Symbol: references.Symbol{
+			Name:         node.Value,
+			Type:         symbolType,
+			Position:     lsp.Position{Line: node.Position.Line, Character: node.Position.Character},
+			Length:       len(node.Value),
+			IsDefinition: definition,
+			Context:      context,
+		},
+		URI: uri,
+	}
+}
+
+// sortEntries orders entries by URI and position
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		if a.Position.Line != b.Position.Line {
+			return a.Position.Line < b.Position.Line
+		}
+		return a.Position.Character < b.Position.Character
+	})
+}
@@ -0,0 +1,160 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/internal/references"
+	"github.com/cybersorcerer/smpe_ls/internal/workspace"
+)
+
+func newTestIndex(t *testing.T, files map[string]string) (*Index, string) {
+	t.Helper()
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := New(parser.NewParser(store.Statements), store.Statements)
+	ix.Build(context.Background(), workspace.PathToURI(root))
+	return ix, root
+}
+
+func uriOf(root, name string) string {
+	return workspace.PathToURI(filepath.Join(root, name))
+}
+
+func TestIndexAcrossFiles(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{
+		"base.smpe": "++FUNCTION(HXY1100).\n++PTF(UA00001) FMID(HXY1100).\n++MAC(MYMAC) DISTLIB(AMACLIB).\n",
+		"fix.smpe":  "++PTF(UA00002) FMID(HXY1100)\n  PRE(UA00001,HXY1100).\n++MACUPD(MYMAC) DISTLIB(AMACLIB).\n",
+		"notes.txt": "++PTF(UA00003).\n",
+	})
+
+	defs := ix.Definitions(references.SymbolTypeSYSMOD, "UA00001")
+	if len(defs) != 1 || defs[0].URI != uriOf(root, "base.smpe") {
+		t.Fatalf("Expected definition of UA00001 in base.smpe, got %+v", defs)
+	}
+	if n := ix.Count(references.SymbolTypeSYSMOD, "UA00001"); n != 1 {
+		t.Errorf("Expected 1 reference to UA00001, got %d", n)
+	}
+	if n := ix.Count(references.SymbolTypeFMID, "HXY1100"); n != 3 {
+		t.Errorf("Expected 3 references to HXY1100, got %d", n)
+	}
+	if got := ix.Lookup(references.SymbolTypeSYSMOD, "UA00003"); len(got) != 0 {
+		t.Errorf("Non-.smpe files must not be indexed, got %+v", got)
+	}
+
+	elements := ix.Lookup(references.SymbolTypeElement, "MYMAC")
+	if len(elements) != 2 || !elements[0].IsDefinition || elements[1].IsDefinition {
+		t.Errorf("Expected ++MAC definition and ++MACUPD reference, got %+v", elements)
+	}
+	if got := ix.Lookup(references.SymbolTypeDDDEF, "AMACLIB"); len(got) != 2 {
+		t.Errorf("Expected 2 DDDEF references, got %+v", got)
+	}
+
+	entry := ix.At(uriOf(root, "fix.smpe"), 1, 8)
+	if entry == nil || entry.Name != "UA00001" || entry.Context != "PRE" {
+		t.Errorf("Expected PRE reference at cursor, got %+v", entry)
+	}
+
+	// A ++FUNCTION is resolved from SYSMOD references as well
+	if defs := ix.Resolve(references.SymbolTypeSYSMOD, "HXY1100"); len(defs) != 1 || defs[0].Context != "++FUNCTION" {
+		t.Errorf("Expected PRE reference to resolve to the ++FUNCTION, got %+v", defs)
+	}
+
+	syms, err := ix.Symbols(context.Background(), "ua0000")
+	if err != nil || len(syms) != 2 {
+		t.Errorf("Expected 2 PTF symbols, got %+v (%v)", syms, err)
+	}
+}
+
+func TestIndexOpenDocumentsTakePrecedence(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{
+		"a.smpe": "++PTF(UA00001).\n",
+	})
+	uri := uriOf(root, "a.smpe")
+	p := ix.parser
+
+	generation := ix.Generation()
+	text := "++PTF(UA00009).\n"
+	if !ix.Open(uri, p.Parse(text), text) || ix.Generation() == generation {
+		t.Error("Replacing a definition must be reported as a change")
+	}
+	if ix.Open(uri, p.Parse(text+"++HOLD(UA00009).\n"), text) {
+		t.Error("Adding a reference must not be reported as a definitions change")
+	}
+	if len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00001")) != 0 {
+		t.Error("On-disk content must be replaced by the open document")
+	}
+
+	// Changes on disk do not override the editor content
+	ix.FileChanged(uri)
+	ix.FileDeleted(uri)
+	if len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00009")) != 1 {
+		t.Error("Open document must stay indexed")
+	}
+
+	ix.Close(uri)
+	if len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00001")) != 1 || len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00009")) != 0 {
+		t.Error("Closing must revert to the on-disk content")
+	}
+
+	os.Remove(filepath.Join(root, "a.smpe"))
+	ix.FileDeleted(uri)
+	if len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00001")) != 0 {
+		t.Error("Deleted file must be removed")
+	}
+
+	// Documents that exist only in the editor are removed on close
+	untitled := "untitled:Untitled-1"
+	ix.Open(untitled, p.Parse(text), text)
+	ix.Close(untitled)
+	if len(ix.Definitions(references.SymbolTypeSYSMOD, "UA00009")) != 0 {
+		t.Error("Closed editor-only document must be removed")
+	}
+}
+
+func TestIndexClosedFiles(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{
+		"a.smpe": "++PTF(UA00001).\n",
+		"b.smpe": "++PTF(UA00002).\n",
+	})
+	p := ix.parser
+
+	closed := ix.ClosedFiles()
+	if len(closed) != 2 || closed[0].URI != uriOf(root, "a.smpe") || closed[0].Text != "++PTF(UA00001).\n" || closed[0].Hash != HashText(closed[0].Text) {
+		t.Fatalf("Expected the on-disk content of both files, got %+v", closed)
+	}
+
+	// Re-indexing unchanged files is not a change
+	changed := ix.Changed()
+	ix.Build(context.Background(), workspace.PathToURI(root))
+	select {
+	case <-changed:
+		t.Error("Unchanged files must not be reported as changed")
+	default:
+	}
+
+	text := "++PTF(UA00009).\n"
+	ix.Open(uriOf(root, "a.smpe"), p.Parse(text), text)
+	select {
+	case <-changed:
+	default:
+		t.Error("Opening a document must be reported as a change")
+	}
+	if closed := ix.ClosedFiles(); len(closed) != 1 || closed[0].URI != uriOf(root, "b.smpe") {
+		t.Errorf("Expected only the closed file, got %+v", closed)
+	}
+}
@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Columns of the sequence number of IEBUPDTE records (0-based, end exclusive)
+const (
+	updateSequenceStart = 72
+	updateSequenceEnd   = 80
+)
+
+// updateOperations lists the operations of IEBUPDTE control statements
+var updateOperations = map[string]bool{
+	"ADD": true, "CHANGE": true, "REPL": true, "REPRO": true, "NUMBER": true,
+	"DELETE": true, "ALIAS": true, "ENDUP": true, "LABEL": true,
+}
+
+// Update is the inline IEBUPDTE input of ++MACUPD and ++SRCUPD
+type Update struct {
+	Controls []*UpdateControl
+	Errors   []ParseError
+}
+
+// UpdateControl is an IEBUPDTE control statement (./ in columns 1-2) with the data records
+// following it up to the next control statement
+type UpdateControl struct {
+	Name       string // Name field; empty if omitted
+	NamePos    Position
+	Operation  string // CHANGE, ADD, NUMBER, DELETE, ...
+	OpPos      Position
+	Parameters []*UpdateParameter
+	Line       int // First line
+	EndLine    int // Last line, including continuation lines
+	Records    []UpdateRecord
+}
+
+// UpdateParameter is a keyword parameter of a control statement, e.g. SEQ1=00000100
+type UpdateParameter struct {
+	Keyword    string
+	KeywordPos Position
+	Value      string
+	ValuePos   Position
+}
+
+// UpdateRecord is a data record inserted or replaced by the update
+type UpdateRecord struct {
+	Line        int
+	Sequence    string // Columns 73-80 without blanks; empty if not numbered
+	SequencePos Position
+}
+
+// Param returns the parameter with the given keyword, if any
+func (c *UpdateControl) Param(keyword string) *UpdateParameter {
+	for _, param := range c.Parameters {
+		if param.Keyword == keyword {
+			return param
+		}
+	}
+	return nil
+}
+
+// IsMember reports whether the control statement starts the update of a member: ADD,
+// CHANGE, REPL or REPRO
+func (c *UpdateControl) IsMember() bool {
+	switch c.Operation {
+	case "ADD", "CHANGE", "REPL", "REPRO":
+		return true
+	}
+	return false
+}
+
+// LastLine returns the last line of the control statement and its data records
+func (c *UpdateControl) LastLine() int {
+	if n := len(c.Records); n > 0 {
+		return c.Records[n-1].Line
+	}
+	return c.EndLine
+}
+
+// parseUpdate parses the IEBUPDTE input of ++MACUPD and ++SRCUPD. firstLine is the
+// document line of lines[0].
+func parseUpdate(lines []string, firstLine int) *Update {
+	u := &Update{}
+	var current *UpdateControl
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case strings.HasPrefix(line, "./"):
+			current, i = u.parseControl(lines, i, firstLine)
+			u.Controls = append(u.Controls, current)
+		case current == nil:
+			u.Errors = append(u.Errors, ParseError{
+				Message:  "Data record before the first IEBUPDTE control statement; start the update with ./ CHANGE NAME=element",
+				Position: Position{Line: firstLine + i, Character: 0, Length: len([]rune(line))},
+			})
+			i++
+		default:
+			current.Records = append(current.Records, newUpdateRecord(line, firstLine+i))
+			i++
+		}
+	}
+	return u
+}
+
+// newUpdateRecord creates a data record from a line
+func newUpdateRecord(line string, lineNum int) UpdateRecord {
+	record := UpdateRecord{Line: lineNum}
+	runes := []rune(line)
+	if len(runes) <= updateSequenceStart {
+		return record
+	}
+	field := runes[updateSequenceStart:min(len(runes), updateSequenceEnd)]
+	start := skipBlanks(field, 0)
+	record.Sequence = strings.TrimSpace(string(field))
+	if record.Sequence != "" {
+		record.SequencePos = Position{Line: lineNum, Character: updateSequenceStart + start, Length: len([]rune(record.Sequence))}
+	}
+	return record
+}
+
+// error records a syntax error
+func (u *Update) error(pos Position, message string) {
+	u.Errors = append(u.Errors, ParseError{Message: message, Position: pos})
+}
+
+// parseControl parses the control statement starting at lines[i] with its continuation
+// lines and returns it with the index of the line after it. A control statement is
+// continued by ending its parameters with a comma or a character in column 72.
+func (u *Update) parseControl(lines []string, i, firstLine int) (*UpdateControl, int) {
+	record := jclRecord(lines[i])
+	line := firstLine + i
+	control := &UpdateControl{Line: line, EndLine: line}
+
+	// Name field in column 3
+	col := 2
+	if col < len(record) && record[col] != ' ' {
+		for col < len(record) && record[col] != ' ' {
+			col++
+		}
+		control.Name = string(record[2:col])
+		control.NamePos = Position{Line: line, Character: 2, Length: col - 2}
+	}
+
+	start := skipBlanks(record, col)
+	col = start
+	for col < len(record) && record[col] != ' ' {
+		col++
+	}
+	if start == col {
+		u.error(Position{Line: line, Character: 0, Length: len(record)}, "IEBUPDTE control statement has no operation")
+		return control, i + 1
+	}
+	control.Operation = string(record[start:col])
+	control.OpPos = Position{Line: line, Character: start, Length: col - start}
+	if !updateOperations[control.Operation] {
+		u.error(control.OpPos, "Unknown IEBUPDTE operation "+control.Operation+"; expected ADD, CHANGE, REPL, REPRO, NUMBER, DELETE, ALIAS or ENDUP")
+		return control, i + 1
+	}
+
+	var chars []jclChar
+	col = skipBlanks(record, col)
+	next := i + 1
+	for {
+		c := col
+		for ; c < len(record) && record[c] != ' '; c++ {
+			chars = append(chars, jclChar{r: record[c], line: line, col: c})
+		}
+		indicator := []rune(strings.TrimRight(lines[next-1], "\r"))
+		continued := (c > col && record[c-1] == ',') || (len(indicator) > jclColumns && indicator[jclColumns] != ' ')
+		if !continued {
+			break
+		}
+		if next >= len(lines) || !strings.HasPrefix(lines[next], "./ ") || skipBlanks(jclRecord(lines[next]), 2) >= len(jclRecord(lines[next])) {
+			u.error(Position{Line: line, Character: max(c-1, 0), Length: 1},
+				"Bad continuation: the control statement is continued, but the next line is not a ./ continuation line")
+			break
+		}
+		record = jclRecord(lines[next])
+		line = firstLine + next
+		col = skipBlanks(record, 2)
+		control.EndLine = line
+		next++
+	}
+
+	for _, item := range splitJCL(chars, ',') {
+		if len(item) == 0 {
+			continue
+		}
+		keyword, value, found := strings.Cut(jclText(item), "=")
+		if !found || keyword == "" {
+			u.error(jclPosition(item), "Invalid IEBUPDTE parameter "+jclText(item)+"; expected keyword=value")
+			continue
+		}
+		n := len([]rune(keyword))
+		control.Parameters = append(control.Parameters, &UpdateParameter{
+			Keyword:    keyword,
+			KeywordPos: jclPosition(item[:n]),
+			Value:      value,
+			ValuePos:   jclPosition(item[n+1:]),
+		})
+	}
+	return control, next
+}
+
+// shifted returns a copy of u moved by delta lines
+func (u *Update) shifted(delta int) *Update {
+	if u == nil {
+		return nil
+	}
+	// Positions of omitted fields are left unset
+	shift := func(pos Position) Position {
+		if pos.Length > 0 {
+			pos.Line += delta
+		}
+		return pos
+	}
+	clone := &Update{}
+	for _, c := range u.Controls {
+		control := *c
+		control.NamePos, control.OpPos = shift(c.NamePos), shift(c.OpPos)
+		control.Line += delta
+		control.EndLine += delta
+		control.Parameters, control.Records = nil, nil
+		for _, p := range c.Parameters {
+			param := *p
+			param.KeywordPos, param.ValuePos = shift(p.KeywordPos), shift(p.ValuePos)
+			control.Parameters = append(control.Parameters, &param)
+		}
+		for _, r := range c.Records {
+			r.Line += delta
+			r.SequencePos = shift(r.SequencePos)
+			control.Records = append(control.Records, r)
+		}
+		clone.Controls = append(clone.Controls, &control)
+	}
+	for _, e := range u.Errors {
+		e.Position = shift(e.Position)
+		clone.Errors = append(clone.Errors, e)
+	}
+	return clone
+}
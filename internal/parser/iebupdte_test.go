@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+)
+
+// seq pads an IEBUPDTE record to column 72 and appends a sequence number
+func seq(record, number string) string {
+	return record + strings.Repeat(" ", 72-len(record)) + number
+}
+
+// parseTestUpdate parses text with the built-in smpe.json and returns the IEBUPDTE input
+// of the first ++MACUPD or ++SRCUPD statement
+func parseTestUpdate(t *testing.T, text string) *Update {
+	t.Helper()
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range NewParser(store.Statements).Parse(text).Statements {
+		if stmt.Update != nil {
+			return stmt.Update
+		}
+	}
+	t.Fatal("No parsed ++MACUPD or ++SRCUPD statement")
+	return nil
+}
+
+func TestParseUpdate(t *testing.T) {
+	text := "++MACUPD(MYMAC) DISTLIB(AMACLIB) .\n" +
+		"./ CHANGE NAME=MYMAC\n" +
+		seq("         MVC   A,B", "00000150") + "\n" +
+		"./ DELETE SEQ1=00000200,SEQ2=00000300\n" +
+		"./ NUMBER SEQ1=00000400,NEW1=00000410,\n" +
+		"./        INCR=10,INSERT=YES\n" +
+		"         LA    R1,0\n" +
+		"./ ENDUP\n"
+	u := parseTestUpdate(t, text)
+	if len(u.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", u.Errors)
+	}
+
+	var ops []string
+	for _, c := range u.Controls {
+		ops = append(ops, c.Operation)
+	}
+	if want := []string{"CHANGE", "DELETE", "NUMBER", "ENDUP"}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("Operations = %v, want %v", ops, want)
+	}
+
+	change := u.Controls[0]
+	if !change.IsMember() || change.Param("NAME").Value != "MYMAC" || change.LastLine() != 2 {
+		t.Errorf("CHANGE = %+v", change)
+	}
+	if r := change.Records[0]; r.Sequence != "00000150" || r.SequencePos != (Position{Line: 2, Character: 72, Length: 8}) {
+		t.Errorf("Record = %+v", r)
+	}
+
+	number := u.Controls[2]
+	if number.EndLine != 5 || number.Param("INCR") == nil || number.Param("INCR").ValuePos != (Position{Line: 5, Character: 15, Length: 2}) {
+		t.Errorf("Continued NUMBER = %+v", number)
+	}
+	if len(number.Records) != 1 || number.Records[0].Sequence != "" {
+		t.Errorf("Expected an unnumbered inserted record, got %+v", number.Records)
+	}
+}
+
+func TestParseUpdateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		error string
+	}{
+		{"data first", "         MVC   A,B\n./ CHANGE NAME=MYMAC", "before the first IEBUPDTE control statement"},
+		{"operation", "./ MODIFY NAME=MYMAC", "Unknown IEBUPDTE operation MODIFY"},
+		{"parameter", "./ CHANGE MYMAC", "Invalid IEBUPDTE parameter MYMAC"},
+		{"continuation", "./ DELETE SEQ1=00000100,\n         MVC   A,B", "Bad continuation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := parseTestUpdate(t, "++SRCUPD(MYMAC) DISTLIB(ASRCLIB) .\n"+tt.input+"\n")
+			if len(u.Errors) != 1 || !strings.Contains(u.Errors[0].Message, tt.error) {
+				t.Errorf("Errors = %v, want %q", u.Errors, tt.error)
+			}
+		})
+	}
+}
@@ -0,0 +1,607 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jclColumns is the number of columns of a JCL record holding statement text; column 72
+// is the continuation indicator and columns 73-80 may hold sequence numbers
+const jclColumns = 71
+
+// jclContinueColumn is the last column (0-based) continued parameters may start in
+const jclContinueColumn = 15
+
+// jclOperations lists the operations of JCL statements
+var jclOperations = map[string]bool{
+	"JOB": true, "EXEC": true, "DD": true, "PROC": true, "PEND": true, "SET": true,
+	"IF": true, "THEN": true, "ELSE": true, "ENDIF": true, "INCLUDE": true, "JCLLIB": true,
+	"OUTPUT": true, "CNTL": true, "ENDCNTL": true, "XMIT": true, "COMMAND": true,
+	"EXPORT": true, "SCHEDULE": true, "NOTIFY": true,
+}
+
+// JCL is the inline JCL of a ++JCLIN statement
+type JCL struct {
+	Statements []*JCLStatement
+	Comments   []Position // Comment statements (//*) and comment fields
+	Errors     []ParseError
+}
+
+// JCLStatement is a JCL statement such as JOB, EXEC or DD, including its continuation lines
+type JCLStatement struct {
+	Name       string // Name field, e.g. the step or DD name; empty if omitted
+	NamePos    Position
+	Operation  string // JOB, EXEC, DD, ...
+	OpPos      Position
+	Parameters []*JCLParameter
+	Line       int // First line
+	EndLine    int // Last line, including continuation lines and in-stream data
+
+	// Data holds the control statements of the in-stream data of a DD * or DD DATA
+	// statement that name load modules and members
+	Data []*JCLControl
+}
+
+// JCLParameter is a positional or keyword parameter, e.g. PGM=IEWL or DSN=SYS1.LINKLIB(MOD1)
+type JCLParameter struct {
+	Keyword    string // e.g. DSN; empty for positional parameters
+	KeywordPos Position
+	Value      string     // Parts continued on several lines are joined
+	ValueParts []Position // Position of the value on each line
+
+	// Member is the member of a DSN or DSNAME parameter such as SYS1.LINKLIB(MOD1)
+	Member    string
+	MemberPos Position
+}
+
+// JCLNameKind tells what a name in a control statement denotes
+type JCLNameKind int
+
+const (
+	JCLNameLoadModule JCLNameKind = iota // Link-edit NAME statement
+	JCLNameMember                        // Link-edit INCLUDE or IEBCOPY SELECT statement
+	JCLNameAlias                         // Link-edit ALIAS statement
+)
+
+// JCLControl is a control statement of in-stream data naming load modules or members, e.g.
+// a link-edit NAME or INCLUDE statement or an IEBCOPY SELECT statement
+type JCLControl struct {
+	Operation string
+	OpPos     Position
+	Names     []JCLName
+}
+
+// JCLName is a load module, member or alias name in a control statement
+type JCLName struct {
+	Kind     JCLNameKind
+	Value    string
+	DD       string // DD the member is included from (INCLUDE)
+	Position Position
+}
+
+// Param returns the keyword parameter with the given keyword, if any
+func (s *JCLStatement) Param(keyword string) *JCLParameter {
+	for _, param := range s.Parameters {
+		if param.Keyword == keyword {
+			return param
+		}
+	}
+	return nil
+}
+
+// Positional returns the first positional parameter, if any
+func (s *JCLStatement) Positional() *JCLParameter {
+	if len(s.Parameters) > 0 && s.Parameters[0].Keyword == "" {
+		return s.Parameters[0]
+	}
+	return nil
+}
+
+// DSN returns the DSN or DSNAME parameter of a DD statement, if any
+func (s *JCLStatement) DSN() *JCLParameter {
+	if param := s.Param("DSN"); param != nil {
+		return param
+	}
+	return s.Param("DSNAME")
+}
+
+// DataSetName returns the data set name of a DSN parameter without its member
+func (p *JCLParameter) DataSetName() string {
+	if p.Member == "" {
+		return p.Value
+	}
+	name, _, _ := strings.Cut(p.Value, "(")
+	return name
+}
+
+// Step returns the EXEC statement of the step a statement belongs to, or nil
+func (j *JCL) Step(stmt *JCLStatement) *JCLStatement {
+	var step *JCLStatement
+	for _, s := range j.Statements {
+		switch s.Operation {
+		case "JOB":
+			step = nil
+		case "EXEC":
+			step = s
+		}
+		if s == stmt {
+			return step
+		}
+	}
+	return nil
+}
+
+// StepDD returns the DD statement with the given name in the step of stmt, or nil
+func (j *JCL) StepDD(stmt *JCLStatement, name string) *JCLStatement {
+	step := j.Step(stmt)
+	for _, s := range j.Statements {
+		if s.Operation == "DD" && s.Name == name && j.Step(s) == step {
+			return s
+		}
+	}
+	return nil
+}
+
+// jclChar is a character of a JCL operand field with its position
+type jclChar struct {
+	r    rune
+	line int
+	col  int
+}
+
+// jclText returns the text of characters
+func jclText(chars []jclChar) string {
+	runes := make([]rune, len(chars))
+	for i, c := range chars {
+		runes[i] = c.r
+	}
+	return string(runes)
+}
+
+// jclParts returns the positions of characters on each line they span
+func jclParts(chars []jclChar) []Position {
+	var parts []Position
+	for _, c := range chars {
+		if n := len(parts); n > 0 && parts[n-1].Line == c.line && parts[n-1].Character+parts[n-1].Length == c.col {
+			parts[n-1].Length++
+			continue
+		}
+		parts = append(parts, Position{Line: c.line, Character: c.col, Length: 1})
+	}
+	return parts
+}
+
+// jclPosition returns the position of characters on the line of the first one
+func jclPosition(chars []jclChar) Position {
+	if parts := jclParts(chars); len(parts) > 0 {
+		return parts[0]
+	}
+	return Position{}
+}
+
+// splitJCL splits characters at separators outside parentheses and quotes
+func splitJCL(chars []jclChar, sep rune) [][]jclChar {
+	var items [][]jclChar
+	depth, inQuote, start := 0, false, 0
+	for i, c := range chars {
+		switch {
+		case c.r == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c.r == '(':
+			depth++
+		case c.r == ')':
+			depth--
+		case c.r == sep && depth == 0:
+			items = append(items, chars[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, chars[start:])
+}
+
+// trimJCLParens removes the parentheses enclosing characters, if any
+func trimJCLParens(chars []jclChar) []jclChar {
+	if len(chars) >= 2 && chars[0].r == '(' && chars[len(chars)-1].r == ')' {
+		return chars[1 : len(chars)-1]
+	}
+	return chars
+}
+
+// jclRecord returns the statement columns of a JCL line
+func jclRecord(line string) []rune {
+	record := []rune(strings.TrimRight(line, "\r"))
+	if len(record) > jclColumns {
+		record = record[:jclColumns]
+	}
+	return record
+}
+
+// skipBlanks returns the first column from col that is not blank
+func skipBlanks(record []rune, col int) int {
+	for col < len(record) && record[col] == ' ' {
+		col++
+	}
+	return col
+}
+
+// isJCLContinuation reports whether a line continues a JCL statement: // followed by a
+// blank name field and parameters
+func isJCLContinuation(line string) bool {
+	record := jclRecord(line)
+	return len(record) > 2 && record[0] == '/' && record[1] == '/' && record[2] == ' ' &&
+		skipBlanks(record, 2) < len(record)
+}
+
+// isJCLName reports whether name is a valid JCL name: a letter or national character
+// followed by letters, digits and national characters
+func isJCLName(name string) bool {
+	for i, r := range name {
+		alpha := (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || r == '@' || r == '#' || r == '$'
+		if !alpha && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// parseJCL parses the inline JCL of a ++JCLIN statement. firstLine is the document line
+// of lines[0].
+func parseJCL(lines []string, firstLine int) *JCL {
+	j := &JCL{}
+	for i := 0; i < len(lines); {
+		record := jclRecord(lines[i])
+		text := string(record)
+		switch {
+		case strings.TrimSpace(text) == "":
+			i++
+		case strings.HasPrefix(text, "//*"):
+			j.Comments = append(j.Comments, Position{Line: firstLine + i, Character: 0, Length: len(record)})
+			i++
+		case strings.HasPrefix(text, "/*"), strings.TrimSpace(text) == "//":
+			// Delimiter, JES2 control or null statement
+			i++
+		case strings.HasPrefix(text, "//"):
+			var stmt *JCLStatement
+			stmt, i = j.parseStatement(lines, i, firstLine)
+			j.Statements = append(j.Statements, stmt)
+			i = j.parseData(stmt, lines, i, firstLine)
+		default:
+			// In-stream data without a DD * statement
+			i++
+		}
+	}
+	return j
+}
+
+// error records a syntax error
+func (j *JCL) error(pos Position, format string, args ...any) {
+	j.Errors = append(j.Errors, ParseError{Message: fmt.Sprintf(format, args...), Position: pos})
+}
+
+// parseStatement parses the JCL statement starting at lines[i] with its continuation lines
+// and returns it with the index of the line after it
+func (j *JCL) parseStatement(lines []string, i, firstLine int) (*JCLStatement, int) {
+	record := jclRecord(lines[i])
+	line := firstLine + i
+	stmt := &JCLStatement{Line: line, EndLine: line}
+
+	// Name field in column 3
+	col := 2
+	if col < len(record) && record[col] != ' ' {
+		for col < len(record) && record[col] != ' ' {
+			col++
+		}
+		stmt.Name = string(record[2:col])
+		stmt.NamePos = Position{Line: line, Character: 2, Length: col - 2}
+		for _, part := range strings.Split(stmt.Name, ".") {
+			if !isJCLName(part) {
+				j.error(stmt.NamePos, "Invalid JCL name %s: names start with a letter, @, # or $ followed by letters, digits, @, # or $", stmt.Name)
+				break
+			}
+		}
+	}
+
+	// Operation field
+	start := skipBlanks(record, col)
+	col = start
+	for col < len(record) && record[col] != ' ' {
+		col++
+	}
+	if start == col {
+		j.error(Position{Line: line, Character: 0, Length: len(record)}, "JCL statement has no operation")
+		return stmt, i + 1
+	}
+	stmt.Operation = string(record[start:col])
+	stmt.OpPos = Position{Line: line, Character: start, Length: col - start}
+	switch {
+	case strings.ContainsAny(stmt.Operation, "=,"):
+		j.error(stmt.OpPos, "Parameters on a line of their own: end the previous line with a comma to continue it")
+		return stmt, i + 1
+	case !jclOperations[stmt.Operation]:
+		j.error(stmt.OpPos, "Unknown JCL operation %s", stmt.Operation)
+		return stmt, i + 1
+	case stmt.Operation == "IF" || stmt.Operation == "ELSE" || stmt.Operation == "ENDIF":
+		// Relational expressions may contain blanks and are not parsed
+		return stmt, i + 1
+	}
+
+	// Operand field, ending at the first blank outside quotes; the rest is a comment
+	var chars []jclChar
+	inQuote, depth := false, 0
+	col = skipBlanks(record, col)
+	next := i + 1
+	for {
+		c := col
+		for ; c < len(record); c++ {
+			r := record[c]
+			if r == ' ' && !inQuote {
+				break
+			}
+			switch {
+			case r == '\'':
+				inQuote = !inQuote
+			case inQuote:
+			case r == '(':
+				depth++
+			case r == ')':
+				depth--
+			}
+			chars = append(chars, jclChar{r: r, line: line, col: c})
+		}
+		if comment := skipBlanks(record, c); comment < len(record) {
+			j.Comments = append(j.Comments, Position{Line: line, Character: comment, Length: len(record) - comment})
+		}
+
+		continued := inQuote || (c > col && record[c-1] == ',')
+		if !continued {
+			break
+		}
+
+		// Comment statements may appear between continuation lines
+		k := next
+		for k < len(lines) && strings.HasPrefix(lines[k], "//*") {
+			j.Comments = append(j.Comments, Position{Line: firstLine + k, Character: 0, Length: len(jclRecord(lines[k]))})
+			k++
+		}
+		if k >= len(lines) || !isJCLContinuation(lines[k]) {
+			if inQuote {
+				break
+			}
+			j.error(Position{Line: line, Character: c - 1, Length: 1},
+				"Bad continuation: the parameters end with a comma, but the next line does not continue the statement")
+			break
+		}
+
+		record = jclRecord(lines[k])
+		line = firstLine + k
+		col = skipBlanks(record, 2)
+		if inQuote && col != jclContinueColumn {
+			j.error(Position{Line: line, Character: col, Length: 1}, "Bad continuation: a continued quoted string must resume in column 16")
+		} else if !inQuote && col > jclContinueColumn {
+			j.error(Position{Line: line, Character: col, Length: 1}, "Bad continuation: continued parameters must start in columns 4 to 16")
+		}
+		stmt.EndLine = line
+		next = k + 1
+	}
+
+	if inQuote {
+		j.error(jclPosition(chars), "Quoted string is not closed")
+	} else if depth != 0 {
+		j.error(jclPosition(chars), "Unbalanced parentheses in the parameters of %s", stmt.Operation)
+	}
+
+	for _, item := range splitJCL(chars, ',') {
+		if len(item) > 0 {
+			stmt.Parameters = append(stmt.Parameters, newJCLParameter(item))
+		}
+	}
+	return stmt, next
+}
+
+// newJCLParameter creates a parameter from its characters
+func newJCLParameter(chars []jclChar) *JCLParameter {
+	param := &JCLParameter{}
+	value := chars
+	for i, c := range chars {
+		if c.r == '(' || c.r == '\'' || c.r == '*' {
+			break
+		}
+		if c.r == '=' && i > 0 && isJCLName(jclText(chars[:i])) {
+			param.Keyword = jclText(chars[:i])
+			param.KeywordPos = jclPosition(chars[:i])
+			value = chars[i+1:]
+			break
+		}
+	}
+	param.Value = jclText(value)
+	param.ValueParts = jclParts(value)
+
+	// Member of a partitioned data set; generations of GDGs such as (+1) are no members
+	if param.Keyword == "DSN" || param.Keyword == "DSNAME" {
+		for i, c := range value {
+			if c.r == '(' && value[len(value)-1].r == ')' {
+				member := value[i+1 : len(value)-1]
+				if text := jclText(member); text != "" && !strings.ContainsAny(text[:1], "+-0123456789") {
+					param.Member = text
+					param.MemberPos = jclPosition(member)
+				}
+				break
+			}
+		}
+	}
+	return param
+}
+
+// parseData reads the in-stream data following a DD * or DD DATA statement and returns
+// the index of the line after it. Data ends at the delimiter (/* or DLM) and, for DD *, at
+// the next JCL statement.
+func (j *JCL) parseData(stmt *JCLStatement, lines []string, i, firstLine int) int {
+	first := stmt.Positional()
+	if stmt.Operation != "DD" || first == nil || (first.Value != "*" && first.Value != "DATA") {
+		return i
+	}
+	delimiter := "/*"
+	if dlm := stmt.Param("DLM"); dlm != nil && dlm.Value != "" {
+		delimiter = strings.Trim(dlm.Value, "'")
+	}
+
+	var previous *JCLControl
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], delimiter) {
+			stmt.EndLine = firstLine + i
+			return i + 1
+		}
+		if first.Value == "*" && strings.HasPrefix(lines[i], "//") {
+			return i
+		}
+		stmt.EndLine = firstLine + i
+		if control := parseJCLControl(jclRecord(lines[i]), firstLine+i, previous); control != nil {
+			if control != previous {
+				stmt.Data = append(stmt.Data, control)
+			}
+			previous = control
+		} else {
+			previous = nil
+		}
+	}
+	return i
+}
+
+// parseJCLControl parses a link-edit or IEBCOPY control statement naming load modules or
+// members. A line that does not start with an operation continues previous if that ended
+// with a comma. It returns nil for other lines.
+func parseJCLControl(record []rune, line int, previous *JCLControl) *JCLControl {
+	col := skipBlanks(record, 0)
+	if col == 0 && previous == nil {
+		// Column 1 holds a label, which link-edit statements rarely use
+		for col < len(record) && record[col] != ' ' {
+			col++
+		}
+		col = skipBlanks(record, col)
+	}
+	start := col
+	for col < len(record) && record[col] != ' ' {
+		col++
+	}
+	operation := strings.ToUpper(string(record[start:col]))
+
+	control := &JCLControl{Operation: operation, OpPos: Position{Line: line, Character: start, Length: col - start}}
+	switch operation {
+	case "NAME", "INCLUDE", "ALIAS", "SELECT", "EXCLUDE":
+		col = skipBlanks(record, col)
+	default:
+		if previous == nil || len(previous.Names) == 0 {
+			return nil
+		}
+		control, col = previous, start
+	}
+
+	var chars []jclChar
+	for ; col < len(record) && record[col] != ' '; col++ {
+		chars = append(chars, jclChar{r: record[col], line: line, col: col})
+	}
+	addName := func(kind JCLNameKind, dd string, name []jclChar) {
+		if text := jclText(name); text != "" {
+			control.Names = append(control.Names, JCLName{Kind: kind, Value: text, DD: dd, Position: jclPosition(name)})
+		}
+	}
+	// upTo returns the characters before the first opening parenthesis
+	upTo := func(item []jclChar) ([]jclChar, []jclChar) {
+		for i, c := range item {
+			if c.r == '(' {
+				return item[:i], item[i:]
+			}
+		}
+		return item, nil
+	}
+
+	for _, item := range splitJCL(chars, ',') {
+		switch control.Operation {
+		case "NAME":
+			name, _ := upTo(item)
+			addName(JCLNameLoadModule, "", name)
+		case "ALIAS":
+			name, _ := upTo(item)
+			addName(JCLNameAlias, "", name)
+		case "INCLUDE":
+			// ddname(member,...); options start with -
+			if len(item) > 0 && item[0].r == '-' {
+				continue
+			}
+			dd, members := upTo(item)
+			for _, member := range splitJCL(trimJCLParens(members), ',') {
+				addName(JCLNameMember, jclText(dd), member)
+			}
+		case "SELECT", "EXCLUDE":
+			// MEMBER=(name,(name,newname,R),...)
+			keyword, value := upTo(item)
+			if text := jclText(keyword); !strings.HasPrefix(text, "MEMBER=") && !strings.HasPrefix(text, "M=") {
+				continue
+			}
+			if value == nil {
+				_, name, _ := strings.Cut(jclText(keyword), "=")
+				addName(JCLNameMember, "", keyword[len(keyword)-len([]rune(name)):])
+				continue
+			}
+			for _, member := range splitJCL(trimJCLParens(value), ',') {
+				name, _ := upTo(trimJCLParens(member))
+				if len(member) > 0 && member[0].r == '(' {
+					name = splitJCL(trimJCLParens(member), ',')[0]
+				}
+				addName(JCLNameMember, "", name)
+			}
+		}
+	}
+	return control
+}
+
+// shifted returns a copy of j moved by delta lines
+func (j *JCL) shifted(delta int) *JCL {
+	if j == nil {
+		return nil
+	}
+	// Positions of omitted fields are left unset
+	shift := func(pos Position) Position {
+		if pos.Length > 0 {
+			pos.Line += delta
+		}
+		return pos
+	}
+	clone := &JCL{}
+	for _, s := range j.Statements {
+		stmt := *s
+		stmt.NamePos, stmt.OpPos = shift(s.NamePos), shift(s.OpPos)
+		stmt.Line += delta
+		stmt.EndLine += delta
+		stmt.Parameters, stmt.Data = nil, nil
+		for _, p := range s.Parameters {
+			param := *p
+			param.KeywordPos, param.MemberPos = shift(p.KeywordPos), shift(p.MemberPos)
+			param.ValueParts = nil
+			for _, part := range p.ValueParts {
+				param.ValueParts = append(param.ValueParts, shift(part))
+			}
+			stmt.Parameters = append(stmt.Parameters, &param)
+		}
+		for _, c := range s.Data {
+			control := *c
+			control.OpPos = shift(c.OpPos)
+			control.Names = nil
+			for _, name := range c.Names {
+				name.Position = shift(name.Position)
+				control.Names = append(control.Names, name)
+			}
+			stmt.Data = append(stmt.Data, &control)
+		}
+		clone.Statements = append(clone.Statements, &stmt)
+	}
+	for _, c := range j.Comments {
+		clone.Comments = append(clone.Comments, shift(c))
+	}
+	for _, e := range j.Errors {
+		e.Position = shift(e.Position)
+		clone.Errors = append(clone.Errors, e)
+	}
+	return clone
+}
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+)
+
+const testJCLIN = `++USERMOD(LJS0001) .
+++VER(Z038) FMID(HBB77C0) .
+++JCLIN .
+//LKED     EXEC PGM=IEWL,PARM='LIST,XREF',
+//*  Link-edit the module
+//             REGION=0M
+//SYSLMOD  DD  DSN=SYS1.LINKLIB,DISP=SHR  Target library
+//AOBJ     DD  DSN=SYS1.AOBJ(MOD1),
+//             DISP=SHR
+//SYSLIN   DD  *
+  INCLUDE AOBJ(MOD1,MOD2)
+  ALIAS ALIAS1,
+        ALIAS2
+  NAME MYLMOD(R)
+/*
+//COPY     EXEC PGM=IEBCOPY
+//SYSIN    DD  *
+  COPY OUTDD=OUT,INDD=IN
+  SELECT MEMBER=(MEMA,(MEMB,NEWB,R))
+++MOD(MOD1) DISTLIB(AOBJ) .
+`
+
+// parseTestJCL parses text with the built-in smpe.json and returns the JCL of ++JCLIN
+func parseTestJCL(t *testing.T, text string) *JCL {
+	t.Helper()
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range NewParser(store.Statements).Parse(text).Statements {
+		if stmt.Name == "++JCLIN" {
+			if stmt.JCL == nil {
+				t.Fatal("Expected the JCL of ++JCLIN to be parsed")
+			}
+			return stmt.JCL
+		}
+	}
+	t.Fatal("No ++JCLIN statement")
+	return nil
+}
+
+func TestParseJCL(t *testing.T) {
+	jcl := parseTestJCL(t, testJCLIN)
+	if len(jcl.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", jcl.Errors)
+	}
+
+	var got []string
+	for _, s := range jcl.Statements {
+		got = append(got, s.Name+" "+s.Operation)
+	}
+	want := []string{"LKED EXEC", "SYSLMOD DD", "AOBJ DD", "SYSLIN DD", "COPY EXEC", "SYSIN DD"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Statements = %v, want %v", got, want)
+	}
+
+	lked := jcl.Statements[0]
+	if lked.Line != 3 || lked.EndLine != 5 {
+		t.Errorf("LKED spans lines %d-%d, want 3-5", lked.Line, lked.EndLine)
+	}
+	if p := lked.Param("PARM"); p == nil || p.Value != "'LIST,XREF'" {
+		t.Errorf("PARM = %+v", p)
+	}
+	if p := lked.Param("REGION"); p == nil || p.ValueParts[0] != (Position{Line: 5, Character: 22, Length: 2}) {
+		t.Errorf("Continued REGION = %+v", p)
+	}
+	if len(jcl.Comments) != 2 {
+		t.Errorf("Expected the comment statement and comment field, got %v", jcl.Comments)
+	}
+
+	aobj := jcl.Statements[2].DSN()
+	if aobj.Member != "MOD1" || aobj.DataSetName() != "SYS1.AOBJ" || aobj.MemberPos != (Position{Line: 7, Character: 29, Length: 4}) {
+		t.Errorf("DSN = %+v", aobj)
+	}
+	if step := jcl.Step(jcl.Statements[3]); step != lked {
+		t.Errorf("Step of SYSLIN = %v", step)
+	}
+	if dd := jcl.StepDD(jcl.Statements[3], "SYSLMOD"); dd != jcl.Statements[1] {
+		t.Errorf("SYSLMOD of step LKED = %v", dd)
+	}
+
+	var names []string
+	for _, control := range append(jcl.Statements[3].Data, jcl.Statements[5].Data...) {
+		for _, name := range control.Names {
+			names = append(names, control.Operation+" "+name.DD+":"+name.Value)
+		}
+	}
+	want = []string{"INCLUDE AOBJ:MOD1", "INCLUDE AOBJ:MOD2", "ALIAS :ALIAS1", "ALIAS :ALIAS2", "NAME :MYLMOD", "SELECT :MEMA", "SELECT :MEMB"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Names = %v, want %v", names, want)
+	}
+	if pos := jcl.Statements[3].Data[2].Names[0].Position; pos != (Position{Line: 13, Character: 7, Length: 6}) {
+		t.Errorf("Position of MYLMOD = %+v", pos)
+	}
+}
+
+func TestParseJCLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		jcl   string
+		error string
+	}{
+		{"missing continuation", "//SYSLMOD DD DSN=SYS1.LINKLIB,\n//SYSLIB DD DSN=SYS1.MACLIB", "Bad continuation"},
+		{"missing comma", "//SYSLMOD DD DSN=SYS1.LINKLIB\n//   DISP=SHR", "end the previous line with a comma"},
+		{"late continuation", "//SYSLMOD DD DSN=SYS1.LINKLIB,\n//                  DISP=SHR", "columns 4 to 16"},
+		{"parentheses", "//SYSUT1 DD SPACE=(CYL,(1,1)", "Unbalanced parentheses"},
+		{"quote", "//LKED EXEC PGM=IEWL,PARM='LIST", "not closed"},
+		{"operation", "//LKED EXECUTE PGM=IEWL", "Unknown JCL operation EXECUTE"},
+		{"name", "//1STEP EXEC PGM=IEWL", "Invalid JCL name 1STEP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jcl := parseTestJCL(t, "++JCLIN .\n"+tt.jcl+"\n")
+			if len(jcl.Errors) != 1 || !strings.Contains(jcl.Errors[0].Message, tt.error) {
+				t.Errorf("Errors = %v, want %q", jcl.Errors, tt.error)
+			}
+		})
+	}
+}
+
+func TestReparseShiftsJCL(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser(store.Statements)
+	old := "++USERMOD(LJS0001) .\n" + testJCLIN[strings.Index(testJCLIN, "++JCLIN"):]
+	text := "++USERMOD(LJS0000) .\n" + old
+	doc := p.Reparse(p.Parse(old), text, 0, 0, 1)
+
+	full := p.Parse(text)
+	var jcl, want *JCL
+	for i, stmt := range doc.Statements {
+		if stmt.Name == "++JCLIN" {
+			jcl, want = stmt.JCL, full.Statements[i].JCL
+		}
+	}
+	if jcl == nil || !reflect.DeepEqual(jcl, want) {
+		t.Errorf("Reparsed JCL differs from a full parse")
+	}
+}
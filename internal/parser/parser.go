@@ -52,11 +52,13 @@ type Node struct {
 	OperandDef   *data.Operand      // Referenz für Operands
 
 	// Statement-specific flags
-	HasTerminator    bool   // Only for statement nodes - tracks if '.' terminator was found
-	UnbalancedParens int    // Tracks parenthesis imbalance: positive = missing closing, negative = missing opening
-	LanguageID       string // Language identifier for language variant statements (e.g., "ENU" from "++FONTENU")
-	HasInlineData    bool   // True if actual inline data (non-empty, non-comment lines) was found
-	InlineDataLines  int    // Number of actual inline data lines found
+	HasTerminator    bool    // Only for statement nodes - tracks if '.' terminator was found
+	UnbalancedParens int     // Tracks parenthesis imbalance: positive = missing closing, negative = missing opening
+	LanguageID       string  // Language identifier for language variant statements (e.g., "ENU" from "++FONTENU")
+	HasInlineData    bool    // True if actual inline data (non-empty, non-comment lines) was found
+	InlineDataLines  int     // Number of actual inline data lines found
+	JCL              *JCL    // Parsed inline JCL of ++JCLIN statements
+	Update           *Update // Parsed IEBUPDTE input of ++MACUPD and ++SRCUPD statements
 }
 
 // ParseError represents a parsing error
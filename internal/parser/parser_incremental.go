@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+)
+
+// Reparse updates a parsed document after an edit that replaced the old lines
+// startLine..oldEndLine with the new lines startLine..newEndLine of text.
+// Only the statements whose span was touched are parsed again; their new nodes are
+// spliced into a copy of doc and the statements after the edit are shifted.
+// Nodes of doc are never modified, so readers holding doc are not affected.
+// Falls back to a full parse when the edit may change how the surrounding text is read
+// (e.g. a block comment crossing the reparsed region).
+func (p *Parser) Reparse(doc *Document, text string, startLine, oldEndLine, newEndLine int) *Document {
+	lines := strings.Split(text, "\n")
+	delta := newEndLine - oldEndLine
+
+	if doc == nil || startLine < 0 || oldEndLine < startLine || newEndLine < startLine {
+		return p.Parse(text)
+	}
+
+	// Region in old coordinates: from the statement before the one containing the edit
+	// (an edit at the start of a statement can join it to the previous one) up to the
+	// first statement starting after the edit
+	regionStart := 0
+	before := -1 // index of the first statement inside the region
+	for i, stmt := range doc.Statements {
+		if stmt.Position.Line > startLine {
+			break
+		}
+		before = i
+	}
+	if before > 0 {
+		before--
+	}
+	if before >= 0 {
+		regionStart = doc.Statements[before].Position.Line
+	} else {
+		before = 0
+	}
+
+	after := len(doc.Statements) // index of the first statement after the region
+	for i := before; i < len(doc.Statements); i++ {
+		if doc.Statements[i].Position.Line > oldEndLine {
+			after = i
+			break
+		}
+	}
+	regionEndNew := len(lines)
+	if after < len(doc.Statements) {
+		regionEndOld := doc.Statements[after].Position.Line
+		regionEndNew = regionEndOld + delta
+		if regionEndNew < regionStart || regionEndNew > len(lines) || commentCrosses(doc, regionEndOld) {
+			return p.Parse(text)
+		}
+	}
+	if commentCrosses(doc, regionStart) {
+		return p.Parse(text)
+	}
+
+	region, openComment := p.parseLines(lines[regionStart:regionEndNew])
+	if openComment && regionEndNew < len(lines) {
+		// An unterminated comment would swallow the statements after the region
+		return p.Parse(text)
+	}
+
+	// Statements after the region must still start where they did
+	if after < len(doc.Statements) && !strings.HasPrefix(strings.TrimSpace(lines[regionEndNew]), "++") {
+		return p.Parse(text)
+	}
+
+	logger.Debug("Parser: Reparsing lines %d-%d (%d statements), shifting %d statements by %d lines",
+		regionStart, regionEndNew, len(region.Statements), len(doc.Statements)-after, delta)
+
+	for _, stmt := range region.Statements {
+		shiftNode(stmt, regionStart)
+	}
+	for _, comment := range region.Comments {
+		shiftNode(comment, regionStart)
+	}
+
+	// Splice statements: unchanged prefix, reparsed region, shifted copies of the rest
+	result := &Document{
+		Statements:                make([]*Node, 0, before+len(region.Statements)+len(doc.Statements)-after),
+		Comments:                  []*Node{},
+		Errors:                    []ParseError{},
+		StatementsExpectingInline: []*Node{},
+	}
+	shifted := make(map[*Node]*Node)
+
+	result.Statements = append(result.Statements, doc.Statements[:before]...)
+	result.Statements = append(result.Statements, region.Statements...)
+	for _, stmt := range doc.Statements[after:] {
+		clone := cloneShifted(stmt, nil, delta)
+		shifted[stmt] = clone
+		result.Statements = append(result.Statements, clone)
+	}
+
+	// Splice statements expecting inline data the same way
+	for _, stmt := range doc.StatementsExpectingInline {
+		if stmt.Position.Line < regionStart {
+			result.StatementsExpectingInline = append(result.StatementsExpectingInline, stmt)
+		}
+	}
+	result.StatementsExpectingInline = append(result.StatementsExpectingInline, region.StatementsExpectingInline...)
+	for _, stmt := range doc.StatementsExpectingInline {
+		if clone, ok := shifted[stmt]; ok {
+			result.StatementsExpectingInline = append(result.StatementsExpectingInline, clone)
+		}
+	}
+
+	// Splice comments
+	oldRegionEnd := regionEndNew - delta
+	for _, comment := range doc.Comments {
+		if comment.Position.Line < regionStart {
+			result.Comments = append(result.Comments, comment)
+		}
+	}
+	result.Comments = append(result.Comments, region.Comments...)
+	for _, comment := range doc.Comments {
+		if comment.Position.Line >= oldRegionEnd && after < len(doc.Statements) {
+			result.Comments = append(result.Comments, cloneShifted(comment, nil, delta))
+		}
+	}
+
+	return result
+}
+
+// commentCrosses reports whether a block comment of doc starts before line and continues into it
+func commentCrosses(doc *Document, line int) bool {
+	for _, comment := range doc.Comments {
+		start := comment.Position.Line
+		end := start + strings.Count(comment.Value, "\n")
+		if start < line && end >= line {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftNode moves a node and its descendants by delta lines
+func shiftNode(node *Node, delta int) {
+	node.Position.Line += delta
+	node.JCL = node.JCL.shifted(delta)
+	node.Update = node.Update.shifted(delta)
+	for _, child := range node.Children {
+		shiftNode(child, delta)
+	}
+}
+
+// cloneShifted returns a deep copy of node moved by delta lines.
+// parent is the copy of node's parent, if any.
+func cloneShifted(node *Node, parent *Node, delta int) *Node {
+	clone := *node
+	if parent != nil {
+		clone.Parent = parent
+	}
+	clone.Position.Line += delta
+	clone.JCL = node.JCL.shifted(delta)
+	clone.Update = node.Update.shifted(delta)
+	if node.Children != nil {
+		clone.Children = make([]*Node, len(node.Children))
+		for i, child := range node.Children {
+			if child.Parent == node {
+				clone.Children[i] = cloneShifted(child, &clone, delta)
+			} else {
+				clone.Children[i] = cloneShifted(child, nil, delta)
+			}
+		}
+	}
+	return &clone
+}
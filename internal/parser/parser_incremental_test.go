@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+)
+
+// dumpDocument renders a document for structural comparison
+func dumpDocument(doc *Document) string {
+	var sb strings.Builder
+	var dump func(n *Node, parent *Node, depth int)
+	dump = func(n *Node, parent *Node, depth int) {
+		fmt.Fprintf(&sb, "%s%d %q %q %d:%d+%d term=%v parens=%d inline=%v/%d parentOK=%v\n",
+			strings.Repeat("  ", depth), n.Type, n.Name, n.Value,
+			n.Position.Line, n.Position.Character, n.Position.Length,
+			n.HasTerminator, n.UnbalancedParens, n.HasInlineData, n.InlineDataLines,
+			parent == nil || n.Parent == parent)
+		for _, child := range n.Children {
+			dump(child, n, depth+1)
+		}
+	}
+	for _, stmt := range doc.Statements {
+		dump(stmt, nil, 0)
+	}
+	sb.WriteString("comments:\n")
+	for _, c := range doc.Comments {
+		dump(c, nil, 1)
+	}
+	sb.WriteString("expecting inline:\n")
+	for _, s := range doc.StatementsExpectingInline {
+		fmt.Fprintf(&sb, "  %s@%d\n", s.Name, s.Position.Line)
+	}
+	return sb.String()
+}
+
+// lineEdit replaces lines [start, end] with newLines and returns the new text
+func lineEdit(lines []string, start, end int, newLines []string) []string {
+	result := append([]string{}, lines[:start]...)
+	result = append(result, newLines...)
+	return append(result, lines[end+1:]...)
+}
+
+func TestReparseMatchesFullParse(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	p := NewParser(store.Statements)
+
+	files, _ := filepath.Glob("../../test-files/*.smpe")
+	if len(files) == 0 {
+		t.Skip("No test files found")
+	}
+
+	// Replacement lines exercising statement boundaries, comments, terminators and inline data
+	replacements := [][]string{
+		{},
+		{""},
+		{"++PTF(UA99999) DESC('INSERTED')."},
+		{"++VER(Z038) FMID(HBB7790)", "   PRE(UA00001,", "   UA00002)."},
+		{"/* comment"},
+		{"comment end */ ."},
+		{"  DISTLIB(AOSFMID)"},
+		{"."},
+		{"++MAC(MYMAC) DISTLIB(AMACLIB).", "  MACRO", "  MEND"},
+		{"  PRE(UA12345"},
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		lines := strings.Split(string(content), "\n")
+		doc := p.Parse(string(content))
+
+		for step := 0; step < 40; step++ {
+			start := rng.Intn(len(lines))
+			end := start + rng.Intn(3)
+			if end >= len(lines) {
+				end = len(lines) - 1
+			}
+
+			newLines := replacements[rng.Intn(len(replacements))]
+			if len(newLines) == 0 && start == 0 && end == len(lines)-1 {
+				continue
+			}
+			lines = lineEdit(lines, start, end, newLines)
+			text := strings.Join(lines, "\n")
+
+			// The edit replaced old lines start..end with new lines start..start+len-1
+			oldEndLine := end
+			newEndLine := start + len(newLines) - 1
+			if len(newLines) == 0 {
+				// Pure deletion: the edit spans up to the start of the line after end
+				oldEndLine = end + 1
+				newEndLine = start
+			}
+
+			before := dumpDocument(doc)
+			previous := doc
+			doc = p.Reparse(doc, text, start, oldEndLine, newEndLine)
+
+			want := dumpDocument(p.Parse(text))
+			if got := dumpDocument(doc); got != want {
+				t.Fatalf("%s step %d: reparse of lines %d-%d differs from full parse\ntext:\n%s\ngot:\n%s\nwant:\n%s",
+					filepath.Base(file), step, start, end, text, got, want)
+			}
+			if dumpDocument(previous) != before {
+				t.Fatalf("%s step %d: reparse modified the previous document", filepath.Base(file), step)
+			}
+		}
+	}
+}
@@ -6,6 +6,13 @@ import (
 
 // Parse parses the given text and returns a Document with AST
 func (p *Parser) Parse(text string) *Document {
+	doc, _ := p.parseLines(strings.Split(text, "\n"))
+	return doc
+}
+
+// parseLines parses the given lines and returns a Document with AST.
+// The second result reports whether the lines end inside an unterminated block comment.
+func (p *Parser) parseLines(lines []string) (*Document, bool) {
 	doc := &Document{
 		Statements:                []*Node{},
 		Comments:                  []*Node{},
@@ -13,8 +20,6 @@ func (p *Parser) Parse(text string) *Document {
 		StatementsExpectingInline: []*Node{},
 	}
 
-	lines := strings.Split(text, "\n")
-
 	// First pass: Extract all comments and create clean lines for parsing
 	cleanLines := make([]string, len(lines))
 	inBlockComment := false
@@ -216,13 +221,21 @@ func (p *Parser) Parse(text string) *Document {
 								currentStatement.InlineDataLines++
 							}
 						}
+
+						start := stmt.StartLine + len(stmt.Lines)
+						switch currentStatement.Name {
+						case "++JCLIN":
+							currentStatement.JCL = parseJCL(lines[start:max(start, endLine)], start)
+						case "++MACUPD", "++SRCUPD":
+							currentStatement.Update = parseUpdate(lines[start:max(start, endLine)], start)
+						}
 					}
 				}
 			}
 		}
 	}
 
-	return doc
+	return doc, inBlockComment
 }
 
 // originalPos represents a position in the original multi-line text
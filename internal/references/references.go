@@ -14,6 +14,7 @@ const (
 	SymbolTypeSYSMOD   SymbolType = iota // PTF, APAR, USERMOD, FUNCTION
 	SymbolTypeFMID                       // FUNCTION identifier
 	SymbolTypeElement                    // MAC, SRC, MOD, etc.
+	SymbolTypeDDDEF                      // DISTLIB, SYSLIB, TXLIB, FROMDS
 )
 
 // Symbol represents a symbol in the document
@@ -26,7 +27,7 @@ type Symbol struct {
 	Context    string // e.g., "PRE", "REQ", "SUP", "FMID", statement name
 }
 
-// Provider provides go-to-definition and find-references functionality
+// Provider extracts SYSMOD ID and FMID symbols for the workspace index and renames them
 type Provider struct{}
 
 // NewProvider creates a new references provider
@@ -34,189 +35,20 @@ func NewProvider() *Provider {
 	return &Provider{}
 }
 
-// GetDefinition returns the definition location for a symbol at the given position
-func (p *Provider) GetDefinition(doc *parser.Document, text string, line, character int) *lsp.Location {
+// Symbols returns all SYSMOD ID and FMID definitions and references in the document
+func (p *Provider) Symbols(doc *parser.Document) []Symbol {
 	if doc == nil {
 		return nil
 	}
-
-	// Find symbol at cursor position
-	symbol := p.findSymbolAtPosition(doc, line, character)
-	if symbol == nil || symbol.IsDefinition {
-		return nil // Already at definition or no symbol found
-	}
-
-	// Find the definition of this symbol
-	definition := p.findDefinition(doc, symbol.Name, symbol.Type)
-	if definition == nil {
-		return nil
-	}
-
-	return &lsp.Location{
-		URI: "", // Will be set by handler
-		Range: lsp.Range{
-			Start: definition.Position,
-			End: lsp.Position{
-				Line:      definition.Position.Line,
-				Character: definition.Position.Character + definition.Length,
-			},
-		},
-	}
-}
-
-// GetReferences returns all references to a symbol at the given position
-func (p *Provider) GetReferences(doc *parser.Document, text string, line, character int, includeDeclaration bool) []lsp.Location {
-	if doc == nil {
-		return nil
-	}
-
-	// Find symbol at cursor position
-	symbol := p.findSymbolAtPosition(doc, line, character)
-	if symbol == nil {
-		return nil
-	}
-
-	// Find all references to this symbol
-	symbols := p.findAllSymbols(doc)
-	var locations []lsp.Location
-
-	for _, s := range symbols {
-		if s.Name == symbol.Name && s.Type == symbol.Type {
-			// Skip definition if not requested
-			if !includeDeclaration && s.IsDefinition {
-				continue
-			}
-
-			locations = append(locations, lsp.Location{
-				URI: "", // Will be set by handler
-				Range: lsp.Range{
-					Start: s.Position,
-					End: lsp.Position{
-						Line:      s.Position.Line,
-						Character: s.Position.Character + s.Length,
-					},
-				},
-			})
-		}
-	}
-
-	return locations
+	return p.findAllSymbols(doc)
 }
 
 // findSymbolAtPosition finds the symbol at the given position
 func (p *Provider) findSymbolAtPosition(doc *parser.Document, line, character int) *Symbol {
-	for _, stmt := range doc.Statements {
-		// Check if cursor is on statement parameter (SYSMOD definition)
-		if p.isSYSMODStatement(stmt.Name) {
-			for _, child := range stmt.Children {
-				if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
-					if p.isPositionInRange(line, character, child.Position) {
-						return &Symbol{
-							Name:         child.Value,
-							Type:         p.getSYSMODType(stmt.Name),
-							Position:     lsp.Position{Line: child.Position.Line, Character: child.Position.Character},
-							Length:       len(child.Value),
-							IsDefinition: true,
-							Context:      stmt.Name,
-						}
-					}
-				}
-			}
-		}
-
-		// Check operands for references
-		for _, child := range stmt.Children {
-			if child.Type != parser.NodeTypeOperand {
-				continue
-			}
-
-			// Check operands that reference SYSMODs: PRE, REQ, SUP, IF
-			if p.isSYSMODReferenceOperand(child.Name) {
-				for _, param := range child.Children {
-					if param.Type == parser.NodeTypeParameter {
-						// Handle comma-separated values
-						refs := p.parseParameterReferences(param.Value)
-						offset := 0
-						for _, ref := range refs {
-							refStart := param.Position.Character + offset
-							refLen := len(ref)
-							if p.isPositionInRangeWithLength(line, character, param.Position.Line, refStart, refLen) {
-								return &Symbol{
-									Name:         ref,
-									Type:         SymbolTypeSYSMOD,
-									Position:     lsp.Position{Line: param.Position.Line, Character: refStart},
-									Length:       refLen,
-									IsDefinition: false,
-									Context:      child.Name,
-								}
-							}
-							offset += refLen + 1 // +1 for comma
-						}
-					}
-				}
-			}
-
-			// Check FMID operand (references ++FUNCTION)
-			if child.Name == "FMID" {
-				for _, param := range child.Children {
-					if param.Type == parser.NodeTypeParameter {
-						if p.isPositionInRange(line, character, param.Position) {
-							return &Symbol{
-								Name:         param.Value,
-								Type:         SymbolTypeFMID,
-								Position:     lsp.Position{Line: param.Position.Line, Character: param.Position.Character},
-								Length:       len(param.Value),
-								IsDefinition: false,
-								Context:      "FMID",
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
-// findDefinition finds the definition of a symbol
-func (p *Provider) findDefinition(doc *parser.Document, name string, symbolType SymbolType) *Symbol {
-	for _, stmt := range doc.Statements {
-		switch symbolType {
-		case SymbolTypeSYSMOD:
-			if p.isSYSMODStatement(stmt.Name) {
-				for _, child := range stmt.Children {
-					if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
-						if child.Value == name {
-							return &Symbol{
-								Name:         child.Value,
-								Type:         symbolType,
-								Position:     lsp.Position{Line: child.Position.Line, Character: child.Position.Character},
-								Length:       len(child.Value),
-								IsDefinition: true,
-								Context:      stmt.Name,
-							}
-						}
-					}
-				}
-			}
-		case SymbolTypeFMID:
-			if stmt.Name == "++FUNCTION" {
-				for _, child := range stmt.Children {
-					if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
-						if child.Value == name {
-							return &Symbol{
-								Name:         child.Value,
-								Type:         symbolType,
-								Position:     lsp.Position{Line: child.Position.Line, Character: child.Position.Character},
-								Length:       len(child.Value),
-								IsDefinition: true,
-								Context:      stmt.Name,
-							}
-						}
-					}
-				}
-			}
+	for _, s := range p.findAllSymbols(doc) {
+		if p.isPositionInRangeWithLength(line, character, s.Position.Line, s.Position.Character, s.Length) {
+			symbol := s
+			return &symbol
 		}
 	}
 
@@ -228,16 +60,20 @@ func (p *Provider) findAllSymbols(doc *parser.Document) []Symbol {
 	var symbols []Symbol
 
 	for _, stmt := range doc.Statements {
-		// Collect SYSMOD definitions
-		if p.isSYSMODStatement(stmt.Name) {
+		// Collect SYSMOD definitions and statement-level SYSMOD references (++HOLD, ++RELEASE)
+		if p.isSYSMODStatement(stmt.Name) || p.isSYSMODReferenceStatement(stmt.Name) {
 			for _, child := range stmt.Children {
-				if child.Type == parser.NodeTypeParameter && child.Parent == stmt {
+				if child.Type == parser.NodeTypeParameter && child.Parent == stmt && child.Value != "" {
+					symbolType := SymbolTypeSYSMOD
+					if p.isSYSMODStatement(stmt.Name) {
+						symbolType = p.getSYSMODType(stmt.Name)
+					}
 					symbols = append(symbols, Symbol{
 						Name:         child.Value,
-						Type:         p.getSYSMODType(stmt.Name),
+						Type:         symbolType,
 						Position:     lsp.Position{Line: child.Position.Line, Character: child.Position.Character},
 						Length:       len(child.Value),
-						IsDefinition: true,
+						IsDefinition: p.isSYSMODStatement(stmt.Name),
 						Context:      stmt.Name,
 					})
 				}
@@ -250,40 +86,29 @@ func (p *Provider) findAllSymbols(doc *parser.Document) []Symbol {
 				continue
 			}
 
-			// SYSMOD references
-			if p.isSYSMODReferenceOperand(child.Name) {
-				for _, param := range child.Children {
-					if param.Type == parser.NodeTypeParameter {
-						refs := p.parseParameterReferences(param.Value)
-						offset := 0
-						for _, ref := range refs {
-							symbols = append(symbols, Symbol{
-								Name:         ref,
-								Type:         SymbolTypeSYSMOD,
-								Position:     lsp.Position{Line: param.Position.Line, Character: param.Position.Character + offset},
-								Length:       len(ref),
-								IsDefinition: false,
-								Context:      child.Name,
-							})
-							offset += len(ref) + 1
-						}
-					}
-				}
+			var symbolType SymbolType
+			switch {
+			case p.isSYSMODReferenceOperand(child.Name):
+				symbolType = SymbolTypeSYSMOD
+			case child.Name == "FMID":
+				symbolType = SymbolTypeFMID
+			default:
+				continue
 			}
 
-			// FMID references
-			if child.Name == "FMID" {
-				for _, param := range child.Children {
-					if param.Type == parser.NodeTypeParameter {
-						symbols = append(symbols, Symbol{
-							Name:         param.Value,
-							Type:         SymbolTypeFMID,
-							Position:     lsp.Position{Line: param.Position.Line, Character: param.Position.Character},
-							Length:       len(param.Value),
-							IsDefinition: false,
-							Context:      "FMID",
-						})
-					}
+			for _, param := range child.Children {
+				if param.Type != parser.NodeTypeParameter {
+					continue
+				}
+				for _, item := range p.referenceItems(param) {
+					symbols = append(symbols, Symbol{
+						Name:         item.Value,
+						Type:         symbolType,
+						Position:     lsp.Position{Line: item.Position.Line, Character: item.Position.Character},
+						Length:       len(item.Value),
+						IsDefinition: false,
+						Context:      child.Name,
+					})
 				}
 			}
 		}
@@ -292,6 +117,37 @@ func (p *Provider) findAllSymbols(doc *parser.Document) []Symbol {
 	return symbols
 }
 
+// referenceItems returns the individual list items of an operand parameter.
+// The parser splits lists into child nodes with their own (multiline-corrected) positions;
+// parameters without children are split on commas relative to the parameter start.
+func (p *Provider) referenceItems(param *parser.Node) []*parser.Node {
+	if len(param.Children) > 0 {
+		var items []*parser.Node
+		for _, item := range param.Children {
+			if item.Type == parser.NodeTypeParameter && item.Value != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	var items []*parser.Node
+	offset := 0
+	for _, ref := range p.parseParameterReferences(param.Value) {
+		items = append(items, &parser.Node{
+			Type:  parser.NodeTypeParameter,
+			Value: ref,
+			Position: parser.Position{
+				Line:      param.Position.Line,
+				Character: param.Position.Character + offset,
+				Length:    len(ref),
+			},
+		})
+		offset += len(ref) + 1 // +1 for comma
+	}
+	return items
+}
+
 // isSYSMODStatement checks if the statement defines a SYSMOD
 func (p *Provider) isSYSMODStatement(name string) bool {
 	switch name {
@@ -301,6 +157,15 @@ func (p *Provider) isSYSMODStatement(name string) bool {
 	return false
 }
 
+// isSYSMODReferenceStatement checks if the statement parameter references an existing SYSMOD
+func (p *Provider) isSYSMODReferenceStatement(name string) bool {
+	switch name {
+	case "++HOLD", "++RELEASE":
+		return true
+	}
+	return false
+}
+
 // getSYSMODType returns the symbol type for a SYSMOD statement
 func (p *Provider) getSYSMODType(stmtName string) SymbolType {
 	if stmtName == "++FUNCTION" {
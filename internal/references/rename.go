@@ -0,0 +1,226 @@
+package references
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/formatting"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// SYSMODIDLength is the fixed length of SYSMOD IDs and FMIDs
+const SYSMODIDLength = 7
+
+// continuationIndent is used when a renamed line has to be wrapped and has no indentation of its own
+const continuationIndent = "   "
+
+// Occurrence is a SYSMOD ID or FMID in a workspace file
+type Occurrence struct {
+	Symbol
+	URI string
+}
+
+// Workspace gives rename access to the SYSMOD IDs and FMIDs of all workspace files,
+// e.g. through the workspace index
+type Workspace interface {
+	// Occurrences returns the definitions and references of a SYSMOD ID or FMID
+	Occurrences(name string) []Occurrence
+	// Statement returns the MCS definition of a statement, or nil if it is unknown
+	Statement(name string) *data.MCSStatement
+	// Text returns the content of a workspace file
+	Text(uri string) (string, error)
+}
+
+// PrepareRename returns the range and current name of the SYSMOD ID or FMID at the given position,
+// or nil if there is nothing to rename there
+func (p *Provider) PrepareRename(doc *parser.Document, line, character int) *lsp.PrepareRenameResult {
+	if doc == nil {
+		return nil
+	}
+
+	symbol := p.findSymbolAtPosition(doc, line, character)
+	if symbol == nil {
+		return nil
+	}
+
+	return &lsp.PrepareRenameResult{
+		Range:       symbolRange(symbol),
+		Placeholder: symbol.Name,
+	}
+}
+
+// Rename renames the SYSMOD ID or FMID at the given position in doc.
+// The definition and every reference in the workspace are rewritten; lines that would
+// extend beyond column 72 are re-wrapped.
+func (p *Provider) Rename(doc *parser.Document, line, character int, newName string, ws Workspace) (*lsp.WorkspaceEdit, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document not parsed")
+	}
+
+	symbol := p.findSymbolAtPosition(doc, line, character)
+	if symbol == nil {
+		return nil, fmt.Errorf("no SYSMOD ID or FMID at cursor position")
+	}
+
+	newName = strings.ToUpper(strings.TrimSpace(newName))
+	edit := &lsp.WorkspaceEdit{Changes: make(map[string][]lsp.TextEdit)}
+	if newName == symbol.Name {
+		return edit, nil
+	}
+
+	// Find the defining statement (if any) to validate against its rules
+	occurrences := ws.Occurrences(symbol.Name)
+	var def *data.MCSStatement
+	for _, o := range occurrences {
+		if o.IsDefinition {
+			def = ws.Statement(o.Context)
+			break
+		}
+	}
+	if err := ValidateSYSMODID(def, newName); err != nil {
+		return nil, err
+	}
+
+	// Refuse to merge two SYSMODs into one
+	for _, o := range ws.Occurrences(newName) {
+		if o.IsDefinition {
+			return nil, fmt.Errorf("%s(%s) is already defined", o.Context, newName)
+		}
+	}
+
+	byURI := make(map[string][]lsp.TextEdit)
+	var uris []string
+	for _, o := range occurrences {
+		if o.Type == SymbolTypeElement {
+			continue
+		}
+		if _, seen := byURI[o.URI]; !seen {
+			uris = append(uris, o.URI)
+		}
+		byURI[o.URI] = append(byURI[o.URI], lsp.TextEdit{
+			Range:   symbolRange(&o.Symbol),
+			NewText: newName,
+		})
+	}
+	for _, uri := range uris {
+		text, err := ws.Text(uri)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", uri, err)
+		}
+		edit.Changes[uri] = wrapEditedLines(strings.Split(text, "\n"), byURI[uri])
+	}
+
+	return edit, nil
+}
+
+// ValidateSYSMODID checks a new SYSMOD ID or FMID against the pattern and reserved
+// pattern of its defining statement (e.g. ++USERMOD) in smpe.json. A nil statement
+// applies the generic SYSMOD ID rules.
+func ValidateSYSMODID(def *data.MCSStatement, id string) error {
+	kind := "SYSMOD ID"
+	if def != nil && def.Name == "++FUNCTION" {
+		kind = "FMID"
+	}
+
+	if len(id) != SYSMODIDLength {
+		return fmt.Errorf("invalid %s '%s': must be exactly %d characters", kind, id, SYSMODIDLength)
+	}
+	if def == nil || def.Pattern == "" {
+		for i := 0; i < len(id); i++ {
+			ch := id[i]
+			if !(ch >= 'A' && ch <= 'Z') && !(ch >= '0' && ch <= '9') && ch != '$' && ch != '@' && ch != '#' {
+				return fmt.Errorf("invalid %s '%s': only alphanumeric and national characters ($, @, #) are allowed", kind, id)
+			}
+		}
+		return nil
+	}
+
+	if !data.MatchPattern(def.Pattern, id) {
+		return fmt.Errorf("invalid %s '%s' for %s: expected %s", kind, id, def.Name, def.PatternDescription)
+	}
+	if def.ReservedPattern != "" && data.MatchPattern(def.ReservedPattern, id) {
+		return fmt.Errorf("%s '%s' is reserved for %s: %s", kind, id, def.Name, def.ReservedDescription)
+	}
+	return nil
+}
+
+// wrapEditedLines replaces edits on lines that would extend beyond column 72 with
+// a single edit rewriting the whole line, wrapped at column 72
+func wrapEditedLines(lines []string, edits []lsp.TextEdit) []lsp.TextEdit {
+	byLine := make(map[int][]lsp.TextEdit)
+	var lineOrder []int
+	for _, e := range edits {
+		if _, seen := byLine[e.Range.Start.Line]; !seen {
+			lineOrder = append(lineOrder, e.Range.Start.Line)
+		}
+		byLine[e.Range.Start.Line] = append(byLine[e.Range.Start.Line], e)
+	}
+	sort.Ints(lineOrder)
+
+	var result []lsp.TextEdit
+	for _, lineNum := range lineOrder {
+		lineEdits := byLine[lineNum]
+		if lineNum >= len(lines) {
+			result = append(result, lineEdits...)
+			continue
+		}
+
+		original := []rune(lines[lineNum])
+		updated := applyLineEdits(original, lineEdits)
+		if len([]rune(strings.TrimRight(updated, " \t\r"))) <= formatting.MaxColumn {
+			result = append(result, lineEdits...)
+			continue
+		}
+
+		indent := updated[:len(updated)-len(strings.TrimLeft(updated, " \t"))]
+		if indent == "" {
+			indent = continuationIndent
+		}
+		result = append(result, lsp.TextEdit{
+			Range: lsp.Range{
+				Start: lsp.Position{Line: lineNum, Character: 0},
+				End:   lsp.Position{Line: lineNum, Character: len(original)},
+			},
+			NewText: formatting.WrapLine(strings.TrimRight(updated, " \t\r"), indent),
+		})
+	}
+
+	return result
+}
+
+// applyLineEdits applies single-line edits to a line, right to left
+func applyLineEdits(line []rune, edits []lsp.TextEdit) string {
+	sorted := make([]lsp.TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Character > sorted[j].Range.Start.Character
+	})
+
+	result := line
+	for _, e := range sorted {
+		start, end := e.Range.Start.Character, e.Range.End.Character
+		if start > len(result) || end > len(result) || start > end {
+			continue
+		}
+		updated := make([]rune, 0, len(result)+len(e.NewText))
+		updated = append(updated, result[:start]...)
+		updated = append(updated, []rune(e.NewText)...)
+		updated = append(updated, result[end:]...)
+		result = updated
+	}
+	return string(result)
+}
+
+// symbolRange returns the LSP range covered by a symbol
+func symbolRange(s *Symbol) lsp.Range {
+	return lsp.Range{
+		Start: s.Position,
+		End: lsp.Position{
+			Line:      s.Position.Line,
+			Character: s.Position.Character + s.Length,
+		},
+	}
+}
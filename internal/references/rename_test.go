@@ -0,0 +1,225 @@
+package references
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+)
+
+var sysmodOperands = []data.Operand{
+	{Name: "DESCRIPTION|DESC", Parameter: "DESCRIPTION", Type: "string"},
+}
+
+var testStatements = map[string]data.MCSStatement{
+	"++USERMOD": {
+		Name: "++USERMOD", Parameter: "SYSMOD-ID", Length: 7, Operands: sysmodOperands,
+		Pattern: "[A-Z0-9$@#]{7}", PatternDescription: "7 uppercase alphanumeric or national characters",
+		ReservedPattern: "[A-KU-Z].*", ReservedDescription: "IDs starting with L-T are available for users",
+	},
+	"++PTF":      {Name: "++PTF", Parameter: "SYSMOD-ID", Length: 7, Operands: sysmodOperands},
+	"++FUNCTION": {Name: "++FUNCTION", Parameter: "SYSMOD-ID", Length: 7, Operands: sysmodOperands},
+	"++VER": {
+		Name:      "++VER",
+		Parameter: "SREL",
+		Operands: []data.Operand{
+			{Name: "FMID", Parameter: "SYSMOD_ID", Type: "string", Length: 7},
+			{Name: "PRE", Parameter: "SYSMOD_IDs", Type: "list", Length: 7},
+			{Name: "REQ", Parameter: "SYSMOD_IDs", Type: "list", Length: 7},
+			{Name: "SUP", Parameter: "SYSMOD_IDs", Type: "list", Length: 7},
+		},
+	},
+}
+
+func newTestParser() *parser.Parser {
+	return parser.NewParser(testStatements)
+}
+
+// testFile is a parsed workspace file
+type testFile struct {
+	URI  string
+	Text string
+	Doc  *parser.Document
+}
+
+func newTestFile(p *parser.Parser, uri, text string) testFile {
+	return testFile{URI: uri, Text: text, Doc: p.Parse(text)}
+}
+
+// testWorkspace looks up the occurrences of a name in its files, like the workspace index
+type testWorkspace []testFile
+
+func (ws testWorkspace) Occurrences(name string) []Occurrence {
+	var result []Occurrence
+	for _, f := range ws {
+		for _, s := range NewProvider().Symbols(f.Doc) {
+			if s.Name == name {
+				result = append(result, Occurrence{Symbol: s, URI: f.URI})
+			}
+		}
+	}
+	return result
+}
+
+func (ws testWorkspace) Statement(name string) *data.MCSStatement {
+	if def, ok := testStatements[name]; ok {
+		return &def
+	}
+	return nil
+}
+
+func (ws testWorkspace) Text(uri string) (string, error) {
+	for _, f := range ws {
+		if f.URI == uri {
+			return f.Text, nil
+		}
+	}
+	return "", fmt.Errorf("no file %s", uri)
+}
+
+func TestRenameAcrossFiles(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	defining := newTestFile(p, "file:///a.smpe", "++USERMOD(LJS2012).\n++VER(Z038) FMID(HBB7790).\n")
+	referencing := newTestFile(p, "file:///b.smpe", "++USERMOD(LJS2013).\n++VER(Z038) FMID(HBB7790)\n   PRE(LJS2011,LJS2012).\n")
+	unrelated := newTestFile(p, "file:///c.smpe", "++PTF(UA12345).\n")
+
+	edit, err := provider.Rename(defining.Doc, 0, 12, "ljs3000", testWorkspace{defining, referencing, unrelated})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	if len(edit.Changes) != 2 {
+		t.Fatalf("Expected edits in 2 files, got %d", len(edit.Changes))
+	}
+
+	defEdits := edit.Changes["file:///a.smpe"]
+	if len(defEdits) != 1 || defEdits[0].NewText != "LJS3000" || defEdits[0].Range.Start.Character != 10 {
+		t.Errorf("Unexpected definition edits: %+v", defEdits)
+	}
+
+	refEdits := edit.Changes["file:///b.smpe"]
+	if len(refEdits) != 1 {
+		t.Fatalf("Expected 1 reference edit, got %+v", refEdits)
+	}
+	if refEdits[0].Range.Start.Line != 2 || refEdits[0].Range.Start.Character != 15 {
+		t.Errorf("Reference edit at wrong position: %+v", refEdits[0].Range)
+	}
+}
+
+func TestRenameFromReference(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	file := newTestFile(p, "file:///a.smpe", "++FUNCTION(HBB7790).\n++VER(Z038) FMID(HBB7790).\n")
+
+	// Cursor on the FMID reference
+	edit, err := provider.Rename(file.Doc, 1, 19, "HBB7791", testWorkspace{file})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if len(edit.Changes["file:///a.smpe"]) != 2 {
+		t.Errorf("Expected definition and reference to be renamed, got %+v", edit.Changes)
+	}
+}
+
+func TestRenameRejectsInvalidIDs(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	file := newTestFile(p, "file:///a.smpe", "++USERMOD(LJS2012).\n++USERMOD(LJS2013).\n")
+	files := testWorkspace{file}
+
+	tests := []struct {
+		name    string
+		newName string
+		errPart string
+	}{
+		{"too short", "LJS20", "exactly 7"},
+		{"too long", "LJS20120", "exactly 7"},
+		{"invalid characters", "LJS-201", "expected 7 uppercase"},
+		{"reserved prefix", "UA12345", "reserved for ++USERMOD"},
+		{"already defined", "LJS2013", "already defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Rename(file.Doc, 0, 12, tt.newName, files)
+			if err == nil {
+				t.Fatalf("Expected rename to %q to be refused", tt.newName)
+			}
+			if !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("Expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestRenameAcceptsNationalCharacters(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	file := newTestFile(p, "file:///a.smpe", "++USERMOD(LJS2012).\n")
+	edit, err := provider.Rename(file.Doc, 0, 12, "LU$0001", testWorkspace{file})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if edits := edit.Changes["file:///a.smpe"]; len(edits) != 1 || edits[0].NewText != "LU$0001" {
+		t.Errorf("Unexpected edits: %+v", edit.Changes)
+	}
+}
+
+func TestRenameWrapsBeyondColumn72(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	// Short (invalid) ID near column 72 - renaming to a valid 7-char ID pushes the line past 72
+	line := "++VER(Z038)  FMID(HBB7790)  PRE(UA00001,UA00002,UA00003,UA00004,UA1)."
+	file := newTestFile(p, "file:///a.smpe", "++PTF(UA1).\n"+line+"\n")
+
+	edit, err := provider.Rename(file.Doc, 0, 7, "UA12345", testWorkspace{file})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	for _, e := range edit.Changes["file:///a.smpe"] {
+		if e.Range.Start.Line != 1 {
+			continue
+		}
+		if e.Range.Start.Character != 0 || !strings.Contains(e.NewText, "\n") {
+			t.Fatalf("Expected whole-line wrapped edit, got %+v", e)
+		}
+		for _, l := range strings.Split(e.NewText, "\n") {
+			if len(l) > 72 {
+				t.Errorf("Wrapped line exceeds column 72: %q", l)
+			}
+		}
+		if !strings.Contains(strings.ReplaceAll(e.NewText, "\n", " "), "UA12345") {
+			t.Errorf("Wrapped line lost the new ID: %q", e.NewText)
+		}
+		return
+	}
+	t.Fatal("No edit found for the referencing line")
+}
+
+func TestPrepareRename(t *testing.T) {
+	p := newTestParser()
+	provider := NewProvider()
+
+	doc := p.Parse("++USERMOD(LJS2012) DESC(TEST).\n")
+
+	result := provider.PrepareRename(doc, 0, 11)
+	if result == nil {
+		t.Fatal("Expected prepare rename result on SYSMOD ID")
+	}
+	if result.Placeholder != "LJS2012" || result.Range.Start.Character != 10 || result.Range.End.Character != 17 {
+		t.Errorf("Unexpected prepare rename result: %+v", result)
+	}
+
+	if provider.PrepareRename(doc, 0, 20) != nil {
+		t.Error("Expected no rename on DESC operand")
+	}
+}
@@ -0,0 +1,53 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+)
+
+// jclTokens creates tokens for the inline JCL of a ++JCLIN statement: operations are
+// keywords, keyword parameters functions and their values parameters, while names of
+// steps, DDs, load modules and members are variables
+func (p *Provider) jclTokens(jcl *parser.JCL) []Token {
+	if jcl == nil {
+		return nil
+	}
+	var tokens []Token
+	add := func(pos parser.Position, tokenType TokenType) {
+		if pos.Length > 0 {
+			tokens = append(tokens, Token{
+				Line:      pos.Line,
+				StartChar: pos.Character,
+				Length:    pos.Length,
+				Type:      tokenType,
+				Modifiers: TokenModifierNone,
+			})
+		}
+	}
+
+	for _, comment := range jcl.Comments {
+		add(comment, TokenTypeComment)
+	}
+	for _, s := range jcl.Statements {
+		add(s.NamePos, TokenTypeVariable)
+		add(s.OpPos, TokenTypeKeyword)
+		for _, param := range s.Parameters {
+			add(param.KeywordPos, TokenTypeFunction)
+			valueType := TokenTypeParameter
+			if strings.HasPrefix(param.Value, "'") {
+				valueType = TokenTypeString
+			}
+			for _, part := range param.ValueParts {
+				add(part, valueType)
+			}
+		}
+		for _, control := range s.Data {
+			add(control.OpPos, TokenTypeKeyword)
+			for _, name := range control.Names {
+				add(name.Position, TokenTypeVariable)
+			}
+		}
+	}
+	return tokens
+}
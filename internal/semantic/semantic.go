@@ -14,6 +14,7 @@ const (
 	TokenTypeComment                  // Comments
 	TokenTypeString                   // Quoted strings
 	TokenTypeNumber                   // Numbers
+	TokenTypeVariable                 // Names in inline JCL like step, DD and member names
 )
 
 // TokenModifier represents modifiers for semantic tokens
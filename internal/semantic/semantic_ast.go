@@ -20,6 +20,7 @@ func (p *Provider) BuildTokensFromAST(doc *parser.Document, text string) []int {
 	// Traverse AST and build tokens for statements
 	for _, stmt := range doc.Statements {
 		tokens = append(tokens, p.traverseNode(stmt)...)
+		tokens = append(tokens, p.jclTokens(stmt.JCL)...)
 	}
 
 	// Sort tokens by line, then by character (required by LSP semantic tokens spec)
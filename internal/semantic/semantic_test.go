@@ -286,3 +286,48 @@ func TestBuildTokensFromAST_TokensAreSorted(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildTokensFromAST_InlineJCL(t *testing.T) {
+	p, sp := newTestProvider(t)
+	input := "++JCLIN .\n" +
+		"//LKED    EXEC PGM=IEWL,PARM='LIST' Link-edit\n" +
+		"//SYSLIN  DD   *\n" +
+		"  NAME MYLMOD(R)\n"
+	result := sp.BuildTokensFromAST(p.Parse(input), input)
+
+	// Absolute line, character and type of each token
+	var got [][3]int
+	line, char := 0, 0
+	for i := 0; i+4 < len(result); i += 5 {
+		if result[i] > 0 {
+			line += result[i]
+			char = 0
+		}
+		char += result[i+1]
+		if line > 0 {
+			got = append(got, [3]int{line, char, result[i+3]})
+		}
+	}
+	want := [][3]int{
+		{1, 2, int(TokenTypeVariable)},   // LKED
+		{1, 10, int(TokenTypeKeyword)},   // EXEC
+		{1, 15, int(TokenTypeFunction)},  // PGM
+		{1, 19, int(TokenTypeParameter)}, // IEWL
+		{1, 24, int(TokenTypeFunction)},  // PARM
+		{1, 29, int(TokenTypeString)},    // 'LIST'
+		{1, 36, int(TokenTypeComment)},   // Link-edit
+		{2, 2, int(TokenTypeVariable)},   // SYSLIN
+		{2, 10, int(TokenTypeKeyword)},   // DD
+		{2, 15, int(TokenTypeParameter)}, // *
+		{3, 2, int(TokenTypeKeyword)},    // NAME
+		{3, 7, int(TokenTypeVariable)},   // MYLMOD
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d JCL tokens, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Token %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
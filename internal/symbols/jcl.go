@@ -0,0 +1,135 @@
+package symbols
+
+import (
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// jclEndLine returns the last line of the inline JCL of a statement, or -1 if it has none
+func jclEndLine(jcl *parser.JCL) int {
+	end := -1
+	if jcl != nil {
+		for _, s := range jcl.Statements {
+			end = max(end, s.EndLine)
+		}
+	}
+	return end
+}
+
+// jclRange returns the range of lines first to last
+func jclRange(first, last int, lines []string) lsp.Range {
+	endChar := 0
+	if last < len(lines) {
+		endChar = len(strings.TrimRight(lines[last], "\r"))
+	}
+	return lsp.Range{
+		Start: lsp.Position{Line: first, Character: 0},
+		End:   lsp.Position{Line: last, Character: endChar},
+	}
+}
+
+// positionRange converts a position in inline JCL to a range
+func positionRange(pos parser.Position) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: pos.Line, Character: pos.Character},
+		End:   lsp.Position{Line: pos.Line, Character: pos.Character + pos.Length},
+	}
+}
+
+// getJCLSymbols returns symbols for the inline JCL of a ++JCLIN statement: jobs and steps
+// with their DD statements as children, which in turn hold the members of their data
+// set and the load modules, members and aliases named in their in-stream data
+func (p *Provider) getJCLSymbols(jcl *parser.JCL, lines []string) []lsp.DocumentSymbol {
+	var symbols []lsp.DocumentSymbol
+	step := -1 // Index of the current step in symbols
+	for _, s := range jcl.Statements {
+		switch s.Operation {
+		case "JOB", "EXEC":
+			symbol := p.createJCLStatementSymbol(s, lines)
+			if s.Operation == "JOB" {
+				symbol.Kind = lsp.SymbolKindNamespace
+				step = -1
+			} else {
+				step = len(symbols)
+			}
+			symbols = append(symbols, symbol)
+		case "DD":
+			symbol := p.createJCLStatementSymbol(s, lines)
+			if step >= 0 {
+				symbols[step].Children = append(symbols[step].Children, symbol)
+				symbols[step].Range.End = symbol.Range.End
+			} else {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+	return symbols
+}
+
+// createJCLStatementSymbol creates the symbol of a JOB, EXEC or DD statement
+func (p *Provider) createJCLStatementSymbol(s *parser.JCLStatement, lines []string) lsp.DocumentSymbol {
+	var params []string
+	for _, param := range s.Parameters {
+		if param.Keyword != "" {
+			params = append(params, param.Keyword+"="+param.Value)
+		} else {
+			params = append(params, param.Value)
+		}
+	}
+
+	name, selection := s.Name, s.NamePos
+	if name == "" {
+		// Concatenated DD statements have no name
+		name, selection = s.Operation, s.OpPos
+		if dsn := s.DSN(); dsn != nil {
+			name = dsn.Value
+		}
+	}
+
+	symbol := lsp.DocumentSymbol{
+		Name:           name,
+		Detail:         strings.TrimSpace(s.Operation + " " + strings.Join(params, ",")),
+		Kind:           lsp.SymbolKindFunction,
+		Range:          jclRange(s.Line, s.EndLine, lines),
+		SelectionRange: positionRange(selection),
+	}
+	if s.Operation != "DD" {
+		return symbol
+	}
+
+	symbol.Kind = lsp.SymbolKindVariable
+	if dsn := s.DSN(); dsn != nil && dsn.Member != "" {
+		symbol.Children = append(symbol.Children, lsp.DocumentSymbol{
+			Name:           dsn.Member,
+			Detail:         "Member of " + dsn.DataSetName(),
+			Kind:           lsp.SymbolKindConstant,
+			Range:          positionRange(dsn.MemberPos),
+			SelectionRange: positionRange(dsn.MemberPos),
+		})
+	}
+	for _, control := range s.Data {
+		for _, n := range control.Names {
+			child := lsp.DocumentSymbol{
+				Name:           n.Value,
+				Kind:           lsp.SymbolKindConstant,
+				Range:          positionRange(n.Position),
+				SelectionRange: positionRange(n.Position),
+			}
+			switch n.Kind {
+			case parser.JCLNameLoadModule:
+				child.Kind, child.Detail = lsp.SymbolKindModule, "Load module"
+			case parser.JCLNameAlias:
+				child.Detail = "Alias"
+			default:
+				child.Detail = "Member"
+				if n.DD != "" {
+					child.Detail = "Member of DD " + n.DD
+				}
+			}
+			symbol.Children = append(symbol.Children, child)
+		}
+	}
+	return symbol
+}
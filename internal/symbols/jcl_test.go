@@ -0,0 +1,86 @@
+package symbols
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/smpe_ls/internal/data"
+	"github.com/cybersorcerer/smpe_ls/internal/parser"
+	"github.com/cybersorcerer/smpe_ls/pkg/lsp"
+)
+
+// contains checks if r lies within outer
+func contains(outer, r lsp.Range) bool {
+	before := func(a, b lsp.Position) bool {
+		return a.Line < b.Line || (a.Line == b.Line && a.Character <= b.Character)
+	}
+	return before(outer.Start, r.Start) && before(r.End, outer.End)
+}
+
+// checkNesting verifies that the range and selection range of every child lie within
+// the range of its parent, and collects the symbols as "parent/name" paths
+func checkNesting(t *testing.T, parent string, symbols []lsp.DocumentSymbol, outer *lsp.Range, paths map[string]lsp.DocumentSymbol) {
+	t.Helper()
+	for _, s := range symbols {
+		path := parent + "/" + s.Name
+		if outer != nil && (!contains(*outer, s.Range) || !contains(*outer, s.SelectionRange)) {
+			t.Errorf("%s: range %+v or selection range %+v outside of the parent range %+v", path, s.Range, s.SelectionRange, *outer)
+		}
+		if !contains(s.Range, s.SelectionRange) {
+			t.Errorf("%s: selection range %+v outside of range %+v", path, s.SelectionRange, s.Range)
+		}
+		paths[path] = s
+		checkNesting(t, path, s.Children, &s.Range, paths)
+	}
+}
+
+func TestJCLSymbols(t *testing.T) {
+	store, err := data.Load("../../data/smpe.json")
+	if err != nil {
+		t.Fatalf("Failed to load smpe.json: %v", err)
+	}
+	text := "++USERMOD(LJS0001) .\n" +
+		"++VER(Z038) FMID(HBB77C0) .\n" +
+		"++JCLIN .\n" +
+		"//JCLIN    JOB 'ACCT',MSGLEVEL=(1,1)\n" +
+		"//LINK     EXEC PGM=IEWL,\n" +
+		"//             PARM='LIST,XREF'\n" +
+		"//SYSLMOD  DD  DSN=SYS1.LINKLIB,DISP=SHR\n" +
+		"//AOBJ     DD  DSN=SYS1.AOBJ,DISP=SHR\n" +
+		"//         DD  DSN=SYS1.AOBJ2(MOD9),DISP=SHR\n" +
+		"//SYSLIN   DD  *\n" +
+		"  INCLUDE AOBJ(MOD1,MOD2)\n" +
+		"  ALIAS MYALIAS\n" +
+		"  NAME MYLMOD(R)\n" +
+		"/*\n"
+	doc := parser.NewParser(store.Statements).Parse(text)
+	symbols := NewProvider().GetDocumentSymbols(doc, strings.Split(text, "\n"))
+
+	paths := make(map[string]lsp.DocumentSymbol)
+	checkNesting(t, "", symbols, nil, paths)
+
+	for path, kind := range map[string]lsp.SymbolKind{
+		"/++JCLIN/JCLIN":                      lsp.SymbolKindNamespace,
+		"/++JCLIN/LINK":                       lsp.SymbolKindFunction,
+		"/++JCLIN/LINK/SYSLMOD":               lsp.SymbolKindVariable,
+		"/++JCLIN/LINK/AOBJ":                  lsp.SymbolKindVariable,
+		"/++JCLIN/LINK/SYS1.AOBJ2(MOD9)":      lsp.SymbolKindVariable,
+		"/++JCLIN/LINK/SYS1.AOBJ2(MOD9)/MOD9": lsp.SymbolKindConstant,
+		"/++JCLIN/LINK/SYSLIN/MOD1":           lsp.SymbolKindConstant,
+		"/++JCLIN/LINK/SYSLIN/MOD2":           lsp.SymbolKindConstant,
+		"/++JCLIN/LINK/SYSLIN/MYALIAS":        lsp.SymbolKindConstant,
+		"/++JCLIN/LINK/SYSLIN/MYLMOD":         lsp.SymbolKindModule,
+	} {
+		if s, ok := paths[path]; !ok || s.Kind != kind {
+			t.Errorf("Expected symbol %s of kind %d, got %+v", path, kind, s)
+		}
+	}
+	if detail := paths["/++JCLIN/LINK/SYSLIN/MOD1"].Detail; detail != "Member of DD AOBJ" {
+		t.Errorf("Unexpected detail %q for MOD1", detail)
+	}
+	if t.Failed() {
+		for path := range paths {
+			t.Log(path)
+		}
+	}
+}
@@ -57,6 +57,10 @@ func (p *Provider) createStatementSymbol(stmt *parser.Node, lines []string) *lsp
 
 	// Calculate range (from statement start to terminator or last operand)
 	endLine, endChar := p.getStatementEndPosition(stmt, lines)
+	if last := jclEndLine(stmt.JCL); last > endLine {
+		end := jclRange(last, last, lines).End
+		endLine, endChar = end.Line, end.Character
+	}
 
 	symbol := &lsp.DocumentSymbol{
 		Name:   name,
@@ -74,6 +78,9 @@ func (p *Provider) createStatementSymbol(stmt *parser.Node, lines []string) *lsp
 
 	// Add child symbols for key operands
 	symbol.Children = p.getOperandSymbols(stmt)
+	if stmt.JCL != nil {
+		symbol.Children = append(symbol.Children, p.getJCLSymbols(stmt.JCL, lines)...)
+	}
 
 	return symbol
 }
@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/cybersorcerer/smpe_ls/internal/logger"
+)
+
+// FileExtension is the extension of MCS files picked up from the workspace
+const FileExtension = ".smpe"
+
+// IsSMPEFile reports whether the path names an MCS file
+func IsSMPEFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), FileExtension)
+}
+
+// WalkFiles calls fn for every .smpe file below the workspace root URI.
+// Inaccessible directories are skipped. Returning filepath.SkipAll from fn stops the walk.
+func WalkFiles(rootURI string, fn func(path string, uri string) error) {
+	rootPath := URIToPath(rootURI)
+	if rootPath == "" {
+		return
+	}
+
+	err := filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip inaccessible directories
+		}
+		if d.IsDir() || !IsSMPEFile(d.Name()) {
+			return nil
+		}
+		return fn(path, PathToURI(path))
+	})
+	if err != nil && err != filepath.SkipAll {
+		logger.Debug("workspace: walk of %s stopped: %v", rootPath, err)
+	}
+}
+
+// URIToPath converts a file:// URI to an OS path
+func URIToPath(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	path := parsed.Path
+	// On Windows, the path starts with / before the drive letter (e.g. /C:/...)
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return path
+}
+
+// PathToURI converts an OS path to a file:// URI
+func PathToURI(path string) string {
+	// Normalize to forward slashes
+	path = filepath.ToSlash(path)
+	if runtime.GOOS == "windows" {
+		// Windows: file:///C:/path
+		return "file:///" + path
+	}
+	// Unix: file:///path (path already starts with /)
+	return "file://" + path
+}
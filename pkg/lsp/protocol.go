@@ -1,5 +1,10 @@
 package lsp
 
+import (
+	"encoding/json"
+	"unicode"
+)
+
 // LSP Protocol types and structures
 // Based on Language Server Protocol Specification
 
@@ -23,11 +28,25 @@ type Location struct {
 
 // Diagnostic represents a diagnostic (error, warning, etc.)
 type Diagnostic struct {
-	Range    Range  `json:"range"`
-	Severity int    `json:"severity"`
-	Code     string `json:"code,omitempty"`
-	Source   string `json:"source,omitempty"`
-	Message  string `json:"message"`
+	Range              Range                          `json:"range"`
+	Severity           int                            `json:"severity"`
+	Code               string                         `json:"code,omitempty"`
+	CodeDescription    *CodeDescription               `json:"codeDescription,omitempty"`
+	Source             string                         `json:"source,omitempty"`
+	Message            string                         `json:"message"`
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+	Data               json.RawMessage                `json:"data,omitempty"` // preserved by the client between diagnostics and code actions
+}
+
+// CodeDescription links to the documentation of a diagnostic code
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+// DiagnosticRelatedInformation points to another location related to a diagnostic
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
 }
 
 // DiagnosticSeverity levels
@@ -139,6 +158,9 @@ type ServerCapabilities struct {
 	CodeLensProvider                *CodeLensOptions       `json:"codeLensProvider,omitempty"`
 	FoldingRangeProvider            bool                   `json:"foldingRangeProvider,omitempty"`
 	WorkspaceSymbolProvider         bool                   `json:"workspaceSymbolProvider,omitempty"`
+	RenameProvider                  *RenameOptions         `json:"renameProvider,omitempty"`
+	CodeActionProvider              *CodeActionOptions     `json:"codeActionProvider,omitempty"`
+	ExecuteCommandProvider          *ExecuteCommandOptions `json:"executeCommandProvider,omitempty"`
 }
 
 // TextDocumentSyncKind values
@@ -155,8 +177,84 @@ type CompletionOptions struct {
 
 // DiagnosticOptions describes diagnostic options
 type DiagnosticOptions struct {
-	InterFileDependencies bool `json:"interFileDependencies"`
-	WorkspaceDiagnostics  bool `json:"workspaceDiagnostics"`
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
+}
+
+// Document diagnostic report kinds
+const (
+	DiagnosticReportFull      = "full"
+	DiagnosticReportUnchanged = "unchanged"
+)
+
+// DocumentDiagnosticParams represents textDocument/diagnostic request parameters
+type DocumentDiagnosticParams struct {
+	TextDocument     TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                 `json:"identifier,omitempty"`
+	PreviousResultID string                 `json:"previousResultId,omitempty"`
+}
+
+// DocumentDiagnosticReport is a full or unchanged diagnostic report for a document
+type DocumentDiagnosticReport struct {
+	Kind     string       `json:"kind"`
+	ResultID string       `json:"resultId,omitempty"`
+	Items    []Diagnostic `json:"items"`
+}
+
+// MarshalJSON omits items from unchanged reports and always includes them in full reports
+func (r DocumentDiagnosticReport) MarshalJSON() ([]byte, error) {
+	type report DocumentDiagnosticReport
+	if r.Kind == DiagnosticReportUnchanged {
+		return json.Marshal(struct {
+			Kind     string `json:"kind"`
+			ResultID string `json:"resultId"`
+		}{r.Kind, r.ResultID})
+	}
+	if r.Items == nil {
+		r.Items = []Diagnostic{}
+	}
+	return json.Marshal(report(r))
+}
+
+// PreviousResultID is a result ID the client got for a document in an earlier workspace/diagnostic
+type PreviousResultID struct {
+	URI   string `json:"uri"`
+	Value string `json:"value"`
+}
+
+// WorkspaceDiagnosticParams represents workspace/diagnostic request parameters
+type WorkspaceDiagnosticParams struct {
+	Identifier        string             `json:"identifier,omitempty"`
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// WorkspaceDiagnosticReport represents the workspace/diagnostic response
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+// WorkspaceDocumentDiagnosticReport is a document diagnostic report in a workspace/diagnostic response.
+// Version is the version of the open document, nil for files read from disk.
+type WorkspaceDocumentDiagnosticReport struct {
+	URI     string
+	Version *int
+	DocumentDiagnosticReport
+}
+
+// MarshalJSON adds uri and version to the embedded document report
+func (r WorkspaceDocumentDiagnosticReport) MarshalJSON() ([]byte, error) {
+	report, err := json.Marshal(r.DocumentDiagnosticReport)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(report, &fields); err != nil {
+		return nil, err
+	}
+	fields["uri"], _ = json.Marshal(r.URI)
+	fields["version"], _ = json.Marshal(r.Version)
+	return json.Marshal(fields)
 }
 
 // SemanticTokensOptions describes semantic tokens options
@@ -195,37 +293,132 @@ type SemanticTokens struct {
 type InitializationOptions struct {
 	Diagnostics *DiagnosticsOptions `json:"diagnostics,omitempty"`
 	Formatting  *FormattingOptions  `json:"formatting,omitempty"`
+	CodeActions *CodeActionsOptions `json:"codeActions,omitempty"`
+
+	// DataOverlays are data files merged into smpe.json in order, relative to the workspace root
+	DataOverlays []string `json:"dataOverlays,omitempty"`
+
+	// TargetRelease is the SMP/E release (e.g. V3R6) the MCS are written for, empty for any
+	TargetRelease string `json:"targetRelease,omitempty"`
+}
+
+// CodeActionsOptions configures how code actions are delivered to the client
+type CodeActionsOptions struct {
+	// SnippetCommand indicates the client implements the smpe.insertSnippet command
+	SnippetCommand bool `json:"snippetCommand"`
 }
 
-// DiagnosticsOptions configures which diagnostics are enabled
+// DiagnosticsOptions configures the diagnostic rules
 type DiagnosticsOptions struct {
-	UnknownStatement            bool `json:"unknownStatement"`
-	InvalidLanguageId           bool `json:"invalidLanguageId"`
-	UnbalancedParentheses       bool `json:"unbalancedParentheses"`
-	MissingTerminator           bool `json:"missingTerminator"`
-	MissingParameter            bool `json:"missingParameter"`
-	UnknownOperand              bool `json:"unknownOperand"`
-	DuplicateOperand            bool `json:"duplicateOperand"`
-	EmptyOperandParameter       bool `json:"emptyOperandParameter"`
-	MissingRequiredOperand      bool `json:"missingRequiredOperand"`
-	DependencyViolation         bool `json:"dependencyViolation"`
-	MutuallyExclusive           bool `json:"mutuallyExclusive"`
-	RequiredGroup               bool `json:"requiredGroup"`
-	MissingInlineData           bool `json:"missingInlineData"`
-	UnknownSubOperand           bool `json:"unknownSubOperand"`
-	SubOperandValidation        bool `json:"subOperandValidation"`
-	ContentBeyondColumn72       bool `json:"contentBeyondColumn72"`
-	StandaloneCommentBetweenMCS bool `json:"standaloneCommentBetweenMCS"`
+	// Rules overrides the severity of rules by code, e.g. {"unknown_operand": "error"}:
+	// "off", "hint", "info", "warning" or "error". Rules not listed keep their default.
+	Rules map[string]string `json:"rules,omitempty"`
+	// KnownSysmodsFile lists SYSMOD IDs that satisfy references without being defined
+	// in the workspace; relative paths are resolved against the workspace root
+	KnownSysmodsFile string `json:"knownSysmodsFile,omitempty"`
+	// Delay is the debounce delay in milliseconds before diagnostics are published after a change
+	Delay *int `json:"delay,omitempty"`
+}
+
+// UnmarshalJSON decodes the options and also accepts the former on/off settings, which are
+// named after the rule code in camel case (e.g. "unknownOperand": false turns unknown_operand off)
+func (o *DiagnosticsOptions) UnmarshalJSON(b []byte) error {
+	type options DiagnosticsOptions
+	if err := json.Unmarshal(b, (*options)(o)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		var enabled bool
+		if json.Unmarshal(raw, &enabled) != nil || enabled {
+			continue
+		}
+		code := snakeCase(name)
+		if _, ok := o.Rules[code]; !ok {
+			if o.Rules == nil {
+				o.Rules = make(map[string]string)
+			}
+			o.Rules[code] = "off"
+		}
+	}
+	return nil
+}
+
+// snakeCase converts a camel case setting name to a rule code, e.g. contentBeyondColumn72
+// to content_beyond_column_72 and standaloneCommentBetweenMCS to standalone_comment_between_mcs
+func snakeCase(name string) string {
+	var code []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 {
+			prev := runes[i-1]
+			upper := unicode.IsUpper(r) && !unicode.IsUpper(prev)
+			digit := unicode.IsDigit(r) && !unicode.IsDigit(prev)
+			if upper || digit {
+				code = append(code, '_')
+			}
+		}
+		code = append(code, unicode.ToLower(r))
+	}
+	return string(code)
 }
 
 // InitializeParams represents the initialize request parameters
 type InitializeParams struct {
 	ProcessID             int                    `json:"processId"`
 	RootURI               string                 `json:"rootUri,omitempty"`
-	Capabilities          struct{}               `json:"capabilities"`
+	Capabilities          ClientCapabilities     `json:"capabilities"`
 	InitializationOptions *InitializationOptions `json:"initializationOptions,omitempty"`
 }
 
+// File change types of workspace/didChangeWatchedFiles
+const (
+	FileChangeCreated = 1
+	FileChangeChanged = 2
+	FileChangeDeleted = 3
+)
+
+// FileEvent describes a change to a watched file
+type FileEvent struct {
+	URI  string `json:"uri"`
+	Type int    `json:"type"`
+}
+
+// DidChangeWatchedFilesParams represents workspace/didChangeWatchedFiles params
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
+// ClientCapabilities holds the client capabilities the server makes use of
+type ClientCapabilities struct {
+	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	Workspace    *WorkspaceClientCapabilities    `json:"workspace,omitempty"`
+}
+
+// TextDocumentClientCapabilities holds text document specific client capabilities
+type TextDocumentClientCapabilities struct {
+	Diagnostic *DiagnosticClientCapabilities `json:"diagnostic,omitempty"`
+}
+
+// DiagnosticClientCapabilities is present if the client supports pull diagnostics
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
+
+// WorkspaceClientCapabilities holds workspace specific client capabilities
+type WorkspaceClientCapabilities struct {
+	Diagnostics *DiagnosticWorkspaceClientCapabilities `json:"diagnostics,omitempty"`
+}
+
+// DiagnosticWorkspaceClientCapabilities describes workspace/diagnostic/refresh support
+type DiagnosticWorkspaceClientCapabilities struct {
+	RefreshSupport bool `json:"refreshSupport,omitempty"`
+}
+
 // InitializeResult represents the initialize response
 type InitializeResult struct {
 	Capabilities ServerCapabilities `json:"capabilities"`
@@ -252,6 +445,10 @@ type SettingsPayload struct {
 type SmpeSettings struct {
 	Diagnostics *DiagnosticsOptions `json:"diagnostics,omitempty"`
 	Formatting  *FormattingOptions  `json:"formatting,omitempty"`
+	// DataOverlays replaces the overlays passed in the initialization options, if set
+	DataOverlays *[]string `json:"dataOverlays,omitempty"`
+	// TargetRelease replaces the target release passed in the initialization options, if set
+	TargetRelease *string `json:"targetRelease,omitempty"`
 }
 
 // FormattingOptions configures document formatting behavior
@@ -397,3 +594,90 @@ type Command struct {
 type CodeLensOptions struct {
 	ResolveProvider bool `json:"resolveProvider,omitempty"`
 }
+
+// RenameOptions describes rename options
+type RenameOptions struct {
+	PrepareProvider bool `json:"prepareProvider,omitempty"`
+}
+
+// RenameParams represents textDocument/rename request params
+type RenameParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	NewName      string                 `json:"newName"`
+}
+
+// PrepareRenameParams represents textDocument/prepareRename request params
+type PrepareRenameParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// PrepareRenameResult describes the range to rename and the default new name
+type PrepareRenameResult struct {
+	Range       Range  `json:"range"`
+	Placeholder string `json:"placeholder"`
+}
+
+// WorkspaceEdit represents changes to many documents, keyed by URI
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// CodeActionKind values
+const (
+	CodeActionKindQuickFix = "quickfix"
+)
+
+// CodeActionOptions describes code action options
+type CodeActionOptions struct {
+	CodeActionKinds []string `json:"codeActionKinds,omitempty"`
+}
+
+// CodeActionParams represents textDocument/codeAction request params
+type CodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      CodeActionContext      `json:"context"`
+}
+
+// CodeActionContext carries the diagnostics the client wants code actions for
+type CodeActionContext struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	Only        []string     `json:"only,omitempty"`
+}
+
+// CodeAction represents a change that can be performed in code, e.g. a quick fix
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+	Command     *Command       `json:"command,omitempty"`
+}
+
+// ExecuteCommandOptions lists the commands the server executes
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+// ExecuteCommandParams represents workspace/executeCommand request params
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Message types of window/showMessage
+const (
+	MessageTypeError   = 1
+	MessageTypeWarning = 2
+	MessageTypeInfo    = 3
+	MessageTypeLog     = 4
+)
+
+// ShowMessageParams represents window/showMessage notification params
+type ShowMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
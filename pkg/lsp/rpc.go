@@ -41,6 +41,12 @@ const (
 	InternalError  = -32603
 )
 
+// LSP-specific error codes
+const (
+	RequestCancelled = -32800
+	RequestFailed    = -32803
+)
+
 // NewResponse creates a new successful response
 func NewResponse(id interface{}, result interface{}) Response {
 	return Response{
@@ -62,6 +68,17 @@ func NewErrorResponse(id interface{}, code int, message string) Response {
 	}
 }
 
+// NewRequest creates a new request
+func NewRequest(id interface{}, method string, params interface{}) *Request {
+	paramsJSON, _ := json.Marshal(params)
+	return &Request{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  paramsJSON,
+	}
+}
+
 // NewNotification creates a new notification
 func NewNotification(method string, params interface{}) *Notification {
 	paramsJSON, _ := json.Marshal(params)
@@ -2,11 +2,16 @@ package lsp
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/cybersorcerer/smpe_ls/internal/logger"
 )
@@ -16,39 +21,79 @@ type Server struct {
 	reader  *bufio.Reader
 	writer  io.Writer
 	handler Handler
+
+	writeMutex    sync.Mutex // serializes messages written by concurrent handlers
+	nextRequestID int64      // ID of the last request sent to the client
+
+	workers   chan struct{}  // semaphore limiting the requests running at once
+	taskGroup sync.WaitGroup // goroutines of dispatched requests and queued notifications
+
+	pendingMutex sync.Mutex
+	pending      map[string]context.CancelFunc // in-flight requests by ID
+	queues       map[string]chan struct{}      // per document, "" for all: closed when the last queued notification is done
 }
 
-// Handler interface for handling LSP requests
+// Handler interface for handling LSP requests.
+// Requests run concurrently; ctx is cancelled when the client sends $/cancelRequest.
+// Notifications for the same document are delivered in order; notifications without a
+// document (e.g. workspace/didChangeConfiguration) are ordered with all of them.
 type Handler interface {
-	Initialize(params InitializeParams) (*InitializeResult, error)
+	Initialize(ctx context.Context, params InitializeParams) (*InitializeResult, error)
 	TextDocumentDidOpen(params DidOpenTextDocumentParams) error
 	TextDocumentDidChange(params DidChangeTextDocumentParams) error
 	TextDocumentDidClose(params DidCloseTextDocumentParams) error
-	TextDocumentCompletion(params CompletionParams) ([]CompletionItem, error)
-	TextDocumentHover(params HoverParams) (*Hover, error)
-	TextDocumentSemanticTokensFull(params SemanticTokensParams) (*SemanticTokens, error)
-	TextDocumentFormatting(params DocumentFormattingParams) ([]TextEdit, error)
-	TextDocumentRangeFormatting(params DocumentRangeFormattingParams) ([]TextEdit, error)
-	TextDocumentDocumentSymbol(params DocumentSymbolParams) ([]DocumentSymbol, error)
-	TextDocumentDefinition(params DefinitionParams) (*Location, error)
-	TextDocumentReferences(params ReferenceParams) ([]Location, error)
-	TextDocumentCodeLens(params CodeLensParams) ([]CodeLens, error)
-	TextDocumentFoldingRange(params FoldingRangeParams) ([]FoldingRange, error)
-	WorkspaceSymbol(params WorkspaceSymbolParams) ([]SymbolInformation, error)
+	Initialized(params InitializedParams) error
+	TextDocumentCompletion(ctx context.Context, params CompletionParams) ([]CompletionItem, error)
+	TextDocumentHover(ctx context.Context, params HoverParams) (*Hover, error)
+	TextDocumentSemanticTokensFull(ctx context.Context, params SemanticTokensParams) (*SemanticTokens, error)
+	TextDocumentFormatting(ctx context.Context, params DocumentFormattingParams) ([]TextEdit, error)
+	TextDocumentRangeFormatting(ctx context.Context, params DocumentRangeFormattingParams) ([]TextEdit, error)
+	TextDocumentDocumentSymbol(ctx context.Context, params DocumentSymbolParams) ([]DocumentSymbol, error)
+	TextDocumentDefinition(ctx context.Context, params DefinitionParams) ([]Location, error)
+	TextDocumentReferences(ctx context.Context, params ReferenceParams) ([]Location, error)
+	TextDocumentCodeLens(ctx context.Context, params CodeLensParams) ([]CodeLens, error)
+	TextDocumentFoldingRange(ctx context.Context, params FoldingRangeParams) ([]FoldingRange, error)
+	TextDocumentPrepareRename(ctx context.Context, params PrepareRenameParams) (*PrepareRenameResult, error)
+	TextDocumentRename(ctx context.Context, params RenameParams) (*WorkspaceEdit, error)
+	TextDocumentCodeAction(ctx context.Context, params CodeActionParams) ([]CodeAction, error)
+	WorkspaceSymbol(ctx context.Context, params WorkspaceSymbolParams) ([]SymbolInformation, error)
+	TextDocumentDiagnostic(ctx context.Context, params DocumentDiagnosticParams) (*DocumentDiagnosticReport, error)
+	WorkspaceDiagnostic(ctx context.Context, params WorkspaceDiagnosticParams) (*WorkspaceDiagnosticReport, error)
 	WorkspaceDidChangeConfiguration(params DidChangeConfigurationParams) error
+	WorkspaceDidChangeWatchedFiles(params DidChangeWatchedFilesParams) error
+	WorkspaceExecuteCommand(ctx context.Context, params ExecuteCommandParams) (interface{}, error)
 }
 
 // NewServer creates a new LSP server
 func NewServer(reader io.Reader, writer io.Writer, handler Handler) *Server {
+	workers := runtime.NumCPU()
+	if workers < 2 {
+		workers = 2
+	}
 	return &Server{
 		reader:  bufio.NewReader(reader),
 		writer:  writer,
 		handler: handler,
+		workers: make(chan struct{}, workers),
+		pending: make(map[string]context.CancelFunc),
+		queues:  make(map[string]chan struct{}),
 	}
 }
 
-// Start starts the server
+// Start starts the server. It returns when the client disconnects or sends exit,
+// after all in-flight requests and notifications have completed.
 func (s *Server) Start() error {
+	defer func() {
+		// Cancel requests still running so their handlers return
+		s.pendingMutex.Lock()
+		for _, cancel := range s.pending {
+			cancel()
+		}
+		s.pendingMutex.Unlock()
+
+		s.taskGroup.Wait()
+	}()
+
 	for {
 		msg, err := s.readMessage()
 		if err != nil {
@@ -61,6 +106,9 @@ func (s *Server) Start() error {
 		}
 
 		if err := s.handleMessage(msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
 			logger.Error("Error handling message: %v", err)
 		}
 	}
@@ -108,7 +156,11 @@ func (s *Server) readMessage() ([]byte, error) {
 	return content, nil
 }
 
-// handleMessage handles a message from the client
+// handleMessage decodes a message from the client and dispatches it.
+// initialize and shutdown are handled synchronously, other requests run concurrently
+// after the pending notifications of their document. Notifications run in order per
+// document. The read loop never blocks on a handler, so $/cancelRequest always gets
+// through. Returns io.EOF on the exit notification.
 func (s *Server) handleMessage(msg []byte) error {
 	// Parse as generic message to check for ID
 	var genericMsg map[string]interface{}
@@ -118,11 +170,22 @@ func (s *Server) handleMessage(msg []byte) error {
 
 	// If message has an "id" field, it's a request
 	if _, hasID := genericMsg["id"]; hasID {
+		if _, hasMethod := genericMsg["method"]; !hasMethod {
+			// Response to a server-initiated request - nothing to do
+			return nil
+		}
+
 		var req Request
 		if err := json.Unmarshal(msg, &req); err != nil {
 			return fmt.Errorf("invalid request: %v", err)
 		}
-		return s.handleRequest(&req)
+
+		if req.Method == "initialize" || req.Method == "shutdown" {
+			return s.handleRequest(context.Background(), &req)
+		}
+
+		s.dispatchRequest(&req)
+		return nil
 	}
 
 	// Otherwise it's a notification
@@ -130,11 +193,162 @@ func (s *Server) handleMessage(msg []byte) error {
 	if err := json.Unmarshal(msg, &notif); err != nil {
 		return fmt.Errorf("invalid notification: %v", err)
 	}
-	return s.handleNotification(&notif)
+
+	switch notif.Method {
+	case "exit":
+		logger.Info("Received exit notification")
+		return io.EOF
+
+	case "$/cancelRequest":
+		var params CancelParams
+		if err := json.Unmarshal(notif.Params, &params); err != nil {
+			return err
+		}
+		s.cancelRequest(params.ID)
+		return nil
+	}
+
+	s.enqueue(documentURI(notif.Params), func() {
+		if err := s.handleNotification(&notif); err != nil {
+			logger.Error("Error handling notification %s: %v", notif.Method, err)
+		}
+	})
+	return nil
+}
+
+// dispatchRequest runs a request in its own goroutine with a cancellable context.
+// At most cap(s.workers) requests run at once; the others wait without blocking the
+// read loop.
+func (s *Server) dispatchRequest(req *Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	key := requestKey(req.ID)
+
+	s.pendingMutex.Lock()
+	s.pending[key] = cancel
+	// Wait for queued notifications of the same document so the request sees its latest state
+	wait := s.predecessors(documentURI(req.Params))
+	s.pendingMutex.Unlock()
+
+	s.taskGroup.Add(1)
+	go func() {
+		defer s.taskGroup.Done()
+		defer func() {
+			s.pendingMutex.Lock()
+			delete(s.pending, key)
+			s.pendingMutex.Unlock()
+			cancel()
+		}()
+
+		for _, w := range wait {
+			select {
+			case <-w:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() == nil {
+			select {
+			case s.workers <- struct{}{}:
+				defer func() { <-s.workers }()
+			case <-ctx.Done():
+			}
+		}
+
+		if ctx.Err() != nil {
+			logger.Debug("Request cancelled before start: method=%s, id=%v", req.Method, req.ID)
+			if err := s.sendErrorResponse(req.ID, RequestCancelled, "Request cancelled"); err != nil {
+				logger.Error("Error sending response: %v", err)
+			}
+			return
+		}
+
+		if err := s.handleRequest(ctx, req); err != nil {
+			logger.Error("Error handling request %s: %v", req.Method, err)
+		}
+	}()
+}
+
+// cancelRequest cancels the context of an in-flight request
+func (s *Server) cancelRequest(id interface{}) {
+	s.pendingMutex.Lock()
+	cancel, ok := s.pending[requestKey(id)]
+	s.pendingMutex.Unlock()
+
+	if ok {
+		logger.Debug("Cancelling request id=%v", id)
+		cancel()
+	}
+}
+
+// enqueue runs fn after all previously enqueued functions for the same document.
+// Functions without a document (key "") run after all previously enqueued functions,
+// and functions enqueued later wait for them.
+func (s *Server) enqueue(key string, fn func()) {
+	done := make(chan struct{})
+
+	s.pendingMutex.Lock()
+	prev := s.predecessors(key)
+	s.queues[key] = done
+	s.pendingMutex.Unlock()
+
+	s.taskGroup.Add(1)
+	go func() {
+		defer s.taskGroup.Done()
+		for _, p := range prev {
+			<-p
+		}
+		fn()
+		close(done)
+
+		s.pendingMutex.Lock()
+		if s.queues[key] == done {
+			delete(s.queues, key)
+		}
+		s.pendingMutex.Unlock()
+	}()
+}
+
+// predecessors returns the queues a message for the document key has to wait for: the
+// document's own queue and the queue of notifications without a document, or all queues
+// for key "". The caller must hold pendingMutex.
+func (s *Server) predecessors(key string) []chan struct{} {
+	var prev []chan struct{}
+	if key == "" {
+		for _, q := range s.queues {
+			prev = append(prev, q)
+		}
+		return prev
+	}
+	for _, k := range []string{key, ""} {
+		if q, ok := s.queues[k]; ok {
+			prev = append(prev, q)
+		}
+	}
+	return prev
+}
+
+// requestKey returns a map key for a JSON-RPC request ID (number or string)
+func requestKey(id interface{}) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// documentURI extracts textDocument.uri from request or notification params, if any
+func documentURI(params json.RawMessage) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return ""
+	}
+	return p.TextDocument.URI
 }
 
 // handleRequest handles a request from the client
-func (s *Server) handleRequest(req *Request) error {
+func (s *Server) handleRequest(ctx context.Context, req *Request) error {
 	logger.Info("Handling request: method=%s, id=%v", req.Method, req.ID)
 	logger.Debug("Handling request: %s", req.Method)
 
@@ -146,9 +360,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.Initialize(params)
+		result, err := s.handler.Initialize(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -159,9 +373,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentCompletion(params)
+		result, err := s.handler.TextDocumentCompletion(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -172,9 +386,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentHover(params)
+		result, err := s.handler.TextDocumentHover(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -185,9 +399,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentSemanticTokensFull(params)
+		result, err := s.handler.TextDocumentSemanticTokensFull(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -201,9 +415,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentFormatting(params)
+		result, err := s.handler.TextDocumentFormatting(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -214,9 +428,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentRangeFormatting(params)
+		result, err := s.handler.TextDocumentRangeFormatting(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -227,9 +441,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentDocumentSymbol(params)
+		result, err := s.handler.TextDocumentDocumentSymbol(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -240,9 +454,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentDefinition(params)
+		result, err := s.handler.TextDocumentDefinition(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -253,9 +467,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentReferences(params)
+		result, err := s.handler.TextDocumentReferences(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -266,9 +480,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentCodeLens(params)
+		result, err := s.handler.TextDocumentCodeLens(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -279,9 +493,9 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.TextDocumentFoldingRange(params)
+		result, err := s.handler.TextDocumentFoldingRange(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
 		}
 
 		return s.sendResponse(req.ID, result)
@@ -292,20 +506,95 @@ func (s *Server) handleRequest(req *Request) error {
 			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
 		}
 
-		result, err := s.handler.WorkspaceSymbol(params)
+		result, err := s.handler.WorkspaceSymbol(ctx, params)
 		if err != nil {
-			return s.sendErrorResponse(req.ID, InternalError, err.Error())
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "textDocument/prepareRename":
+		var params PrepareRenameParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
+		}
+
+		result, err := s.handler.TextDocumentPrepareRename(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "textDocument/rename":
+		var params RenameParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
+		}
+
+		result, err := s.handler.TextDocumentRename(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, RequestFailed, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "textDocument/codeAction":
+		var params CodeActionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
+		}
+
+		result, err := s.handler.TextDocumentCodeAction(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "textDocument/diagnostic":
+		var params DocumentDiagnosticParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, err.Error())
+		}
+
+		result, err := s.handler.TextDocumentDiagnostic(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "workspace/diagnostic":
+		var params WorkspaceDiagnosticParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, err.Error())
+		}
+
+		result, err := s.handler.WorkspaceDiagnostic(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, InternalError, err)
+		}
+
+		return s.sendResponse(req.ID, result)
+
+	case "workspace/executeCommand":
+		var params ExecuteCommandParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.sendErrorResponse(req.ID, InvalidParams, "Invalid params")
+		}
+
+		result, err := s.handler.WorkspaceExecuteCommand(ctx, params)
+		if err != nil {
+			return s.sendHandlerError(ctx, req.ID, RequestFailed, err)
 		}
 
 		return s.sendResponse(req.ID, result)
 
 	// Optional capabilities - respond with null to indicate not supported
 	case "textDocument/onTypeFormatting",
-		"textDocument/codeAction",
-		"textDocument/rename",
 		"textDocument/signatureHelp",
-		"textDocument/documentHighlight",
-		"workspace/executeCommand":
+		"textDocument/documentHighlight":
 		logger.Debug("Unsupported method: %s", req.Method)
 		return s.sendResponse(req.ID, nil)
 
@@ -341,13 +630,9 @@ func (s *Server) handleNotification(notif *Notification) error {
 		}
 		return s.handler.TextDocumentDidClose(params)
 
-	case "exit":
-		logger.Info("Received exit notification")
-		return io.EOF
-
 	case "initialized":
-		// Nothing to do
-		return nil
+		var params InitializedParams
+		return s.handler.Initialized(params)
 
 	case "workspace/didChangeConfiguration":
 		var params DidChangeConfigurationParams
@@ -356,6 +641,13 @@ func (s *Server) handleNotification(notif *Notification) error {
 		}
 		return s.handler.WorkspaceDidChangeConfiguration(params)
 
+	case "workspace/didChangeWatchedFiles":
+		var params DidChangeWatchedFilesParams
+		if err := json.Unmarshal(notif.Params, &params); err != nil {
+			return err
+		}
+		return s.handler.WorkspaceDidChangeWatchedFiles(params)
+
 	default:
 		logger.Debug("Unhandled notification: %s", notif.Method)
 		return nil
@@ -374,12 +666,27 @@ func (s *Server) sendErrorResponse(id interface{}, code int, message string) err
 	return s.writeMessage(resp)
 }
 
+// sendHandlerError sends an error returned by a handler, reporting
+// RequestCancelled if the request was cancelled
+func (s *Server) sendHandlerError(ctx context.Context, id interface{}, code int, err error) error {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return s.sendErrorResponse(id, RequestCancelled, "Request cancelled")
+	}
+	return s.sendErrorResponse(id, code, err.Error())
+}
+
 // SendNotification sends a notification to the client
 func (s *Server) SendNotification(method string, params interface{}) error {
 	notif := NewNotification(method, params)
 	return s.writeMessage(notif)
 }
 
+// SendRequest sends a request to the client without waiting for its response
+func (s *Server) SendRequest(method string, params interface{}) error {
+	req := NewRequest(atomic.AddInt64(&s.nextRequestID, 1), method, params)
+	return s.writeMessage(req)
+}
+
 // writeMessage writes a message to the client
 func (s *Server) writeMessage(msg interface{}) error {
 	data, err := EncodeMessage(msg)
@@ -388,6 +695,8 @@ func (s *Server) writeMessage(msg interface{}) error {
 	}
 
 	logger.Debug("Sending message: %s", string(data))
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
 	_, err = s.writer.Write(data)
 	return err
 }
@@ -403,6 +712,12 @@ type DidChangeTextDocumentParams struct {
 	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
 }
 
+type InitializedParams struct{}
+
+type CancelParams struct {
+	ID interface{} `json:"id"`
+}
+
 type DidCloseTextDocumentParams struct {
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
 }
@@ -0,0 +1,488 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHandler records notifications and lets tests block requests
+type fakeHandler struct {
+	mu       sync.Mutex
+	versions map[string][]int // didOpen/didChange versions in delivery order
+	order    []string         // all recorded notifications in delivery order
+
+	symbolOnce    sync.Once
+	symbolStarted chan struct{} // closed when the first workspace/symbol starts
+	releaseSymbol chan struct{} // closed to let workspace/symbol return
+}
+
+func newFakeHandler() *fakeHandler {
+	return &fakeHandler{
+		versions:      make(map[string][]int),
+		symbolStarted: make(chan struct{}),
+		releaseSymbol: make(chan struct{}),
+	}
+}
+
+func (f *fakeHandler) record(uri string, version int) {
+	// Give concurrent deliveries a chance to overtake each other
+	time.Sleep(time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions[uri] = append(f.versions[uri], version)
+	f.order = append(f.order, fmt.Sprintf("%s@%d", uri, version))
+}
+
+func (f *fakeHandler) Initialize(ctx context.Context, params InitializeParams) (*InitializeResult, error) {
+	return &InitializeResult{}, nil
+}
+
+func (f *fakeHandler) TextDocumentDidOpen(params DidOpenTextDocumentParams) error {
+	f.record(params.TextDocument.URI, params.TextDocument.Version)
+	return nil
+}
+
+func (f *fakeHandler) TextDocumentDidChange(params DidChangeTextDocumentParams) error {
+	f.record(params.TextDocument.URI, params.TextDocument.Version)
+	return nil
+}
+
+func (f *fakeHandler) TextDocumentDidClose(params DidCloseTextDocumentParams) error {
+	return nil
+}
+
+// TextDocumentCompletion returns the last version seen for the document
+func (f *fakeHandler) TextDocumentCompletion(ctx context.Context, params CompletionParams) ([]CompletionItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	versions := f.versions[params.TextDocument.URI]
+	if len(versions) == 0 {
+		return []CompletionItem{}, nil
+	}
+	return []CompletionItem{{Label: strconv.Itoa(versions[len(versions)-1])}}, nil
+}
+
+func (f *fakeHandler) TextDocumentHover(ctx context.Context, params HoverParams) (*Hover, error) {
+	return &Hover{Contents: MarkupContent{Kind: "plaintext", Value: "hover"}}, nil
+}
+
+func (f *fakeHandler) TextDocumentSemanticTokensFull(ctx context.Context, params SemanticTokensParams) (*SemanticTokens, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentFormatting(ctx context.Context, params DocumentFormattingParams) ([]TextEdit, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentRangeFormatting(ctx context.Context, params DocumentRangeFormattingParams) ([]TextEdit, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentDocumentSymbol(ctx context.Context, params DocumentSymbolParams) ([]DocumentSymbol, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentDefinition(ctx context.Context, params DefinitionParams) ([]Location, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentReferences(ctx context.Context, params ReferenceParams) ([]Location, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentCodeLens(ctx context.Context, params CodeLensParams) ([]CodeLens, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentFoldingRange(ctx context.Context, params FoldingRangeParams) ([]FoldingRange, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentPrepareRename(ctx context.Context, params PrepareRenameParams) (*PrepareRenameResult, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentRename(ctx context.Context, params RenameParams) (*WorkspaceEdit, error) {
+	return nil, nil
+}
+
+func (f *fakeHandler) TextDocumentCodeAction(ctx context.Context, params CodeActionParams) ([]CodeAction, error) {
+	return nil, nil
+}
+
+// WorkspaceSymbol blocks until released or cancelled
+func (f *fakeHandler) WorkspaceSymbol(ctx context.Context, params WorkspaceSymbolParams) ([]SymbolInformation, error) {
+	f.symbolOnce.Do(func() { close(f.symbolStarted) })
+	select {
+	case <-f.releaseSymbol:
+		return []SymbolInformation{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeHandler) TextDocumentDiagnostic(ctx context.Context, params DocumentDiagnosticParams) (*DocumentDiagnosticReport, error) {
+	return &DocumentDiagnosticReport{Kind: DiagnosticReportFull}, nil
+}
+
+func (f *fakeHandler) WorkspaceDiagnostic(ctx context.Context, params WorkspaceDiagnosticParams) (*WorkspaceDiagnosticReport, error) {
+	return &WorkspaceDiagnosticReport{}, nil
+}
+
+func (f *fakeHandler) Initialized(params InitializedParams) error {
+	return nil
+}
+
+func (f *fakeHandler) WorkspaceDidChangeConfiguration(params DidChangeConfigurationParams) error {
+	time.Sleep(time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.order = append(f.order, "configuration")
+	return nil
+}
+
+func (f *fakeHandler) WorkspaceDidChangeWatchedFiles(params DidChangeWatchedFilesParams) error {
+	return nil
+}
+
+func (f *fakeHandler) WorkspaceExecuteCommand(ctx context.Context, params ExecuteCommandParams) (interface{}, error) {
+	return nil, nil
+}
+
+// testClient drives a server over pipes
+type testClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	done   chan error
+	writeM sync.Mutex
+}
+
+func startServer(t *testing.T, h Handler) *testClient {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	server := NewServer(serverIn, serverOut, h)
+	go func() {
+		c.done <- server.Start()
+		serverOut.Close()
+	}()
+	t.Cleanup(func() {
+		clientOut.Close()
+		clientIn.Close()
+	})
+	return c
+}
+
+// send writes a request (id != nil) or notification to the server
+func (c *testClient) send(id interface{}, method string, params interface{}) {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = id
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatalf("marshal: %v", err)
+	}
+	c.writeM.Lock()
+	defer c.writeM.Unlock()
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+// testResponse is a decoded response from the server
+type testResponse struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// receive reads the next message from the server
+func (c *testClient) receive() testResponse {
+	c.t.Helper()
+	length := -1
+	for {
+		line, err := c.out.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("read header: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			length, _ = strconv.Atoi(value)
+		}
+	}
+	if length < 0 {
+		c.t.Fatal("missing Content-Length")
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(c.out, content); err != nil {
+		c.t.Fatalf("read content: %v", err)
+	}
+	var resp testResponse
+	if err := json.Unmarshal(content, &resp); err != nil {
+		c.t.Fatalf("invalid response %q: %v", content, err)
+	}
+	return resp
+}
+
+// exit sends the exit notification and waits for Start to return
+func (c *testClient) exit() {
+	c.t.Helper()
+	c.send(nil, "exit", nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Errorf("Start returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server did not stop after exit")
+	}
+}
+
+func docParams(uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": 0, "character": 0},
+	}
+}
+
+func TestSlowRequestDoesNotBlockOthers(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	c.send(1, "workspace/symbol", map[string]interface{}{"query": ""})
+	<-h.symbolStarted
+	c.send(2, "textDocument/hover", docParams("file:///a.smpe"))
+
+	if resp := c.receive(); resp.ID != float64(2) || resp.Error != nil {
+		t.Fatalf("Expected hover response first, got %+v", resp)
+	}
+
+	close(h.releaseSymbol)
+	if resp := c.receive(); resp.ID != float64(1) || resp.Error != nil {
+		t.Fatalf("Expected workspace/symbol response, got %+v", resp)
+	}
+	c.exit()
+}
+
+func TestCancelRequest(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	c.send("sym-1", "workspace/symbol", map[string]interface{}{"query": ""})
+	<-h.symbolStarted
+	c.send(nil, "$/cancelRequest", map[string]interface{}{"id": "sym-1"})
+
+	resp := c.receive()
+	if resp.ID != "sym-1" {
+		t.Fatalf("Unexpected response %+v", resp)
+	}
+	if resp.Error == nil || resp.Error.Code != RequestCancelled {
+		t.Fatalf("Expected RequestCancelled error, got %+v", resp.Error)
+	}
+
+	// Cancelling an unknown or finished request is ignored
+	c.send(nil, "$/cancelRequest", map[string]interface{}{"id": 99})
+	c.exit()
+}
+
+func TestCancelRequestWhileOthersWait(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	// Far more blocked requests than can run at once
+	const requests = 300
+	for id := 1; id <= requests; id++ {
+		c.send(id, "workspace/symbol", map[string]interface{}{"query": ""})
+	}
+	<-h.symbolStarted
+	c.send(nil, "$/cancelRequest", map[string]interface{}{"id": requests})
+
+	resp := c.receive()
+	if resp.ID != float64(requests) || resp.Error == nil || resp.Error.Code != RequestCancelled {
+		t.Fatalf("Expected RequestCancelled for the last request, got %+v", resp)
+	}
+
+	close(h.releaseSymbol)
+	for i := 1; i < requests; i++ {
+		if resp := c.receive(); resp.Error != nil {
+			t.Fatalf("Unexpected error %+v", resp)
+		}
+	}
+	c.exit()
+}
+
+func TestConfigurationOrderedWithDocuments(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	uris := []string{"file:///a.smpe", "file:///b.smpe"}
+	for _, uri := range uris {
+		c.send(nil, "textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "smpe", "version": 0, "text": ""},
+		})
+	}
+	c.send(nil, "workspace/didChangeConfiguration", map[string]interface{}{"settings": map[string]interface{}{}})
+	for _, uri := range uris {
+		c.send(nil, "textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": 1},
+			"contentChanges": []interface{}{map[string]interface{}{"text": ""}},
+		})
+	}
+	c.exit()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	position := make(map[string]int)
+	for i, n := range h.order {
+		position[n] = i
+	}
+	config, ok := position["configuration"]
+	if !ok || len(h.order) != 5 {
+		t.Fatalf("Unexpected notifications %v", h.order)
+	}
+	for _, uri := range uris {
+		if position[uri+"@0"] > config || position[uri+"@1"] < config {
+			t.Errorf("Configuration change not ordered with %s: %v", uri, h.order)
+		}
+	}
+}
+
+func TestNotificationsOrderedPerDocument(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	uris := []string{"file:///a.smpe", "file:///b.smpe", "file:///c.smpe"}
+	const changes = 20
+	for _, uri := range uris {
+		c.send(nil, "textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "smpe", "version": 0, "text": ""},
+		})
+	}
+	for v := 1; v <= changes; v++ {
+		for _, uri := range uris {
+			c.send(nil, "textDocument/didChange", map[string]interface{}{
+				"textDocument":   map[string]interface{}{"uri": uri, "version": v},
+				"contentChanges": []interface{}{map[string]interface{}{"text": ""}},
+			})
+		}
+	}
+
+	// A request for a document sees all notifications sent before it
+	for i, uri := range uris {
+		c.send(i+1, "textDocument/completion", docParams(uri))
+	}
+	for range uris {
+		resp := c.receive()
+		var items []CompletionItem
+		if err := json.Unmarshal(resp.Result, &items); err != nil || len(items) != 1 {
+			t.Fatalf("Unexpected completion result %s (%v)", resp.Result, err)
+		}
+		if items[0].Label != strconv.Itoa(changes) {
+			t.Errorf("Request %v saw version %s, want %d", resp.ID, items[0].Label, changes)
+		}
+	}
+	c.exit()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, uri := range uris {
+		versions := h.versions[uri]
+		if len(versions) != changes+1 {
+			t.Fatalf("%s: got %d notifications, want %d", uri, len(versions), changes+1)
+		}
+		for i, v := range versions {
+			if v != i {
+				t.Fatalf("%s: notifications out of order: %v", uri, versions)
+			}
+		}
+	}
+}
+
+func TestConcurrentResponsesAreFramed(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	const requests = 50
+	var wg sync.WaitGroup
+	for i := 1; i <= requests; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			c.send(id, "textDocument/hover", docParams(fmt.Sprintf("file:///%d.smpe", id%5)))
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[float64]bool)
+	for i := 0; i < requests; i++ {
+		resp := c.receive()
+		id, ok := resp.ID.(float64)
+		if !ok || resp.Error != nil || seen[id] {
+			t.Fatalf("Unexpected response %+v", resp)
+		}
+		seen[id] = true
+	}
+	c.exit()
+}
+
+func TestShutdownAndExit(t *testing.T) {
+	h := newFakeHandler()
+	c := startServer(t, h)
+
+	c.send(1, "initialize", map[string]interface{}{})
+	if resp := c.receive(); resp.ID != float64(1) || resp.Error != nil {
+		t.Fatalf("Unexpected initialize response %+v", resp)
+	}
+	c.send(2, "shutdown", nil)
+	if resp := c.receive(); resp.ID != float64(2) || resp.Error != nil {
+		t.Fatalf("Unexpected shutdown response %+v", resp)
+	}
+	c.send(3, "unknown/method", nil)
+	if resp := c.receive(); resp.Error == nil || resp.Error.Code != MethodNotFound {
+		t.Fatalf("Expected MethodNotFound, got %+v", resp)
+	}
+	c.exit()
+}
+
+func TestDiagnosticsOptionsLegacySettings(t *testing.T) {
+	var opts DiagnosticsOptions
+	raw := `{"rules": {"unknown_operand": "error"}, "unknownOperand": false, "duplicateOperand": false,
+		"contentBeyondColumn72": false, "standaloneCommentBetweenMCS": false, "missingTerminator": true, "delay": 100}`
+	if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"unknown_operand":                "error", // explicit rules take precedence
+		"duplicate_operand":              "off",
+		"content_beyond_column_72":       "off",
+		"standalone_comment_between_mcs": "off",
+	}
+	if len(opts.Rules) != len(want) {
+		t.Errorf("rules = %v, want %v", opts.Rules, want)
+	}
+	for code, severity := range want {
+		if opts.Rules[code] != severity {
+			t.Errorf("rules[%s] = %q, want %q", code, opts.Rules[code], severity)
+		}
+	}
+	if opts.Delay == nil || *opts.Delay != 100 {
+		t.Errorf("delay = %v, want 100", opts.Delay)
+	}
+}